@@ -0,0 +1,95 @@
+/**
+ *@Title 控制台颜色
+ *@Desc 颜色输出模式、终端检测及配色主题
+ */
+
+package console
+
+import (
+	"io"
+	"os"
+
+	"github.com/bearki/belog/v3/encoder"
+)
+
+// ColorMode 颜色输出模式
+type ColorMode uint8
+
+// 颜色输出模式定义
+const (
+	// 自动检测（默认）
+	//
+	// 设置了NO_COLOR环境变量时不输出颜色，
+	// 设置了FORCE_COLOR环境变量（值不为0或false）时始终输出颜色，
+	// 否则仅在输出目标为终端时输出颜色
+	ColorAuto ColorMode = 0
+	// 始终输出颜色
+	ColorAlways ColorMode = 1
+	// 从不输出颜色
+	ColorNever ColorMode = 2
+)
+
+// Style 控制台样式（ANSI SGR转义序列，为空时不设置样式）
+type Style = encoder.Style
+
+// 常用控制台样式
+const (
+	StyleNone    Style = ""
+	StyleBold    Style = "\x1b[1m"
+	StyleFaint   Style = "\x1b[2m"
+	StyleRed     Style = "\x1b[31m"
+	StyleGreen   Style = "\x1b[32m"
+	StyleYellow  Style = "\x1b[33m"
+	StyleBlue    Style = "\x1b[34m"
+	StyleMagenta Style = "\x1b[35m"
+	StyleCyan    Style = "\x1b[36m"
+	StyleGray    Style = "\x1b[90m"
+)
+
+// Theme 控制台配色主题
+type Theme = encoder.Theme
+
+// DefaultTheme 默认配色主题
+var DefaultTheme = Theme{
+	Time:  StyleGray,
+	Trace: StyleGray,
+	Debug: StyleBlue,
+	Info:  StyleGreen,
+	Warn:  StyleYellow,
+	Error: StyleRed,
+	Fatal: StyleMagenta,
+	Stack: StyleGray,
+	Key:   StyleCyan,
+}
+
+// 判断输出目标是否为终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// 判断输出目标是否需要输出颜色
+//
+//	@param	mode	颜色输出模式
+//	@param	w		输出目标
+//	@return	是否输出颜色
+func colorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	// 遵循https://no-color.org约定
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	if v := os.Getenv("FORCE_COLOR"); len(v) > 0 && v != "0" && v != "false" {
+		return true
+	}
+	return isTerminal(w)
+}
@@ -9,63 +9,115 @@ package console
 
 import (
 	"bufio"
-	"bytes"
+	"io"
 	"os"
+	"reflect"
+	"sync"
 	"time"
 
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
 	"github.com/bearki/belog/v3/logger"
 )
 
-// 控制台字体颜色字节表
-var (
-	// 灰色
-	colorGrayStartBytes = [...]byte{27, 91, 57, 48, 109}
-	// 蓝色
-	colorBlueStartBytes = [...]byte{27, 91, 51, 52, 109}
-	// 绿色
-	colorGreenStartBytes = [...]byte{27, 91, 51, 50, 109}
-	// 黄色
-	colorYellowStartBytes = [...]byte{27, 91, 51, 51, 109}
-	// 红色
-	colorRedStartBytes = [...]byte{27, 91, 51, 49, 109}
-	// 洋红色
-	colorMagentaStartBytes = [...]byte{27, 91, 51, 53, 109}
-	// 重置
-	colorResetBytes = [...]byte{27, 91, 48, 109}
+// StreamRoute 日志输出流路由方式
+type StreamRoute uint8
+
+// 日志输出流路由方式定义
+const (
+	RouteSplit  StreamRoute = 0 // 按级别分流，不低于StderrLevel的日志输出到标准错误，其余输出到标准输出（默认）
+	RouteStdout StreamRoute = 1 // 全部输出到标准输出
+	RouteStderr StreamRoute = 2 // 全部输出到标准错误
 )
 
-// 获取日志级别对应的控制台颜色字节表
-func getLevelConsoleColorBytes(level logger.Level) []byte {
-	switch level {
-	case logger.Trace: // 通知级别(灰色)
-		return colorGrayStartBytes[:]
-	case logger.Debug: // 调试级别(蓝色)
-		return colorBlueStartBytes[:]
-	case logger.Info: // 普通级别(绿色)
-		return colorGreenStartBytes[:]
-	case logger.Warn: // 警告级别(黄色)
-		return colorYellowStartBytes[:]
-	case logger.Error: // 错误级别(红色)
-		return colorRedStartBytes[:]
-	case logger.Fatal: // 紧急级别(洋红色)
-		return colorMagentaStartBytes[:]
-	default:
-		return nil
+// 控制台日志适配器初始化参数
+type Option struct {
+	DisabledBuffer bool         // 禁用缓冲区输出
+	DisabledColor  bool         // 禁用颜色输出（优先于Color）
+	Color          ColorMode    // 颜色输出模式（默认：ColorAuto）
+	Theme          *Theme       // 配色主题（默认：DefaultTheme）
+	Route          StreamRoute  // 输出流路由方式（默认：RouteSplit）
+	StderrLevel    logger.Level // 按级别分流时输出到标准错误的最低级别（默认：logger.Error）
+	Stdout         io.Writer    // 标准输出目标（默认：os.Stdout）
+	Stderr         io.Writer    // 标准错误目标（默认：os.Stderr，与Stdout为同一目标时共用写入锁及缓冲区）
+
+	// 控制台编码器
+	//
+	// 各输出流使用编码器按是否输出颜色生成的副本编码日志（参见encoder.WithTheme），
+	// 为nil时使用日志记录器的编码器，仅在该编码器支持配色主题时输出颜色，
+	// 颜色由日志级别、时间、调用栈、描述及字段等元数据直接决定
+	Encoder logger.Encoder
+
+	// 缓冲区自动刷新间隔
+	//
+	// 仅在启用缓冲区时生效，为0时只在调用Flush时刷新，
+	// 定时刷新协程在调用Close后停止
+	//
+	// Unit: 毫秒, Default: 0, Max: 3600000
+	FlushInterval uint
+}
+
+// 日志输出流
+type stream struct {
+	mutex  sync.Mutex    // 写入锁
+	writer io.Writer     // 输出目标
+	buffer *bufio.Writer // 写入缓冲器（禁用缓冲区时为nil）
+	color  bool          // 是否输出颜色
+}
+
+// 写入日志
+func (s *stream) write(content []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.buffer != nil {
+		_, _ = s.buffer.Write(content)
+	} else {
+		_, _ = s.writer.Write(content)
 	}
 }
 
-// 控制台日志适配器初始化参数
-type Option struct {
-	DisabledBuffer bool // 禁用缓冲区输出
-	DisabledColor  bool // 禁用颜色输出
+// 刷新缓冲区
+func (s *stream) flush() {
+	if s.buffer == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.buffer.Buffered() > 0 {
+		_ = s.buffer.Flush()
+	}
 }
 
 // Adapter 控制台日志适配器
 type Adapter struct {
-	disabledBuffer    bool          // 禁用缓冲区输出
-	disabledColor     bool          // 禁用颜色输出
-	stdoutWriteBuffer *bufio.Writer // 标准输出写入缓冲器
-	stderrWriteBuffer *bufio.Writer // 标准错误写入缓冲器
+	route       StreamRoute    // 输出流路由方式
+	stderrLevel logger.Level   // 按级别分流时输出到标准错误的最低级别
+	stdout      *stream        // 标准输出流
+	stderr      *stream        // 标准错误流（与标准输出为同一目标时共用同一输出流）
+	theme       *Theme         // 配色主题
+	encoder     logger.Encoder // 按输出流选择编码器的编码器（使用日志记录器的编码器时为nil）
+	closeOnce   sync.Once      // 关闭控制
+	closeSignal chan struct{}  // 关闭信号（停止定时刷新协程）
+}
+
+// 判断两个输出目标是否为同一目标
+func sameWriter(a, b io.Writer) bool {
+	if a == nil || b == nil || !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// 创建日志输出流
+func newStream(opt Option, writer io.Writer) *stream {
+	s := &stream{
+		writer: writer,
+		color:  !opt.DisabledColor && colorEnabled(opt.Color, writer),
+	}
+	if !opt.DisabledBuffer {
+		s.buffer = bufio.NewWriter(writer)
+	}
+	return s
 }
 
 // New 创建控制台日志适配器
@@ -73,13 +125,60 @@ type Adapter struct {
 //	@param	opt	适配器参数
 //	@return	适配器实例
 func New(opt Option) logger.Adapter {
+	// 判断参数有效性
+	if opt.Route > RouteStderr {
+		opt.Route = RouteSplit
+	}
+	if opt.StderrLevel < logger.Trace || opt.StderrLevel > logger.Fatal {
+		opt.StderrLevel = logger.Error
+	}
+	if opt.FlushInterval > 3600000 {
+		opt.FlushInterval = 0
+	}
+	if opt.Theme == nil {
+		opt.Theme = &DefaultTheme
+	}
+
+	if opt.Stdout == nil {
+		opt.Stdout = os.Stdout
+	}
+	if opt.Stderr == nil {
+		opt.Stderr = os.Stderr
+	}
+
 	adapter := &Adapter{
-		disabledBuffer: opt.DisabledBuffer,
-		disabledColor:  opt.DisabledColor,
+		route:       opt.Route,
+		stderrLevel: opt.StderrLevel,
+		theme:       opt.Theme,
+		stdout:      newStream(opt, opt.Stdout),
+		closeSignal: make(chan struct{}),
 	}
-	if !opt.DisabledBuffer {
-		adapter.stdoutWriteBuffer = bufio.NewWriter(os.Stdout)
-		adapter.stderrWriteBuffer = bufio.NewWriter(os.Stderr)
+	// 同一目标共用写入锁及缓冲区，避免并发写入及输出交错
+	if sameWriter(opt.Stdout, opt.Stderr) {
+		adapter.stderr = adapter.stdout
+	} else {
+		adapter.stderr = newStream(opt, opt.Stderr)
+	}
+
+	// 配置了编码器时由适配器编码日志，否则在设置到日志记录器时基于记录器的编码器生成
+	if opt.Encoder != nil {
+		adapter.encoder = adapter.streamEncoder(opt.Encoder)
+	}
+
+	// 定时刷新缓冲区
+	if !opt.DisabledBuffer && opt.FlushInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(time.Duration(opt.FlushInterval) * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					adapter.Flush()
+				case <-adapter.closeSignal:
+					return
+				}
+			}
+		}()
 	}
 	return adapter
 }
@@ -91,51 +190,34 @@ func (e *Adapter) Name() string {
 	return "belog-console-adapter"
 }
 
+// Encoder 获取适配器自带的编码器
+//
+//	配置了Encoder时返回按输出流选择编码器的编码器，否则返回nil
+func (e *Adapter) Encoder() logger.Encoder {
+	return e.encoder
+}
+
+// WrapEncoder 基于日志记录器的编码器生成适配器使用的编码器
+//
+//	未配置Encoder且需要输出颜色时返回按输出流选择编码器的编码器，
+//	记录器的编码器不支持配色主题时原样输出，不需要输出颜色时返回nil
+//
+//	@param	enc	日志记录器的编码器
+//	@return	适配器使用的编码器
+func (e *Adapter) WrapEncoder(enc logger.Encoder) logger.Encoder {
+	if !e.stdout.color && !e.stderr.color {
+		return nil
+	}
+	return e.streamEncoder(enc)
+}
+
 // Print 普通日志打印方法
 //
 //	@param	logTime	日记记录时间
 //	@param	level	日志级别
 //	@param	content	日志内容
 func (e *Adapter) Print(_ time.Time, level logger.Level, content []byte) {
-	// 是否禁用颜色
-	if !e.disabledColor {
-		oldBytes := []byte{'[', level.Byte(), ']'}
-		if !bytes.Contains(content, oldBytes) {
-			oldBytes = make([]byte, 0, len(level.String())+2)
-			oldBytes = append(oldBytes, '[')
-			oldBytes = append(oldBytes, level.String()...)
-			oldBytes = append(oldBytes, ']')
-		}
-		newBytes := getLevelConsoleColorBytes(level)
-		newBytes = append(newBytes, oldBytes...)
-		newBytes = append(newBytes, colorResetBytes[:]...)
-		// 替换颜色
-		content = bytes.Replace(
-			content,
-			oldBytes,
-			newBytes,
-			1,
-		)
-	}
-
-	// 是否禁用缓冲区 或 缓冲器输出器为空
-	if e.disabledBuffer || e.stdoutWriteBuffer == nil {
-		// 大于警告级别的往标准错误写
-		if level > logger.Warn {
-			_, _ = os.Stderr.Write(content)
-		} else {
-			_, _ = os.Stdout.Write(content)
-		}
-		return
-	}
-
-	// 使用缓冲区输出
-	// 大于警告级别的往标准错误缓冲区写
-	if level > logger.Warn {
-		_, _ = e.stderrWriteBuffer.Write(content)
-	} else {
-		_, _ = e.stdoutWriteBuffer.Write(content)
-	}
+	e.streamOf(level).write(content)
 }
 
 // PrintStack 调用栈日志打印方法
@@ -147,52 +229,81 @@ func (e *Adapter) Print(_ time.Time, level logger.Level, content []byte) {
 //	@param	lineNo		日志记录调用文件行号
 //	@param	methodName	日志记录调用函数名
 func (e *Adapter) PrintStack(_ time.Time, level logger.Level, content []byte, _ string, _ int, _ string) {
-	// 是否禁用颜色
-	if !e.disabledColor {
-		oldBytes := []byte{'[', level.Byte(), ']'}
-		if !bytes.Contains(content, oldBytes) {
-			oldBytes = make([]byte, 0, len(level.String())+2)
-			oldBytes = append(oldBytes, '[')
-			oldBytes = append(oldBytes, level.String()...)
-			oldBytes = append(oldBytes, ']')
+	e.streamOf(level).write(content)
+}
+
+// 获取日志级别对应的输出流
+func (e *Adapter) streamOf(level logger.Level) *stream {
+	switch e.route {
+	case RouteStdout:
+		return e.stdout
+	case RouteStderr:
+		return e.stderr
+	default:
+		if level >= e.stderrLevel {
+			return e.stderr
 		}
-		newBytes := getLevelConsoleColorBytes(level)
-		newBytes = append(newBytes, oldBytes...)
-		newBytes = append(newBytes, colorResetBytes[:]...)
-		// 替换颜色
-		content = bytes.Replace(
-			content,
-			oldBytes,
-			newBytes,
-			1,
-		)
-	}
-
-	// 是否禁用缓冲区 或 缓冲器输出器为空
-	if e.disabledBuffer || e.stdoutWriteBuffer == nil {
-		// 大于警告级别的往标准错误写
-		if level > logger.Warn {
-			_, _ = os.Stderr.Write(content)
-		} else {
-			_, _ = os.Stdout.Write(content)
+		return e.stdout
+	}
+}
+
+// 按输出流选择编码器的编码器
+//
+//	两个输出流是否输出颜色可能不同，因此按日志级别选择对应输出流的编码器
+type streamEncoder struct {
+	adapter *Adapter       // 控制台日志适配器
+	stdout  logger.Encoder // 标准输出流编码器
+	stderr  logger.Encoder // 标准错误流编码器
+}
+
+// 按各输出流是否输出颜色生成编码器副本
+func (e *Adapter) streamEncoder(enc logger.Encoder) logger.Encoder {
+	themed := func(s *stream) logger.Encoder {
+		if s.color {
+			return encoder.WithTheme(enc, e.theme)
 		}
-		return
+		return encoder.WithTheme(enc, nil)
 	}
+	return &streamEncoder{adapter: e, stdout: themed(e.stdout), stderr: themed(e.stderr)}
+}
 
-	// 使用缓冲区输出
-	// 大于警告级别的往标准错误缓冲区写
-	if level > logger.Warn {
-		_, _ = e.stderrWriteBuffer.Write(content)
-	} else {
-		_, _ = e.stdoutWriteBuffer.Write(content)
+// 获取日志级别对应输出流的编码器
+func (e *streamEncoder) of(l logger.Level) logger.Encoder {
+	if e.adapter.streamOf(l) == e.adapter.stdout {
+		return e.stdout
 	}
+	return e.stderr
+}
+
+// Encode 编码输出方法
+func (e *streamEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return e.of(l).Encode(dst, t, l, msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (e *streamEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	return e.of(l).EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
 }
 
 // Flush 日志缓存刷新
 //
 //	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
 func (e *Adapter) Flush() {
-	if e.stdoutWriteBuffer != nil {
-		_ = e.stdoutWriteBuffer.Flush()
+	e.stdout.flush()
+	if e.stderr != e.stdout {
+		e.stderr.flush()
 	}
 }
+
+// Close 关闭适配器
+//
+//	停止定时刷新协程并刷新缓冲区，标准输出及标准错误不会被关闭
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.closeSignal)
+	})
+	e.Flush()
+	return nil
+}
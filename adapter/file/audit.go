@@ -0,0 +1,204 @@
+/**
+ *@Title 文件日志审计模式
+ *@Desc 开启审计模式后每条日志都会追加哈希链值，可使用pkg/audit校验日志是否被篡改
+ */
+
+package file
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bearki/belog/v3/pkg/audit"
+	"github.com/bearki/belog/v3/pkg/crypt"
+)
+
+// 恢复哈希链时尾部采样的大小
+const auditTailSize = 64 * 1024
+
+// 打开日志分段的明文内容
+//
+//	@param	file		文件句柄
+//	@param	encrypted	日志分段是否已加密
+//	@return	明文读取器（加密分段且未提供密钥时为nil）
+func (e *fileWriter) plainReader(file *os.File, encrypted bool) io.Reader {
+	if !encrypted {
+		return file
+	}
+	if e.encryption == nil {
+		return nil
+	}
+	return crypt.NewReader(file, e.encryption)
+}
+
+// 判断日志分段能否以当前的审计模式继续写入
+//
+//	开启审计模式时只能追加到以分段头开始且末尾完整的日志分段，
+//	未开启审计模式时不能追加到带分段头的日志分段
+//
+//	@param	fileName	日志分段路径
+//	@param	encrypted	日志分段是否已加密
+//	@return	能否继续写入
+func (e *fileWriter) auditCompatible(fileName string, encrypted bool) bool {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	r := e.plainReader(file, encrypted)
+	if r == nil {
+		return false
+	}
+	head := make([]byte, len(audit.HeaderPrefix))
+	n, _ := io.ReadFull(r, head)
+	isAudit := n == len(head) && string(head) == audit.HeaderPrefix
+	if isAudit != (e.chain != nil) {
+		return false
+	}
+
+	// 末尾存在不完整的日志时不再追加，避免下一条日志与其拼接在同一行
+	if e.chain != nil && !encrypted {
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return false
+		}
+		last := make([]byte, 1)
+		if _, err = file.ReadAt(last, fileInfo.Size()-1); err != nil || last[0] != '\n' {
+			return false
+		}
+	}
+	return true
+}
+
+// 从已有的日志分段中恢复哈希链
+//
+//	从最新的日志分段开始查找最后一条带链值的日志，找不到时从头开始
+func (e *fileWriter) resumeChain() error {
+	// 查找当前写入器生成的所有日志分段
+	//
+	//	通配符会匹配到同一文件夹下以相同名称开头的路由日志文件（如app.errors.log的分段），
+	//	因此需要按分段命名格式精确匹配文件名
+	pattern := strings.Replace(e.logPathFormat, "%s", "*", 1)
+	pattern = strings.Replace(pattern, "%d", "*", 1)
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	re := segmentNameRegexp(filepath.Base(e.logPathFormat))
+	fileNames := matches[:0]
+	for _, v := range matches {
+		if re.MatchString(filepath.Base(v)) {
+			fileNames = append(fileNames, v)
+		}
+	}
+	audit.SortSegments(fileNames)
+
+	for i := len(fileNames) - 1; i >= 0; i-- {
+		seq, prev, ok, err := e.readChainState(fileNames[i])
+		if err != nil {
+			return err
+		}
+		if ok {
+			e.chain.Resume(seq, prev)
+			return nil
+		}
+	}
+	return nil
+}
+
+// 根据日志分段命名格式生成精确匹配分段文件名的正则
+//
+//	@param	format	日志分段文件名格式（如app.%s.%d.log）
+//	@return	正则表达式
+func segmentNameRegexp(format string) *regexp.Regexp {
+	i := strings.Index(format, "%s")
+	j := strings.Index(format, "%d")
+	return regexp.MustCompile("^" + regexp.QuoteMeta(format[:i]) + `[0-9]{4}-[0-9]{2}-[0-9]{2}` +
+		regexp.QuoteMeta(format[i+2:j]) + `[0-9]+` + regexp.QuoteMeta(format[j+2:]) + "$")
+}
+
+// 读取日志分段中最后一条带链值的日志的位置
+//
+//	@param	fileName	日志分段路径
+//	@return	日志序号
+//	@return	日志链值
+//	@return	是否找到
+//	@return	异常信息
+func (e *fileWriter) readChainState(fileName string) (uint64, []byte, bool, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0, nil, false, err
+	}
+	head := make([]byte, len(crypt.Magic))
+	n, _ := io.ReadFull(file, head)
+	encrypted := crypt.IsEncrypted(head[:n])
+
+	// 未加密的大文件先从尾部查找
+	if !encrypted && fileInfo.Size() > auditTailSize {
+		if _, err = file.Seek(fileInfo.Size()-auditTailSize, io.SeekStart); err != nil {
+			return 0, nil, false, err
+		}
+		br := bufio.NewReader(file)
+		// 丢弃第一行不完整的内容
+		if _, err = br.ReadSlice('\n'); err == nil {
+			if seq, prev, ok := scanChainState(br); ok {
+				return seq, prev, true, nil
+			}
+		}
+	}
+
+	// 从头查找
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, false, err
+	}
+	r := e.plainReader(file, encrypted)
+	if r == nil {
+		return 0, nil, false, nil
+	}
+	seq, prev, ok := scanChainState(bufio.NewReader(r))
+	return seq, prev, ok, nil
+}
+
+// 查找最后一个分段头或带链值的日志的位置
+func scanChainState(br *bufio.Reader) (uint64, []byte, bool) {
+	var seq uint64
+	var prev []byte
+	var found bool
+	for {
+		line, err := br.ReadBytes('\n')
+		// 不完整的行不参与查找
+		if err != nil {
+			return seq, prev, found
+		}
+		if s, p, ok := audit.ParseHeader(line); ok {
+			seq, prev, found = s, p, true
+		} else if _, s, p, ok := audit.ParseRecord(line); ok {
+			seq, prev, found = s, p, true
+		}
+	}
+}
+
+// 为日志追加链值
+//
+//	未开启审计模式时原样追加
+//
+//	@param	dst			填充目标
+//	@param	logBytes	日志内容
+//	@return	填充后的内容
+func (e *fileWriter) appendRecord(dst []byte, logBytes []byte) []byte {
+	if e.chain == nil {
+		return append(dst, logBytes...)
+	}
+	return e.chain.Append(dst, logBytes)
+}
@@ -0,0 +1,144 @@
+/**
+ *@Title 文件日志批量写入与同步策略
+ *@Desc 批量写入会将管道中的所有日志合并为一次写入，同步策略决定何时将文件内容持久化到磁盘
+ */
+
+package file
+
+import (
+	"bufio"
+	"os"
+	"time"
+)
+
+// SyncPolicy 文件同步（fsync）策略
+type SyncPolicy uint8
+
+// 文件同步策略定义
+const (
+	SyncAuto     SyncPolicy = 0 // 同步写入模式下每次写入后同步，异步写入模式下仅在刷新时同步（默认）
+	SyncNever    SyncPolicy = 1 // 从不主动同步，交由操作系统决定（刷新时仍会同步）
+	SyncPerBatch SyncPolicy = 2 // 每次写入（批量写入模式下为每个批次）后同步
+	SyncInterval SyncPolicy = 3 // 每隔指定时间同步一次
+	SyncBytes    SyncPolicy = 4 // 每写入指定字节数同步一次
+)
+
+// 获取定时同步信号管道
+//
+//	非定时同步策略时返回nil管道，select时将永远阻塞
+func (e *fileWriter) syncTicker() (<-chan time.Time, func()) {
+	if e.syncPolicy != SyncInterval {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(e.syncInterval)
+	return ticker.C, ticker.Stop
+}
+
+// 将缓冲区内容写入文件并同步到磁盘
+func (e *fileWriter) syncFile(file *os.File, writer *bufio.Writer) {
+	if writer.Buffered() > 0 {
+		if err := writer.Flush(); err != nil {
+			printWarningMsg(err.Error())
+		}
+	}
+	e.flushSeal()
+	if e.unsyncedSize == 0 {
+		return
+	}
+	if err := file.Sync(); err != nil {
+		printWarningMsg(err.Error())
+	}
+	e.unsyncedSize = 0
+}
+
+// 写入完成后根据同步策略同步文件
+//
+//	@param	file	文件句柄
+//	@param	writer	写入缓冲区
+//	@param	n		本次写入的字节数
+func (e *fileWriter) syncAfterWrite(file *os.File, writer *bufio.Writer, n int) {
+	e.unsyncedSize += uint64(n)
+
+	switch e.syncPolicy {
+
+	// 同步写入模式下每次写入后同步
+	case SyncAuto:
+		if !e.fileWriteAsync {
+			e.syncFile(file, writer)
+		}
+
+	// 每次写入后同步
+	case SyncPerBatch:
+		e.syncFile(file, writer)
+
+	// 写入量达到阈值后同步
+	case SyncBytes:
+		if e.unsyncedSize >= e.syncBytes {
+			e.syncFile(file, writer)
+		}
+	}
+}
+
+// 批量写入日志
+//
+//	取出管道及二级缓冲区中当前可用的所有日志（不超过单批次最大字节数），合并为一次写入
+//
+//	@param	file		文件句柄
+//	@param	writer		写入缓冲区
+//	@param	logBytes	首条日志内容
+//	@return	大小或行数是否超过了限制
+func (e *fileWriter) writeBatch(file *os.File, writer *bufio.Writer, logBytes []byte) bool {
+	// 缓冲区中残留的内容需要先写入，保证日志顺序
+	if writer.Buffered() > 0 {
+		if err := writer.Flush(); err != nil {
+			printWarningMsg(err.Error())
+		}
+	}
+
+	// 合并日志
+	batch := e.appendRecord(e.batchBuf[:0], logBytes)
+	e.logBytesPool.Put(logBytes)
+	lines := uint64(1)
+	for len(batch) < e.batchMaxSize {
+		select {
+		case logBytes = <-e.fileWriteChan:
+		default:
+			// 管道已空，继续取出二级缓冲区中的日志
+			logBytes = e.unspill()
+		}
+		if logBytes == nil {
+			break
+		}
+		batch = e.appendRecord(batch, logBytes)
+		e.logBytesPool.Put(logBytes)
+		lines++
+	}
+
+	// 单次写入
+	count, err := e.out.Write(batch)
+	if err != nil {
+		printWarningMsg(err.Error())
+	}
+
+	// 超大批次不保留，避免长期占用内存
+	if cap(batch) <= e.batchMaxSize*2 {
+		e.batchBuf = batch[:0]
+	}
+
+	// 增加当前文件已写入的大小和行数
+	e.currSize += uint64(count)
+	e.currLines += lines
+
+	// 提交已写入文件的日志
+	if e.ring != nil {
+		// 加密模式下需要先将分块缓冲区中的内容写入文件
+		e.flushSeal()
+		e.ring.commit(int(lines))
+	}
+
+	// 根据同步策略同步文件
+	e.syncAfterWrite(file, writer, count)
+
+	// 判断大小或行数是否超过
+	return e.currSize >= e.maxSize || e.currLines >= e.maxLines
+}
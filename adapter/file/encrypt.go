@@ -0,0 +1,240 @@
+/**
+ *@Title 文件日志加密
+ *@Desc 开启加密后日志内容将按分块加密后写入日志文件，可使用pkg/crypt读取
+ */
+
+package file
+
+import (
+	"io"
+	"os"
+
+	"github.com/bearki/belog/v3/pkg/crypt"
+)
+
+// 加密分块写入器
+//
+//	写入的明文先保存在分块缓冲区中，分块缓冲区已满或调用Flush时加密为一个分块写入文件
+type sealWriter struct {
+	file      *os.File      // 文件句柄
+	sealer    *crypt.Sealer // 分块加密器
+	chunkSize int           // 分块明文最大大小
+	plain     []byte        // 分块明文缓冲区
+	sealed    []byte        // 分块密文缓冲区
+	size      uint64        // 已写入文件的大小
+}
+
+// 创建加密分块写入器
+//
+//	空文件将先写入加密文件头，已有的加密文件将从最后一个分块之后继续写入
+//
+//	@param	file	文件句柄
+//	@param	size	文件当前大小
+//	@return	加密分块写入器
+//	@return	异常信息
+func (e *fileWriter) newSealWriter(file *os.File, size uint64) (*sealWriter, error) {
+	w := &sealWriter{
+		file:      file,
+		sealer:    e.sealer,
+		chunkSize: e.chunkSize,
+		plain:     e.chunkBuf[:0],
+		size:      size,
+	}
+	if size == 0 {
+		header, err := w.sealer.Start(nil)
+		if err != nil {
+			return nil, err
+		}
+		n, err := file.Write(header)
+		w.size += uint64(n)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	// 读取已有分段的文件ID及分块数量（写入句柄为追加模式，需单独打开）
+	r, err := os.Open(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	id, chunks, err := crypt.ScanSegment(r)
+	if err != nil {
+		return nil, err
+	}
+	w.sealer.Resume(id, chunks)
+	return w, nil
+}
+
+// Write 写入明文
+func (w *sealWriter) Write(p []byte) (int, error) {
+	total := len(p)
+	for len(w.plain)+len(p) > w.chunkSize && len(w.plain) > 0 {
+		// 补满当前分块后加密写入
+		n := w.chunkSize - len(w.plain)
+		w.plain = append(w.plain, p[:n]...)
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+		p = p[n:]
+	}
+	w.plain = append(w.plain, p...)
+	if len(w.plain) >= w.chunkSize {
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// Flush 将分块缓冲区中的明文加密写入文件
+func (w *sealWriter) Flush() error {
+	if len(w.plain) == 0 {
+		return nil
+	}
+	return w.seal(false)
+}
+
+// Finish 将分块缓冲区中的明文加密为结束分块写入文件
+//
+//	关闭日志分段前调用，缓冲区为空时写入空的结束分块
+func (w *sealWriter) Finish() error {
+	return w.seal(true)
+}
+
+// 加密分块缓冲区中的明文并写入文件
+func (w *sealWriter) seal(final bool) error {
+	var err error
+	w.sealed, err = w.sealer.Seal(w.sealed[:0], w.plain, final)
+	if err != nil {
+		return err
+	}
+	n, err := w.file.Write(w.sealed)
+	w.size += uint64(n)
+	w.plain = w.plain[:0]
+	return err
+}
+
+// 打开日志分段的输出
+//
+//	未开启加密时直接输出到文件
+//
+//	@param	file	文件句柄
+//	@param	size	文件当前大小
+//	@return	日志分段输出
+//	@return	异常信息
+func (e *fileWriter) openOutput(file *os.File, size uint64) (io.Writer, error) {
+	var out io.Writer = file
+	e.seal = nil
+	if e.encryption != nil {
+		seal, err := e.newSealWriter(file, size)
+		if err != nil {
+			return nil, err
+		}
+		e.seal = seal
+		out = seal
+	}
+
+	// 审计模式下新的日志分段以分段头开始
+	if e.chain != nil && size == 0 {
+		n, err := out.Write(e.chain.AppendHeader(nil))
+		e.currSize += uint64(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// 将分块缓冲区中的明文加密写入文件
+func (e *fileWriter) flushSeal() {
+	if e.seal == nil {
+		return
+	}
+	if err := e.seal.Flush(); err != nil {
+		printWarningMsg(err.Error())
+	}
+	// 保留分块缓冲区，供下个日志分段复用
+	e.chunkBuf = e.seal.plain[:0]
+}
+
+// 写入结束分块（关闭日志分段时调用）
+func (e *fileWriter) finishSeal() {
+	if e.seal == nil {
+		return
+	}
+	if err := e.seal.Finish(); err != nil {
+		printWarningMsg(err.Error())
+	}
+	e.chunkBuf = e.seal.plain[:0]
+}
+
+// 获取写入分段索引的文件大小
+//
+//	加密模式下记录的是密文大小，与文件的实际大小保持一致
+func (e *fileWriter) indexSize() uint64 {
+	if e.seal != nil {
+		return e.seal.size
+	}
+	return e.currSize
+}
+
+// 读取日志分段的行数并判断该分段能否继续写入
+//
+//	加密模式下只能追加到索引与文件大小一致的加密分段，
+//	未加密模式下不能追加到加密分段，否则将导致同一分段中混合明文与密文，
+//	审计模式的限制见auditCompatible
+//
+//	@param	fileName	日志分段路径
+//	@return	日志分段行数
+//	@return	能否继续写入
+//	@return	异常信息
+func (e *fileWriter) segmentLines(fileName string) (uint64, bool, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, true, nil
+		}
+		return 0, false, err
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return 0, false, err
+	}
+	head := make([]byte, len(crypt.Magic))
+	n, _ := io.ReadFull(file, head)
+	file.Close()
+	if fileInfo.Size() == 0 {
+		return 0, true, nil
+	}
+	encrypted := crypt.IsEncrypted(head[:n])
+
+	// 未开启加密
+	if e.encryption == nil {
+		if encrypted || !e.auditCompatible(fileName, false) {
+			return 0, false, nil
+		}
+		lines, err := countSegmentLines(fileName)
+		return lines, err == nil, err
+	}
+
+	// 开启了加密，无法从密文中统计行数，只能依赖索引
+	if !encrypted || !e.auditCompatible(fileName, true) {
+		return 0, false, nil
+	}
+	size, lines, ok := readSegmentIndex(fileName)
+	if !ok || size != uint64(fileInfo.Size()) {
+		return 0, false, nil
+	}
+	// 末尾存在不完整的分块时无法继续写入
+	if file, err = os.Open(fileName); err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+	if _, _, err = crypt.ScanSegment(file); err != nil {
+		return 0, false, nil
+	}
+	return lines, true, nil
+}
@@ -8,25 +8,30 @@
 package file
 
 import (
-	"bufio"
-	"errors"
-	"fmt"
-	"log"
-	"math"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/bearki/belog/v3/field"
 	"github.com/bearki/belog/v3/logger"
-	"github.com/bearki/belog/v3/pkg/pool"
+	"github.com/bearki/belog/v3/pkg/crypt"
 )
 
+// DefaultName 文件日志适配器默认名称
+const DefaultName = "belog-file-adapter"
+
 // Options 文件日志适配器参数
 type Options struct {
 
+	// 适配器名称
+	//
+	// 同一记录器挂载多个文件日志适配器时，需要为每个适配器指定不同的名称，
+	// 否则后挂载的适配器将覆盖先挂载的适配器
+	//
+	// Default: belog-file-adapter
+	Name string
+
 	// 日志文件储存路径
 	//
 	// Default: ${work_dir}/app.log
@@ -64,33 +69,143 @@ type Options struct {
 	//
 	// Default: 1, Min: 1, Max: 100
 	AsyncChanCap uint
-}
 
-// Adapter 文件日志适配器
-type Adapter struct {
+	// 异步写入管道溢出策略
+	//
+	// 仅在Async=true时生效，
+	// 可通过(*Adapter).Stats()获取被丢弃的日志条数
+	//
+	// Default: OverflowBlock
+	Overflow OverflowPolicy
+
+	// 二级环形缓冲区容量
+	//
+	// 仅在Overflow=OverflowSpill时生效，缓冲区已满时将丢弃其中最旧的日志
+	//
+	// Default: 1000, Min: 1, Max: 1000000
+	SpillCap uint
+
+	// 丢弃提示编码器
+	//
+	// 发生丢弃且写入压力缓解后，会向文件写入一条"N records dropped"的警告日志，
+	// 该编码器用于生成这条日志，为空时使用纯文本格式
+	//
+	// Default: nil
+	DropNoticeEncoder logger.Encoder
+
+	// 是否开启批量写入
+	//
+	// 开启后写入协程每次会取出管道中当前可用的所有日志，合并为一次写入，
+	// 建议配合Async=true及较大的AsyncChanCap使用
+	//
+	// Default: false
+	BatchWrite bool
+
+	// 批量写入单批次最大容量
+	//
+	// 仅在BatchWrite=true时生效
+	//
+	// Unit: KB, Default: 1024, Min: 4, Max: 65536
+	BatchMaxSize uint
+
+	// 文件同步（fsync）策略
+	//
+	// 用于权衡日志持久性与写入吞吐量
+	//
+	// Default: SyncAuto
+	SyncPolicy SyncPolicy
+
+	// 定时同步间隔
+	//
+	// 仅在SyncPolicy=SyncInterval时生效
+	//
+	// Unit: 毫秒, Default: 1000, Min: 1, Max: 3600000
+	SyncInterval uint
+
+	// 定量同步阈值
+	//
+	// 仅在SyncPolicy=SyncBytes时生效
+	//
+	// Unit: KB, Default: 1024, Min: 1, Max: 1048576
+	SyncBytes uint
+
+	// 日志路由规则
+	//
+	// 每条规则对应一个独立的日志文件，匹配规则的日志会额外写入该文件，
+	// 主日志文件（LogPath）始终记录所有日志，
+	// 路由日志文件的分割、保存及写入方式与主日志文件一致
+	//
+	// 注意：路由规则由记录器通过logger.TagAdapter接口在记录日志时匹配，
+	// 主日志文件及路由日志文件均使用记录器编码器的输出，
+	// 直接调用Print或PrintStack时仅写入主日志文件，最多支持64条路由规则
+	//
+	// Default: nil
+	Routes []Route
+
+	// 是否开启崩溃保护
+	//
+	// 开启后日志在进入写入管道前会先追加到内存映射的环形缓冲区文件（日志文件同目录下的隐藏文件）中，
+	// 进程被强制结束（如SIGKILL、OOM）后，下次创建适配器时会将尚未写入文件的日志恢复到当前日志文件，
+	// 恢复的日志可能与进程结束前已写入的日志存在少量重复
+	//
+	// 注意：开启后将强制使用批量写入及阻塞溢出策略，仅支持类Unix平台
+	//
+	// Default: false
+	CrashSafe bool
 
-	// 外部传入字段
+	// 崩溃保护环形缓冲区容量
+	//
+	// 仅在CrashSafe=true时生效，缓冲区已满时将等待日志写入文件
+	//
+	// Unit: KB, Default: 4096, Min: 64, Max: 1048576
+	CrashSafeSize uint
 
-	logPath        string      // 日志文件保存路径（默认：app.log）
-	maxSize        uint64      // 单文件最大容量（单位：byte, 默认：4MB）
-	maxLines       uint64      // 单文件最大保存行数（默认：10万行）
-	saveDay        uint16      // 日志最大保存天数（默认：30天）
-	fileWriteAsync bool        // 日志写入是否为异步（默认：false）
-	fileWriteChan  chan []byte // 日志写入缓冲管道（默认：1）
+	// 加密密钥提供者
+	//
+	// 设置后日志文件将使用AES-GCM按分块加密，每个分块使用密钥提供者当前的密钥加密，
+	// 可通过crypt.NewReader或belog-decrypt工具解密，
+	// 日志分段关闭时写入结束分块，进程异常退出时最后一个不完整的分块无法解密，
+	// 读取未正常关闭的日志分段将在末尾返回crypt.ErrTruncated，此前的内容不受影响
+	//
+	// 注意：无法从密文中统计行数，重新打开时只会追加到分段索引有效的加密日志文件，
+	// 不会追加到未加密的日志文件，反之亦然
+	//
+	// Default: nil（不加密）
+	Encryption crypt.KeyProvider
 
-	// 内部字段
+	// 加密分块明文最大容量
+	//
+	// 仅在Encryption!=nil时生效，分块缓冲区已满、刷新、同步或分割文件时将加密为一个分块写入文件
+	//
+	// Unit: KB, Default: 64, Min: 1, Max: 16384
+	EncryptChunkSize uint
 
-	logPathFormat    string        // 日志文件路径格式
-	currLogPath      string        // 日志文件源文件路径
-	currTime         time.Time     // 当前日志文件使用的日期
-	currIndex        uint32        // 当前日志文件分割后缀标识
-	currSize         uint64        // 当前日志文件大小（单位：byte）
-	currLines        uint64        // 当前日志文件行数
-	flushMutex       sync.Mutex    // 刷新操作锁
-	flushStartSignal chan struct{} // 刷新开始信号
-	flushOverSignal  chan struct{} // 刷新结束信号
+	// 是否开启审计模式
+	//
+	// 开启后每个日志分段都以记录哈希链位置的分段头开始，每条日志末尾都会追加"\t序号:链值"，
+	// 链值为HMAC-SHA256(AuditKey, 上一条日志链值 + 序号 + 日志内容)，
+	// 可使用audit.VerifyFiles校验日志是否被修改、删除或调换顺序，
+	// 重新创建适配器时将从已有的日志分段中恢复哈希链
+	//
+	// 注意：主日志文件与每个路由日志文件各自维护独立的哈希链
+	//
+	// Default: false
+	Audit bool
 
-	logBytesPool *pool.BytesPool // 日志字节流对象池
+	// 审计模式哈希链密钥
+	//
+	// 仅在Audit=true时生效，为空时退化为SHA-256，只能发现意外的修改
+	//
+	// Default: nil
+	AuditKey []byte
+}
+
+// Adapter 文件日志适配器
+type Adapter struct {
+	name    string        // 适配器名称
+	writer  *fileWriter   // 主日志文件写入器（记录所有日志）
+	routes  []routeWriter // 路由日志文件写入器
+	writers []*fileWriter // 全部日志文件写入器
 }
 
 // 打印警告信息
@@ -103,6 +218,10 @@ func printWarningMsg(msg string) {
 func (p *Options) validity() {
 	// 转换路径为当前系统格式
 	p.LogPath = filepath.Join(p.LogPath)
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
 	// 判断路径是否为空
 	if len(p.LogPath) <= 0 {
 		p.LogPath = "app.log"
@@ -129,9 +248,63 @@ func (p *Options) validity() {
 			p.AsyncChanCap = 1
 			printWarningMsg("async log channel cap error, min 1, max 100, use the default value 1")
 		}
+		if p.AsyncChanCap == 0 {
+			p.AsyncChanCap = 1
+		}
+		// 判断溢出策略
+		if p.Overflow > OverflowSpill {
+			p.Overflow = OverflowBlock
+			printWarningMsg("async log overflow policy error, use the default value OverflowBlock")
+		}
+		// 判断二级缓冲区容量
+		if p.Overflow == OverflowSpill && (p.SpillCap < 1 || p.SpillCap > 1000000) {
+			p.SpillCap = 1000
+			printWarningMsg("async log spill cap min value is 1,max value is 1000000, use the default value 1000")
+		}
 	} else {
 		// 非异步情况下管道容量为1
 		p.AsyncChanCap = 1
+		// 非异步情况下始终阻塞等待
+		p.Overflow = OverflowBlock
+	}
+	// 崩溃保护模式下强制使用批量写入及阻塞溢出策略
+	if p.CrashSafe {
+		p.Overflow = OverflowBlock
+		if !p.BatchWrite {
+			p.BatchWrite = true
+			p.BatchMaxSize = 1024
+		}
+		if p.CrashSafeSize < 64 || p.CrashSafeSize > 1048576 {
+			p.CrashSafeSize = 4096
+			printWarningMsg("crash-safe ring size min value is 64(KB),max value is 1048576(KB), use the default value 4096(KB)")
+		}
+	}
+	// 判断批量写入单批次最大容量
+	if p.BatchWrite && (p.BatchMaxSize < 4 || p.BatchMaxSize > 65536) {
+		p.BatchMaxSize = 1024
+		printWarningMsg("batch write max size min value is 4(KB),max value is 65536(KB), use the default value 1024(KB)")
+	}
+	// 判断加密分块容量
+	if p.Encryption != nil && (p.EncryptChunkSize < 1 || p.EncryptChunkSize > 16384) {
+		p.EncryptChunkSize = 64
+		printWarningMsg("encrypt chunk size min value is 1(KB),max value is 16384(KB), use the default value 64(KB)")
+	}
+	// 判断同步策略
+	switch p.SyncPolicy {
+	case SyncAuto, SyncNever, SyncPerBatch:
+	case SyncInterval:
+		if p.SyncInterval < 1 || p.SyncInterval > 3600000 {
+			p.SyncInterval = 1000
+			printWarningMsg("file sync interval min value is 1(ms),max value is 3600000(ms), use the default value 1000(ms)")
+		}
+	case SyncBytes:
+		if p.SyncBytes < 1 || p.SyncBytes > 1048576 {
+			p.SyncBytes = 1024
+			printWarningMsg("file sync bytes min value is 1(KB),max value is 1048576(KB), use the default value 1024(KB)")
+		}
+	default:
+		p.SyncPolicy = SyncAuto
+		printWarningMsg("file sync policy error, use the default value SyncAuto")
 	}
 }
 
@@ -143,57 +316,41 @@ func (p *Options) validity() {
 func New(options Options) (logger.Adapter, error) {
 	// 判断参数有效性
 	options.validity()
-	// 创建路径的文件夹部分
-	err := os.MkdirAll(filepath.Dir(options.LogPath), 0755)
-	if err != nil {
+	// 判断路由参数有效性
+	if err := options.routesValidity(); err != nil {
 		return nil, err
 	}
-
-	// 预处理一些变量
-	// 分割文件夹与文件名部分
-	logDir, logFile := filepath.Split(options.LogPath)
-	// 截取文件名后缀
-	logExt := filepath.Ext(logFile)
-	// 日志文件名（不含后缀）
-	logName := strings.TrimSuffix(logFile, logExt)
-	// 定义MB的字节大小
-	MB := uint64(1024 * 1024)
+	// 检查加密密钥是否可用
+	if options.Encryption != nil {
+		if _, err := crypt.NewSealer(options.Encryption).Seal(nil, nil, false); err != nil {
+			return nil, err
+		}
+	}
 
 	// 实例化文件日志适配器
-	e := new(Adapter)
-	// 赋值软链文件名
-	e.logPath = options.LogPath
-	// 赋值文件大小限制
-	e.maxSize = uint64(options.MaxSize) * MB
-	// 赋值文件最大行数
-	e.maxLines = options.MaxLines
-	// 赋值日志保存天数
-	e.saveDay = options.SaveDay
-	// 赋值日志文件路径生成格式
-	e.logPathFormat = filepath.Join(logDir, logName+".%s.%d"+logExt)
-	// 赋值是否为异步写入
-	e.fileWriteAsync = options.Async
-	// 初始化日志写入管道容量
-	e.fileWriteChan = make(chan []byte, options.AsyncChanCap)
-	// 筛选出合适的下标日志文件
-	if err = e.selectAvailableFile(); err != nil {
+	e := &Adapter{
+		name: options.Name,
+	}
+
+	// 创建主日志文件写入器
+	w, err := newFileWriter(options.LogPath, options)
+	if err != nil {
 		return nil, err
 	}
-	// 初始化刷新信号管道
-	e.flushStartSignal = make(chan struct{}, 1)
-	e.flushOverSignal = make(chan struct{}, 1)
-	// 日志字节流对象池
-	e.logBytesPool = pool.NewBytesPool(100, 0, 1024)
-
-	// 异步执行一次过期日志文件删除
-	go e.deleteTimeoutLogFile()
-	// 异步死循环监听文件写入
-	go func() {
-		for {
-			// 阻塞开启监听写入日志
-			e.writeFile()
+	e.writer = w
+	e.writers = append(e.writers, w)
+
+	// 创建路由日志文件写入器
+	for _, route := range options.Routes {
+		w, err = newFileWriter(route.LogPath, options)
+		if err != nil {
+			// 关闭已创建的写入器
+			e.close()
+			return nil, err
 		}
-	}()
+		e.routes = append(e.routes, routeWriter{match: route.Match, writer: w})
+		e.writers = append(e.writers, w)
+	}
 
 	// 返回文件日志实例
 	return e, nil
@@ -203,7 +360,7 @@ func New(options Options) (logger.Adapter, error) {
 //
 //	注意：请确保适配器名称不与其他适配器名称冲突
 func (e *Adapter) Name() string {
-	return "belog-file-adapter"
+	return e.name
 }
 
 // Print 普通日志打印方法
@@ -212,16 +369,8 @@ func (e *Adapter) Name() string {
 //	@param	level	日志级别
 //	@param	content	日志内容
 func (e *Adapter) Print(_ time.Time, _ logger.Level, c []byte) {
-	// 从对象池获取切片
-	logSlice := e.logBytesPool.Get()
-	// 检查是否需要扩容
-	if cap(logSlice) < len(c) {
-		// 创建一个指定容量的切片，避免二次扩容
-		logSlice = make([]byte, 0, len(c))
-	}
-
-	// 发送到管道
-	e.fileWriteChan <- append(logSlice, c...)
+	// 仅写入主日志文件
+	e.writer.print(c)
 }
 
 // PrintStack 调用栈日志打印方法
@@ -233,310 +382,83 @@ func (e *Adapter) Print(_ time.Time, _ logger.Level, c []byte) {
 //	@param	lineNo		日志记录调用文件行号
 //	@param	methodName	日志记录调用函数名
 func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, c []byte, _ string, _ int, _ string) {
-	// 从对象池获取切片
-	logSlice := e.logBytesPool.Get()
-	// 检查是否需要扩容
-	if cap(logSlice) < len(c) {
-		// 创建一个指定容量的切片，避免二次扩容
-		logSlice = make([]byte, 0, len(c))
-	}
-
-	// 发送到管道
-	e.fileWriteChan <- append(logSlice, c...)
+	// 仅写入主日志文件
+	e.writer.print(c)
 }
 
-// Flush 日志缓存刷新
+// Tag 生成日志路由标记
 //
-//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
-func (e *Adapter) Flush() {
-	// 加锁
-	e.flushMutex.Lock()
-	defer e.flushMutex.Unlock()
-
-	// 发送刷新开始信号
-	e.flushStartSignal <- struct{}{}
-
-	// 阻塞，直到刷新完成
-	<-e.flushOverSignal
+//	由记录器在记录日志的协程中调用，标记中记录了日志匹配的路由规则
+//
+//	@param	level	日志级别
+//	@param	msg		日志描述
+//	@param	val		日志内容字段
+//	@return	日志路由标记
+func (e *Adapter) Tag(level logger.Level, msg string, val []field.Field) uint64 {
+	return e.route(level, msg, val)
 }
 
-// 打开文件并获取文件总行数
-func openFileGetLines(fileName string, flag int, closeFile bool) (*os.File, uint64, error) {
-	// 打开文件
-	file, err := os.OpenFile(fileName, flag, 0666)
-	if err != nil {
-		return nil, 0, err
-	}
-	if closeFile {
-		defer file.Close()
-	}
-	// 行数统计
-	var lines uint64 = 0
-	// 获取文件行数
-	reader := bufio.NewScanner(file)
-	for reader.Scan() {
-		lines++
-	}
-	// 返回行数
-	return file, lines, nil
+// PrintTag 带路由标记的普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+//	@param	tag		日志路由标记
+func (e *Adapter) PrintTag(_ time.Time, _ logger.Level, c []byte, tag uint64) {
+	e.printRoute(c, tag)
 }
 
-// 选择一个可用的文件
-func (e *Adapter) selectAvailableFile() error {
-	// 赋值当前日期
-	e.currTime = time.Now()
-	// 循环取文件名
-	for i := 1; i <= math.MaxInt32; i++ {
-		// 赋值文件分割后缀标识
-		e.currIndex = uint32(i)
-		// 以当前日期命名文件名
-		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), i)
-
-		// 先获取文件信息
-		fileInfo, err := os.Stat(e.currLogPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				// 文件不存在，则该文件可用，跳出循环
-				return nil
-			}
-			// 文件存在，但获取信息错误
-			return err
-		}
-
-		// 判断文件大小是否超过限制
-		if fileInfo.Size() >= int64(e.maxSize) {
-			// 超过了限制，递增后缀标识
-			continue
-		}
-
-		// 判断文件是否超过了最大行数
-		_, lines, err := openFileGetLines(e.currLogPath, os.O_RDONLY, true)
-		if err != nil {
-			// 文件异常
-			return err
-		}
-		if lines >= e.maxLines {
-			// 超过了限制，递增后缀标识
-			continue
-		}
-
-		// 文件可用
-		return nil
-	}
-
-	// 全部文件不可用
-	return errors.New("no available files found")
+// PrintStackTag 带路由标记的调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	tag			日志路由标记
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStackTag(_ time.Time, _ logger.Level, c []byte, tag uint64, _ string, _ int, _ string) {
+	e.printRoute(c, tag)
 }
 
-// 过期日志文件删除
-func (e *Adapter) deleteTimeoutLogFile() {
-	// 获取日志储存文件夹部分
-	logDirPath := filepath.Dir(e.logPath)
-	// 打开文件夹
-	logDir, err := os.ReadDir(logDirPath)
-	if err != nil {
-		printWarningMsg(err.Error())
-		return
-	}
-
-	// 获取当天整点时间
-	now := time.Now()
-	// 再解析成时间类型
-	currDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
-	// 初始化正则
-	re := regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}`)
-	// 遍历文件夹
-	for _, item := range logDir {
-		// 当前路径不是文件夹并且文件名不是当前正在使用的日志文件名
-		if !item.IsDir() && filepath.Base(e.logPath) != item.Name() {
-			// 获取文件名中的时间部分
-			fileDateStr := re.FindString(item.Name())
-			// 解析成时间类型
-			fileDate, err := time.Parse("2006-01-02", fileDateStr)
-			if err != nil {
-				continue
-			}
-			// 比对两个时间是否大于指定的保存天数
-			if currDate.Sub(fileDate).Hours() >= float64(24*e.saveDay) {
-				// 删除这个文件
-				err = os.Remove(filepath.Join(logDirPath, item.Name()))
-				if err != nil {
-					printWarningMsg(err.Error())
-				}
-			}
-		}
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+func (e *Adapter) Flush() {
+	for _, w := range e.writers {
+		w.flush()
 	}
 }
 
-// 日志文件是否需要分割
-func (e *Adapter) fileSplit() bool {
-	// 获取当前时间
-	currTime := time.Now()
-	// 比对一下当前日志文件的日期和当前日期是否是同一天
-	if currTime.Day() != e.currTime.Day() { // 不是同一天
-		// 赋值新日期
-		e.currTime = currTime
-		// 赋值新后缀标识
-		e.currIndex = 1
-		// 拼接后缀标识及文件后缀
-		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), e.currIndex)
-		// 通知执行文件分割
-		return true
-	}
-
-	// 判断容量或行数是否超过了
-	if e.currSize >= e.maxSize || e.currLines >= e.maxLines {
-		// 后缀标识加1
-		e.currIndex++
-		// 拼接后缀标识及文件后缀
-		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), e.currIndex)
-		// 通知执行文件分割
-		return true
-	}
-
-	// 不分隔文件
-	return false
+// Close 关闭适配器
+//
+//	将缓冲区中的日志写入文件后停止全部写入协程，关闭后写入的日志将被丢弃
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	return e.close()
 }
 
-// 写入日志到文件中
-func (e *Adapter) writeFile() {
-	// 函数结束时的操作
-	defer func() {
-		// 异步执行一次过期日志文件删除
-		go e.deleteTimeoutLogFile()
-	}()
-
-	// 移除硬连接
-	err := os.RemoveAll(e.logPath)
-	if err != nil {
-		log.Fatalln("remove file error: " + err.Error())
-	}
-
-	// 创建或追加文件，并获取文件总行数
-	file, lines, err := openFileGetLines(e.currLogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_SYNC, false)
-	if err != nil {
-		log.Fatalln("open file error: " + err.Error())
-	}
-	defer func() {
-		file.Sync()  // 同步IO底层缓存到磁盘
-		file.Close() // 关闭文件句柄
-	}()
-
-	// 赋值当前文件大小
-	fileStat, _ := file.Stat()
-	e.currSize = uint64(fileStat.Size())
-	// 赋值当前文件行数
-	e.currLines = lines
-
-	// 创建写入缓冲区
-	writer := bufio.NewWriter(file)
-	defer func() {
-		writer.Flush() // 结束时刷新到文件中
-	}()
-
-	// 创建硬连接
-	err = os.Link(e.currLogPath, e.logPath)
-	if err != nil && !errors.Is(err, os.ErrExist) {
-		log.Fatalln("create file link error: " + err.Error())
+// 关闭全部日志文件写入器
+func (e *Adapter) close() error {
+	var err error
+	for _, w := range e.writers {
+		if werr := w.close(); err == nil {
+			err = werr
+		}
 	}
-
-	// 阻塞，执行监听写入
-	e.listenBufioWrite(file, writer)
+	return err
 }
 
-// 监听日志并通过bufio写入
-func (e *Adapter) listenBufioWrite(file *os.File, writer *bufio.Writer) {
-	// 计算距离第二天凌晨0点还有多少时间
-	tmpTime := e.currTime.AddDate(0, 0, 1)
-	zeroTime := time.Date(tmpTime.Year(), tmpTime.Month(), tmpTime.Day(), 0, 0, 0, 0, time.Local)
-
-	// 分割文件的信号管道，
-	fileSplitChan := time.After(zeroTime.Sub(e.currTime))
-
-	// 在指定时间间隔强制刷新一次缓冲区
-	specifiedTimeAfter := time.NewTicker(time.Minute * 5)
-	defer specifiedTimeAfter.Stop()
-
-	// 预声明
-	var count int
-	var err error
-
-	// 监听文件写入或重新打开新的日志文件
-	for {
-		select {
-
-		// 是否监听到日志来了
-		case logBytes := <-e.fileWriteChan:
-			// 检查内容
-			if logBytes == nil {
-				// 跳过
-				break
-			}
-
-			// 判断写入模式
-			if e.fileWriteAsync {
-				// 异步模式使用缓冲区写入
-				count, err = writer.Write(logBytes)
-			} else {
-				// 使用文件句柄直接写入
-				count, err = file.Write(logBytes)
-			}
-			if err != nil {
-				printWarningMsg(err.Error())
-			}
-
-			// 将字节流对象放回对象池
-			e.logBytesPool.Put(logBytes)
-
-			// 增加当前文件已写入的大小
-			e.currSize += uint64(count)
-			// 增加当前文件已写入的行数
-			e.currLines++
-
-			// 判断大小或行数是否超过
-			if e.currSize >= e.maxSize || e.currLines >= e.maxLines {
-				// 判断一下是否需要分隔文件了
-				if e.fileSplit() {
-					// 结束当前文件的写入
-					return
-				}
-			}
-
-		// 是否接收到日志刷新信号
-		case <-e.flushStartSignal:
-			// 管道中是否还有内容
-			num := len(e.fileWriteChan)
-			for i := 0; i < num; i++ {
-				logStr := <-e.fileWriteChan
-				if logStr == nil {
-					continue
-				}
-				if _, err := writer.Write(logStr); err != nil {
-					printWarningMsg(err.Error())
-				}
-			}
-
-			// 缓冲区内有内容时执行缓冲区刷新
-			if writer.Buffered() > 0 {
-				writer.Flush()
-				file.Sync()
-			}
-
-			// 发送刷新完成信号
-			e.flushOverSignal <- struct{}{}
-
-		// 是否到达凌晨0点了
-		case <-fileSplitChan:
-			if e.fileSplit() || writer.Buffered() > 0 {
-				return
-			}
-
-		// 是否需要强制刷新了
-		case <-specifiedTimeAfter.C:
-			if e.fileSplit() || writer.Buffered() > 0 {
-				return
-			}
-		}
+// Stats 获取适配器统计信息
+//
+//	@return	所有日志文件的统计信息之和
+func (e *Adapter) Stats() Stats {
+	var s Stats
+	for _, w := range e.writers {
+		ws := w.stats()
+		s.Dropped += ws.Dropped
+		s.Spilled += ws.Spilled
 	}
+	return s
 }
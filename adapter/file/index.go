@@ -0,0 +1,147 @@
+/**
+ *@Title 文件日志分段索引
+ *@Desc 重新打开日志分段时通过索引文件获取行数，避免扫描整个文件
+ */
+
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// 分段索引相关常量
+const (
+	indexFileExt   = ".idx"            // 索引文件后缀
+	indexFileSize  = 24                // 索引文件大小（标识8字节 + 文件大小8字节 + 行数8字节）
+	countChunkSize = 32 * 1024         // 统计行数时单次读取的块大小
+	tailSampleSize = 64 * 1024         // 估算行数时尾部采样的大小
+	fullCountLimit = 4 * 1024 * 1024   // 无索引时允许完整统计行数的最大文件大小
+	indexMagic     = "BELOGIDX"        // 索引文件标识
+	indexFilePerm  = os.FileMode(0666) // 索引文件权限
+)
+
+// 获取日志分段对应的索引文件路径
+//
+//	索引文件与日志分段位于同一文件夹，以隐藏文件的形式保存，
+//	文件名中保留了日期部分，过期时会随日志分段一起被删除
+func indexFilePath(fileName string) string {
+	dir, name := filepath.Split(fileName)
+	return filepath.Join(dir, "."+name+indexFileExt)
+}
+
+// 读取日志分段索引
+//
+//	@param	fileName	日志分段路径
+//	@return	索引记录时的文件大小
+//	@return	索引记录时的文件行数
+//	@return	索引是否有效
+func readSegmentIndex(fileName string) (size uint64, lines uint64, ok bool) {
+	b, err := os.ReadFile(indexFilePath(fileName))
+	if err != nil || len(b) != indexFileSize || string(b[:8]) != indexMagic {
+		return 0, 0, false
+	}
+	return binary.LittleEndian.Uint64(b[8:16]), binary.LittleEndian.Uint64(b[16:24]), true
+}
+
+// 写入日志分段索引
+//
+//	@param	fileName	日志分段路径
+//	@param	size		当前文件大小
+//	@param	lines		当前文件行数
+//	@return	异常信息
+func writeSegmentIndex(fileName string, size uint64, lines uint64) error {
+	var b [indexFileSize]byte
+	copy(b[:8], indexMagic)
+	binary.LittleEndian.PutUint64(b[8:16], size)
+	binary.LittleEndian.PutUint64(b[16:24], lines)
+	return os.WriteFile(indexFilePath(fileName), b[:], indexFilePerm)
+}
+
+// 统计文件指定区间内的行数
+//
+//	按块读取并统计换行符数量，不受单行长度限制
+func countLines(file *os.File, start int64, end int64) (uint64, error) {
+	var lines uint64
+	buf := make([]byte, countChunkSize)
+	for start < end {
+		n := int64(len(buf))
+		if end-start < n {
+			n = end - start
+		}
+		c, err := file.ReadAt(buf[:n], start)
+		lines += uint64(bytes.Count(buf[:c], []byte{'\n'}))
+		start += int64(c)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return lines, err
+		}
+	}
+	return lines, nil
+}
+
+// 通过尾部采样估算文件行数
+//
+//	读取文件末尾的一段内容计算平均行长，再按文件大小推算总行数
+func estimateLines(file *os.File, size int64) (uint64, error) {
+	sample := int64(tailSampleSize)
+	if size < sample {
+		sample = size
+	}
+	lines, err := countLines(file, size-sample, size)
+	if err != nil {
+		return 0, err
+	}
+	// 采样区间内没有换行符，说明存在超长行，至少按一行计算
+	if lines == 0 {
+		return 1, nil
+	}
+	return uint64(size) * lines / uint64(sample), nil
+}
+
+// 获取日志分段文件的行数
+//
+//	优先使用索引文件中记录的行数，仅统计索引记录之后追加的内容；
+//	索引不存在或已失效时，小文件完整统计，大文件通过尾部采样估算
+//
+//	@param	fileName	日志分段路径
+//	@return	文件行数
+//	@return	异常信息
+func countSegmentLines(fileName string) (uint64, error) {
+	// 打开文件
+	file, err := os.Open(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+
+	// 获取文件大小
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := fileInfo.Size()
+
+	// 索引有效时只统计索引之后的增量部分
+	if idxSize, idxLines, ok := readSegmentIndex(fileName); ok && idxSize <= uint64(size) {
+		if idxSize == uint64(size) {
+			return idxLines, nil
+		}
+		lines, err := countLines(file, int64(idxSize), size)
+		return idxLines + lines, err
+	}
+
+	// 无可用索引
+	if size <= fullCountLimit {
+		return countLines(file, 0, size)
+	}
+	return estimateLines(file, size)
+}
@@ -0,0 +1,189 @@
+/**
+ *@Title 文件日志异步写入溢出处理
+ *@Desc 异步写入管道已满时，将根据溢出策略决定阻塞、丢弃或溢出到二级缓冲区
+ */
+
+package file
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// OverflowPolicy 异步写入管道溢出策略
+type OverflowPolicy uint8
+
+// 异步写入管道溢出策略定义
+const (
+	OverflowBlock      OverflowPolicy = 0 // 阻塞等待管道可写（默认）
+	OverflowDropNewest OverflowPolicy = 1 // 丢弃当前写入的日志
+	OverflowDropOldest OverflowPolicy = 2 // 丢弃管道中最旧的日志
+	OverflowSpill      OverflowPolicy = 3 // 溢出到二级环形缓冲区
+)
+
+// 丢弃提示的检查间隔
+const dropNoticeInterval = time.Second
+
+// Stats 文件日志适配器统计信息
+type Stats struct {
+	Dropped uint64 // 累计丢弃的日志条数
+	Spilled uint64 // 累计溢出到二级缓冲区的日志条数
+}
+
+// 获取写入器统计信息
+func (e *fileWriter) stats() Stats {
+	return Stats{
+		Dropped: atomic.LoadUint64(&e.droppedTotal),
+		Spilled: atomic.LoadUint64(&e.spilledTotal),
+	}
+}
+
+// 将日志内容发送到写入管道
+func (e *fileWriter) send(logBytes []byte) {
+	switch e.overflow {
+
+	// 管道已满时丢弃当前日志
+	case OverflowDropNewest:
+		select {
+		case e.fileWriteChan <- logBytes:
+		default:
+			e.drop(logBytes)
+		}
+
+	// 管道已满时丢弃最旧的日志，直到当前日志写入管道
+	case OverflowDropOldest:
+		for {
+			select {
+			case e.fileWriteChan <- logBytes:
+				return
+			default:
+			}
+			select {
+			case old := <-e.fileWriteChan:
+				e.drop(old)
+			default:
+			}
+		}
+
+	// 管道已满时溢出到二级缓冲区
+	case OverflowSpill:
+		e.spill(logBytes)
+
+	// 阻塞等待（写入器开始关闭后丢弃，避免写入协程退出后永久阻塞）
+	default:
+		select {
+		case e.fileWriteChan <- logBytes:
+		case <-e.closingSignal:
+			e.drop(logBytes)
+		}
+	}
+}
+
+// 丢弃日志并计数
+func (e *fileWriter) drop(logBytes []byte) {
+	e.logBytesPool.Put(logBytes)
+	atomic.AddUint64(&e.droppedTotal, 1)
+	atomic.AddUint64(&e.droppedPending, 1)
+}
+
+// 溢出到二级缓冲区
+//
+//	二级缓冲区不为空时，新日志也必须进入二级缓冲区，以保证日志的写入顺序
+func (e *fileWriter) spill(logBytes []byte) {
+	e.spillMutex.Lock()
+
+	// 二级缓冲区为空时优先写入管道
+	if e.spillLen == 0 {
+		select {
+		case e.fileWriteChan <- logBytes:
+			e.spillMutex.Unlock()
+			return
+		default:
+		}
+	}
+
+	// 二级缓冲区已满，丢弃最旧的日志
+	if e.spillLen == len(e.spillBuf) {
+		e.drop(e.spillBuf[e.spillHead])
+		e.spillBuf[e.spillHead] = nil
+		e.spillHead = (e.spillHead + 1) % len(e.spillBuf)
+		e.spillLen--
+	}
+
+	// 追加到二级缓冲区
+	e.spillBuf[(e.spillHead+e.spillLen)%len(e.spillBuf)] = logBytes
+	e.spillLen++
+	atomic.AddUint64(&e.spilledTotal, 1)
+	e.spillMutex.Unlock()
+
+	// 通知写入协程
+	select {
+	case e.spillSignal <- struct{}{}:
+	default:
+	}
+}
+
+// 从二级缓冲区取出最旧的日志
+//
+//	注意：管道中的日志总是早于二级缓冲区中的日志，因此只有管道为空时才允许取出
+func (e *fileWriter) unspill() []byte {
+	if e.spillBuf == nil || len(e.fileWriteChan) > 0 {
+		return nil
+	}
+
+	e.spillMutex.Lock()
+	defer e.spillMutex.Unlock()
+
+	if e.spillLen == 0 {
+		return nil
+	}
+	logBytes := e.spillBuf[e.spillHead]
+	e.spillBuf[e.spillHead] = nil
+	e.spillHead = (e.spillHead + 1) % len(e.spillBuf)
+	e.spillLen--
+	return logBytes
+}
+
+// 生成丢弃提示日志
+//
+//	仅在管道与二级缓冲区均为空（写入压力已缓解）且存在未提示的丢弃时生成
+//
+//	@param	dst	填充目标
+//	@return	填充后的内容
+func (e *fileWriter) dropNotice(dst []byte) []byte {
+	if len(e.fileWriteChan) > 0 || e.spillPending() {
+		return dst
+	}
+	n := atomic.SwapUint64(&e.droppedPending, 0)
+	if n == 0 {
+		return dst
+	}
+
+	// 使用指定的编码器生成
+	if e.dropNoticeEncoder != nil {
+		return e.dropNoticeEncoder.Encode(dst, time.Now(), logger.Warn, "records dropped", field.Uint64("dropped", n))
+	}
+
+	// 使用纯文本格式生成
+	dst = time.Now().AppendFormat(dst, "2006/01/02 15:04:05.000")
+	dst = append(dst, " ["...)
+	dst = append(dst, logger.Warn.Byte())
+	dst = append(dst, "]  "...)
+	dst = strconv.AppendUint(dst, n, 10)
+	dst = append(dst, " records dropped\r\n"...)
+	return dst
+}
+
+// 二级缓冲区中是否还有日志
+func (e *fileWriter) spillPending() bool {
+	if e.spillBuf == nil {
+		return false
+	}
+	e.spillMutex.Lock()
+	defer e.spillMutex.Unlock()
+	return e.spillLen > 0
+}
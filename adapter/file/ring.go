@@ -0,0 +1,214 @@
+/**
+ *@Title 文件日志崩溃保护环形缓冲区
+ *@Desc 日志在进入写入管道前先追加到内存映射的环形文件中，进程异常退出后可在下次启动时恢复
+ */
+
+package file
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 环形缓冲区相关常量
+//
+//	文件布局：标识(8) + 容量(8) + 写入位置(8) + 持久化位置(8) + 数据区
+//	记录布局：长度(4) + CRC32(4) + 内容
+//	写入位置与持久化位置均为单调递增的字节偏移量，对容量取模后得到数据区中的实际位置
+const (
+	ringFileExt    = ".ring"    // 环形缓冲区文件后缀
+	ringMagic      = "BELOGRNG" // 环形缓冲区文件标识
+	ringHeaderSize = 32         // 环形缓冲区文件头大小
+	ringFrameSize  = 8          // 记录头大小
+)
+
+// 环形缓冲区空间不足时的等待间隔
+const ringWaitInterval = time.Millisecond
+
+// 崩溃保护环形缓冲区
+//
+//	生产者持有appendMutex完成追加并将日志发送到写入管道，保证环形缓冲区与管道中的顺序一致；
+//	写入协程将日志写入文件后按顺序提交，推进持久化位置
+type crashRing struct {
+	tail uint64 // 已持久化位置（原子操作，需保持64位对齐）
+
+	data     []byte // 映射的内存区域（含文件头）
+	capacity uint64 // 数据区容量
+	head     uint64 // 写入位置（受appendMutex保护）
+
+	appendMutex sync.Mutex // 追加操作锁
+	endsMutex   sync.Mutex // 待提交队列操作锁
+	ends        []uint64   // 待提交的记录结束位置队列
+}
+
+// 获取日志文件对应的环形缓冲区文件路径
+func ringFilePath(fileName string) string {
+	dir, name := filepath.Split(fileName)
+	return filepath.Join(dir, "."+name+ringFileExt)
+}
+
+// 创建崩溃保护环形缓冲区
+//
+//	注意：已存在的环形缓冲区文件将被清空，调用前需先通过readRingPending恢复未持久化的日志
+//
+//	@param	fileName	环形缓冲区文件路径
+//	@param	capacity	数据区容量
+//	@return	环形缓冲区
+//	@return	异常信息
+func openCrashRing(fileName string, capacity uint64) (*crashRing, error) {
+	// 重新创建环形缓冲区文件
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err = file.Truncate(int64(ringHeaderSize + capacity)); err != nil {
+		return nil, err
+	}
+
+	// 映射到内存
+	data, err := mmapFile(file, ringHeaderSize+int(capacity))
+	if err != nil {
+		return nil, err
+	}
+	copy(data[:8], ringMagic)
+	binary.LittleEndian.PutUint64(data[8:16], capacity)
+
+	return &crashRing{
+		data:     data,
+		capacity: capacity,
+	}, nil
+}
+
+// 读取环形缓冲区文件中未持久化的日志
+//
+//	@param	fileName	环形缓冲区文件路径
+//	@return	未持久化的日志
+//	@return	异常信息
+func readRingPending(fileName string) ([][]byte, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(data) < ringHeaderSize || string(data[:8]) != ringMagic {
+		return nil, nil
+	}
+	capacity := binary.LittleEndian.Uint64(data[8:16])
+	head := binary.LittleEndian.Uint64(data[16:24])
+	tail := binary.LittleEndian.Uint64(data[24:32])
+	if capacity == 0 || uint64(len(data)) != ringHeaderSize+capacity || tail > head || head-tail > capacity {
+		return nil, errors.New("crash-safe ring file `" + fileName + "` is corrupted")
+	}
+
+	// 依次读取记录，遇到损坏的记录时停止
+	r := &crashRing{data: data, capacity: capacity}
+	var pending [][]byte
+	var frame [ringFrameSize]byte
+	for off := tail; off+ringFrameSize <= head; {
+		r.read(off, frame[:])
+		size := uint64(binary.LittleEndian.Uint32(frame[:4]))
+		if off+ringFrameSize+size > head {
+			break
+		}
+		content := make([]byte, size)
+		r.read(off+ringFrameSize, content)
+		if crc32.ChecksumIEEE(content) != binary.LittleEndian.Uint32(frame[4:]) {
+			break
+		}
+		pending = append(pending, content)
+		off += ringFrameSize + size
+	}
+	return pending, nil
+}
+
+// 从数据区读取内容（自动处理回绕）
+func (r *crashRing) read(off uint64, dst []byte) {
+	area := r.data[ringHeaderSize:]
+	pos := off % r.capacity
+	n := copy(dst, area[pos:])
+	copy(dst[n:], area)
+}
+
+// 向数据区写入内容（自动处理回绕）
+func (r *crashRing) write(off uint64, src []byte) {
+	area := r.data[ringHeaderSize:]
+	pos := off % r.capacity
+	n := copy(area[pos:], src)
+	copy(area, src[n:])
+}
+
+// 追加一条日志
+//
+//	注意：调用方需持有appendMutex
+//
+//	空间不足时将等待写入协程提交，超过容量的日志不受保护
+func (r *crashRing) append(content []byte) {
+	size := ringFrameSize + uint64(len(content))
+	if size <= r.capacity {
+		// 等待可用空间
+		for r.head+size-atomic.LoadUint64(&r.tail) > r.capacity {
+			time.Sleep(ringWaitInterval)
+		}
+
+		// 先写入记录，再更新写入位置
+		var frame [ringFrameSize]byte
+		binary.LittleEndian.PutUint32(frame[:4], uint32(len(content)))
+		binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(content))
+		r.write(r.head, frame[:])
+		r.write(r.head+ringFrameSize, content)
+		r.head += size
+		binary.LittleEndian.PutUint64(r.data[16:24], r.head)
+	}
+
+	// 记录结束位置，等待提交
+	r.endsMutex.Lock()
+	r.ends = append(r.ends, r.head)
+	r.endsMutex.Unlock()
+}
+
+// 提交已写入文件的日志
+//
+//	@param	n	按顺序写入文件的日志条数
+func (r *crashRing) commit(n int) {
+	if n <= 0 {
+		return
+	}
+
+	r.endsMutex.Lock()
+	if n > len(r.ends) {
+		n = len(r.ends)
+	}
+	if n == 0 {
+		r.endsMutex.Unlock()
+		return
+	}
+	tail := r.ends[n-1]
+	r.ends = r.ends[:copy(r.ends, r.ends[n:])]
+	r.endsMutex.Unlock()
+
+	binary.LittleEndian.PutUint64(r.data[24:32], tail)
+	atomic.StoreUint64(&r.tail, tail)
+}
+
+// 关闭环形缓冲区（解除内存映射）
+//
+//	注意：调用前需确保写入协程已退出且不会再追加日志
+//
+//	@return	异常信息
+func (r *crashRing) close() error {
+	if r.data == nil {
+		return nil
+	}
+	err := munmapFile(r.data)
+	r.data = nil
+	return err
+}
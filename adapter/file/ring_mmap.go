@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// 将文件以共享方式映射到内存
+//
+//	共享映射的内容由操作系统负责写回文件，进程被强制结束后不会丢失
+func mmapFile(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// 解除内存映射
+func munmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package file
+
+import (
+	"errors"
+	"os"
+)
+
+// 将文件以共享方式映射到内存
+//
+//	当前平台不支持，崩溃保护模式不可用
+func mmapFile(_ *os.File, _ int) ([]byte, error) {
+	return nil, errors.New("crash-safe mode is not supported on this platform")
+}
+
+// 解除内存映射
+//
+//	当前平台不支持，崩溃保护模式不可用
+func munmapFile(_ []byte) error {
+	return nil
+}
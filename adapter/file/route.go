@@ -0,0 +1,152 @@
+/**
+ *@Title 文件日志路由
+ *@Desc 按日志级别或字段值将日志额外写入到不同的日志文件
+ */
+
+package file
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// Matcher 日志路由匹配规则
+//
+//	由记录器在记录日志的协程中调用，不能修改或持有fields
+//
+//	@param	level	日志级别
+//	@param	msg		日志消息
+//	@param	fields	日志字段
+//	@return	是否匹配
+type Matcher func(level logger.Level, msg string, fields []field.Field) bool
+
+// Route 日志路由规则
+type Route struct {
+	// 日志文件储存路径
+	//
+	// 不能与主日志文件或其他路由日志文件的路径相同
+	LogPath string
+
+	// 路由匹配规则
+	//
+	// 可使用Levels、LevelRange、FieldEquals创建，也可自定义
+	Match Matcher
+}
+
+// 最大路由规则数量
+const maxRoutes = 64
+
+// 路由日志文件写入器
+type routeWriter struct {
+	match  Matcher     // 路由匹配规则
+	writer *fileWriter // 日志文件写入器
+}
+
+// 判断路由参数有效性
+func (p *Options) routesValidity() error {
+	// 路由标记最多容纳64条路由规则
+	if len(p.Routes) > maxRoutes {
+		return errors.New("the number of routes cannot exceed 64")
+	}
+	// 记录已使用的路径
+	used := map[string]struct{}{
+		p.LogPath: {},
+	}
+	for i := range p.Routes {
+		route := &p.Routes[i]
+		// 转换路径为当前系统格式
+		route.LogPath = filepath.Join(route.LogPath)
+		if len(route.LogPath) == 0 {
+			return errors.New("the `LogPath` of route cannot be empty")
+		}
+		if route.Match == nil {
+			return errors.New("the `Match` of route `" + route.LogPath + "` cannot be nil")
+		}
+		// 路径不能重复
+		if _, ok := used[route.LogPath]; ok {
+			return errors.New("the `LogPath` of route `" + route.LogPath + "` is already in use")
+		}
+		used[route.LogPath] = struct{}{}
+	}
+	return nil
+}
+
+// Levels 创建按日志级别匹配的路由规则
+//
+//	@param	levels	需要匹配的日志级别
+//	@return	路由匹配规则
+func Levels(levels ...logger.Level) Matcher {
+	var set [256]bool
+	for _, l := range levels {
+		set[l] = true
+	}
+	return func(level logger.Level, _ string, _ []field.Field) bool {
+		return set[level]
+	}
+}
+
+// LevelRange 创建按日志级别区间匹配的路由规则
+//
+//	@param	min	最小日志级别（包含）
+//	@param	max	最大日志级别（包含）
+//	@return	路由匹配规则
+func LevelRange(min logger.Level, max logger.Level) Matcher {
+	return func(level logger.Level, _ string, _ []field.Field) bool {
+		return min <= level && level <= max
+	}
+}
+
+// FieldEquals 创建按字段值匹配的路由规则
+//
+//	字符串及错误类型的字段直接比较原始值，其他类型比较普通编码器输出的字段值（如123、true）
+//
+//	@param	key		字段键名
+//	@param	value	字段值的字符串形式
+//	@return	路由匹配规则
+func FieldEquals(key string, value string) Matcher {
+	return func(_ logger.Level, _ string, fields []field.Field) bool {
+		for _, f := range fields {
+			if f.Key != key {
+				continue
+			}
+			if f.Type == field.TypeString || f.Type == field.TypeError {
+				if f.String == value {
+					return true
+				}
+				continue
+			}
+			var buf [64]byte
+			if string(encoder.AppendValue(buf[:0], f)) == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// 生成日志路由标记
+//
+//	第i位为1表示日志匹配第i条路由规则
+func (e *Adapter) route(l logger.Level, msg string, val []field.Field) uint64 {
+	var tag uint64
+	for i, r := range e.routes {
+		if r.match(l, msg, val) {
+			tag |= 1 << uint(i)
+		}
+	}
+	return tag
+}
+
+// 写入主日志文件及标记匹配的路由日志文件
+func (e *Adapter) printRoute(c []byte, tag uint64) {
+	e.writer.print(c)
+	for i := 0; tag != 0 && i < len(e.routes); i++ {
+		if tag&(1<<uint(i)) != 0 {
+			e.routes[i].writer.print(c)
+		}
+	}
+}
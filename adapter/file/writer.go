@@ -0,0 +1,771 @@
+/**
+ *@Title 日志文件写入器
+ *@Desc 单个日志文件的分割、写入和刷新将在这里完成
+ */
+
+package file
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/audit"
+	"github.com/bearki/belog/v3/pkg/crypt"
+	"github.com/bearki/belog/v3/pkg/pool"
+)
+
+// 日志文件写入器
+//
+//	每个日志文件（含路由文件）都由一个独立的写入器负责分割、写入和刷新
+type fileWriter struct {
+
+	// 统计字段（原子操作，需保持64位对齐）
+
+	droppedTotal   uint64 // 累计丢弃的日志条数
+	droppedPending uint64 // 尚未写入提示的丢弃条数
+	spilledTotal   uint64 // 累计溢出到二级缓冲区的日志条数
+	closed         uint32 // 写入器是否已关闭（原子操作）
+
+	// 外部传入字段
+
+	logPath        string      // 日志文件保存路径（默认：app.log）
+	maxSize        uint64      // 单文件最大容量（单位：byte, 默认：4MB）
+	maxLines       uint64      // 单文件最大保存行数（默认：10万行）
+	saveDay        uint16      // 日志最大保存天数（默认：30天）
+	fileWriteAsync bool        // 日志写入是否为异步（默认：false）
+	fileWriteChan  chan []byte // 日志写入缓冲管道（默认：1）
+
+	overflow          OverflowPolicy    // 异步写入管道溢出策略（默认：阻塞）
+	dropNoticeEncoder logger.Encoder    // 丢弃提示编码器（默认：纯文本）
+	batchWrite        bool              // 是否开启批量写入（默认：false）
+	batchMaxSize      int               // 批量写入单批次最大容量（单位：byte, 默认：1MB）
+	syncPolicy        SyncPolicy        // 文件同步策略（默认：SyncAuto）
+	syncInterval      time.Duration     // 定时同步间隔（默认：1秒）
+	syncBytes         uint64            // 定量同步阈值（单位：byte, 默认：1MB）
+	encryption        crypt.KeyProvider // 加密密钥提供者（默认：nil，不加密）
+	chunkSize         int               // 加密分块明文最大大小（单位：byte, 默认：64KB）
+
+	// 内部字段
+
+	logPathFormat    string        // 日志文件路径格式
+	currLogPath      string        // 日志文件源文件路径
+	currTime         time.Time     // 当前日志文件使用的日期
+	currIndex        uint32        // 当前日志文件分割后缀标识
+	currSize         uint64        // 当前日志文件大小（单位：byte）
+	currLines        uint64        // 当前日志文件行数
+	countedLogPath   string        // 当前行数所对应的日志文件路径
+	flushMutex       sync.Mutex    // 刷新操作锁
+	flushStartSignal chan struct{} // 刷新开始信号
+	flushOverSignal  chan struct{} // 刷新结束信号
+	closeSignal      chan struct{} // 关闭信号
+	closingSignal    chan struct{} // 开始关闭信号（关闭时关闭该管道，解除阻塞等待管道可写的调用方）
+	closeOverSignal  chan struct{} // 关闭完成信号（写入协程退出时关闭）
+
+	spillMutex  sync.Mutex    // 二级缓冲区操作锁
+	spillBuf    [][]byte      // 二级环形缓冲区
+	spillHead   int           // 二级缓冲区中最旧日志的下标
+	spillLen    int           // 二级缓冲区中的日志条数
+	spillSignal chan struct{} // 二级缓冲区写入信号
+
+	batchBuf     []byte // 批量写入合并缓冲区
+	unsyncedSize uint64 // 上次同步后写入的字节数
+
+	ring *crashRing // 崩溃保护环形缓冲区（未开启时为nil）
+
+	out      io.Writer     // 当前日志分段的输出（未开启加密时为文件句柄）
+	seal     *sealWriter   // 当前日志分段的加密分块写入器（未开启加密时为nil）
+	sealer   *crypt.Sealer // 分块加密器
+	chunkBuf []byte        // 加密分块明文缓冲区
+
+	chain    *audit.Chain // 审计哈希链（未开启时为nil）
+	auditBuf []byte       // 追加链值后的日志缓冲区
+
+	logBytesPool *pool.BytesPool // 日志字节流对象池
+}
+
+// 创建日志文件写入器
+//
+//	@param	logPath	日志文件储存路径
+//	@param	options	适配器参数（已校验）
+//	@return	日志文件写入器
+//	@return	异常信息
+func newFileWriter(logPath string, options Options) (*fileWriter, error) {
+	// 创建路径的文件夹部分
+	err := os.MkdirAll(filepath.Dir(logPath), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预处理一些变量
+	// 分割文件夹与文件名部分
+	logDir, logFile := filepath.Split(logPath)
+	// 截取文件名后缀
+	logExt := filepath.Ext(logFile)
+	// 日志文件名（不含后缀）
+	logName := strings.TrimSuffix(logFile, logExt)
+	// 定义MB的字节大小
+	MB := uint64(1024 * 1024)
+
+	// 实例化日志文件写入器
+	e := new(fileWriter)
+	// 赋值软链文件名
+	e.logPath = logPath
+	// 赋值文件大小限制
+	e.maxSize = uint64(options.MaxSize) * MB
+	// 赋值文件最大行数
+	e.maxLines = options.MaxLines
+	// 赋值日志保存天数
+	e.saveDay = options.SaveDay
+	// 赋值日志文件路径生成格式
+	e.logPathFormat = filepath.Join(logDir, logName+".%s.%d"+logExt)
+	// 赋值是否为异步写入
+	e.fileWriteAsync = options.Async
+	// 初始化日志写入管道容量
+	e.fileWriteChan = make(chan []byte, options.AsyncChanCap)
+	// 赋值溢出策略
+	e.overflow = options.Overflow
+	e.dropNoticeEncoder = options.DropNoticeEncoder
+	// 初始化二级缓冲区
+	if e.overflow == OverflowSpill {
+		e.spillBuf = make([][]byte, options.SpillCap)
+	}
+	e.spillSignal = make(chan struct{}, 1)
+	// 赋值批量写入参数
+	e.batchWrite = options.BatchWrite
+	e.batchMaxSize = int(options.BatchMaxSize) * 1024
+	// 赋值同步策略
+	e.syncPolicy = options.SyncPolicy
+	e.syncInterval = time.Duration(options.SyncInterval) * time.Millisecond
+	e.syncBytes = uint64(options.SyncBytes) * 1024
+	// 赋值加密参数
+	if options.Encryption != nil {
+		e.encryption = options.Encryption
+		e.chunkSize = int(options.EncryptChunkSize) * 1024
+		e.sealer = crypt.NewSealer(options.Encryption)
+	}
+	// 开启审计模式
+	if options.Audit {
+		e.chain = audit.NewChain(options.AuditKey)
+	}
+	// 筛选出合适的下标日志文件
+	if err = e.selectAvailableFile(); err != nil {
+		return nil, err
+	}
+	// 从已有的日志分段中恢复哈希链
+	if e.chain != nil {
+		if err = e.resumeChain(); err != nil {
+			return nil, err
+		}
+	}
+	// 开启崩溃保护
+	if options.CrashSafe {
+		// 先恢复上次未写入文件的日志，再重新创建环形缓冲区
+		ringPath := ringFilePath(logPath)
+		pending, err := readRingPending(ringPath)
+		if err != nil {
+			return nil, err
+		}
+		if err = e.recoverPending(pending); err != nil {
+			return nil, err
+		}
+		if e.ring, err = openCrashRing(ringPath, uint64(options.CrashSafeSize)*1024); err != nil {
+			return nil, err
+		}
+	}
+	// 初始化刷新信号管道
+	e.flushStartSignal = make(chan struct{}, 1)
+	e.flushOverSignal = make(chan struct{}, 1)
+	// 初始化关闭信号管道
+	e.closeSignal = make(chan struct{})
+	e.closingSignal = make(chan struct{})
+	e.closeOverSignal = make(chan struct{})
+	// 日志字节流对象池
+	e.logBytesPool = pool.NewBytesPool(100, 0, 1024)
+
+	// 异步执行一次过期日志文件删除
+	go e.deleteTimeoutLogFile()
+	// 异步循环监听文件写入，直到写入器关闭
+	go func() {
+		// 阻塞开启监听写入日志
+		for e.writeFile() {
+		}
+		close(e.closeOverSignal)
+	}()
+
+	// 返回日志文件写入器
+	return e, nil
+}
+
+// 复制日志内容并发送到写入管道
+//
+//	@param	c	日志内容
+func (e *fileWriter) print(c []byte) {
+	// 写入器已关闭
+	if atomic.LoadUint32(&e.closed) == 1 {
+		return
+	}
+
+	// 从对象池获取切片
+	logSlice := e.logBytesPool.Get()
+	// 检查是否需要扩容
+	if cap(logSlice) < len(c) {
+		// 创建一个指定容量的切片，避免二次扩容
+		logSlice = make([]byte, 0, len(c))
+	}
+
+	logSlice = append(logSlice, c...)
+
+	// 开启崩溃保护时先追加到环形缓冲区，并在持有锁的情况下发送到管道，保证两者顺序一致
+	if e.ring != nil {
+		e.ring.appendMutex.Lock()
+		if atomic.LoadUint32(&e.closed) == 1 {
+			e.ring.appendMutex.Unlock()
+			return
+		}
+		e.ring.append(logSlice)
+		e.send(logSlice)
+		e.ring.appendMutex.Unlock()
+		return
+	}
+
+	// 发送到管道
+	e.send(logSlice)
+}
+
+// 恢复上次未写入文件的日志
+//
+//	@param	pending	未写入文件的日志
+//	@return	异常信息
+func (e *fileWriter) recoverPending(pending [][]byte) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 追加到当前日志文件
+	file, err := os.OpenFile(e.currLogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	fileStat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	e.currSize = uint64(fileStat.Size())
+	out, err := e.openOutput(file, e.currSize)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	writer := bufio.NewWriter(out)
+	for _, v := range pending {
+		e.auditBuf = e.appendRecord(e.auditBuf[:0], v)
+		if _, err = writer.Write(e.auditBuf); err != nil {
+			file.Close()
+			return err
+		}
+		e.currSize += uint64(len(e.auditBuf))
+	}
+	if err = writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if e.seal != nil {
+		if err = e.seal.Finish(); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	// 增加当前文件行数并刷新分段索引
+	e.currLines += uint64(len(pending))
+	return writeSegmentIndex(e.currLogPath, e.indexSize(), e.currLines)
+}
+
+// 日志缓存刷新
+func (e *fileWriter) flush() {
+	// 加锁
+	e.flushMutex.Lock()
+	defer e.flushMutex.Unlock()
+
+	// 写入器已关闭
+	if atomic.LoadUint32(&e.closed) == 1 {
+		return
+	}
+
+	// 发送刷新开始信号
+	e.flushStartSignal <- struct{}{}
+
+	// 阻塞，直到刷新完成
+	<-e.flushOverSignal
+}
+
+// 关闭写入器
+//
+//	将管道及缓冲区中的日志写入文件后停止写入协程，可重复调用
+//
+//	@return	异常信息
+func (e *fileWriter) close() error {
+	e.flushMutex.Lock()
+	defer e.flushMutex.Unlock()
+
+	if !atomic.CompareAndSwapUint32(&e.closed, 0, 1) {
+		return nil
+	}
+	close(e.closingSignal)
+
+	// 通知写入协程并等待其退出
+	e.closeSignal <- struct{}{}
+	<-e.closeOverSignal
+
+	// 写入协程退出后仍进入管道的日志已无法写入文件，计入丢弃
+	for len(e.fileWriteChan) > 0 {
+		if logBytes := <-e.fileWriteChan; logBytes != nil {
+			e.drop(logBytes)
+		}
+	}
+
+	// 解除崩溃保护环形缓冲区的内存映射
+	if e.ring != nil {
+		e.ring.appendMutex.Lock()
+		defer e.ring.appendMutex.Unlock()
+		return e.ring.close()
+	}
+	return nil
+}
+
+// 选择一个可用的文件
+func (e *fileWriter) selectAvailableFile() error {
+	// 赋值当前日期
+	e.currTime = time.Now()
+	// 循环取文件名
+	for i := 1; i <= math.MaxInt32; i++ {
+		// 赋值文件分割后缀标识
+		e.currIndex = uint32(i)
+		// 以当前日期命名文件名
+		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), i)
+
+		// 先获取文件信息
+		fileInfo, err := os.Stat(e.currLogPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// 文件不存在，则该文件可用，跳出循环
+				e.currLines = 0
+				e.countedLogPath = e.currLogPath
+				return nil
+			}
+			// 文件存在，但获取信息错误
+			return err
+		}
+
+		// 判断文件大小是否超过限制
+		if fileInfo.Size() >= int64(e.maxSize) {
+			// 超过了限制，递增后缀标识
+			continue
+		}
+
+		// 判断文件是否超过了最大行数
+		lines, ok, err := e.segmentLines(e.currLogPath)
+		if err != nil {
+			// 文件异常
+			return err
+		}
+		if !ok || lines >= e.maxLines {
+			// 超过了限制，递增后缀标识
+			continue
+		}
+
+		// 文件可用
+		e.currLines = lines
+		e.countedLogPath = e.currLogPath
+		return nil
+	}
+
+	// 全部文件不可用
+	return errors.New("no available files found")
+}
+
+// 过期日志文件删除
+func (e *fileWriter) deleteTimeoutLogFile() {
+	// 获取日志储存文件夹部分
+	logDirPath := filepath.Dir(e.logPath)
+	// 打开文件夹
+	logDir, err := os.ReadDir(logDirPath)
+	if err != nil {
+		printWarningMsg(err.Error())
+		return
+	}
+
+	// 获取当天整点时间
+	now := time.Now()
+	// 再解析成时间类型
+	currDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// 初始化正则
+	re := regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}`)
+	// 遍历文件夹
+	for _, item := range logDir {
+		// 当前路径不是文件夹并且文件名不是当前正在使用的日志文件名
+		if !item.IsDir() && filepath.Base(e.logPath) != item.Name() {
+			// 获取文件名中的时间部分
+			fileDateStr := re.FindString(item.Name())
+			// 解析成时间类型
+			fileDate, err := time.Parse("2006-01-02", fileDateStr)
+			if err != nil {
+				continue
+			}
+			// 比对两个时间是否大于指定的保存天数
+			if currDate.Sub(fileDate).Hours() >= float64(24*e.saveDay) {
+				// 删除这个文件
+				err = os.Remove(filepath.Join(logDirPath, item.Name()))
+				if err != nil {
+					printWarningMsg(err.Error())
+				}
+			}
+		}
+	}
+}
+
+// 日志文件是否需要分割
+func (e *fileWriter) fileSplit() bool {
+	// 获取当前时间
+	currTime := time.Now()
+	// 比对一下当前日志文件的日期和当前日期是否是同一天
+	if currTime.Day() != e.currTime.Day() { // 不是同一天
+		// 赋值新日期
+		e.currTime = currTime
+		// 赋值新后缀标识
+		e.currIndex = 1
+		// 拼接后缀标识及文件后缀
+		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), e.currIndex)
+		// 通知执行文件分割
+		return true
+	}
+
+	// 判断容量或行数是否超过了
+	if e.currSize >= e.maxSize || e.currLines >= e.maxLines {
+		// 后缀标识加1
+		e.currIndex++
+		// 拼接后缀标识及文件后缀
+		e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), e.currIndex)
+		// 通知执行文件分割
+		return true
+	}
+
+	// 不分隔文件
+	return false
+}
+
+// 写入日志到文件中
+//
+//	@return	是否需要继续写入（写入器关闭后返回false）
+func (e *fileWriter) writeFile() bool {
+	// 函数结束时的操作
+	defer func() {
+		// 异步执行一次过期日志文件删除
+		if atomic.LoadUint32(&e.closed) == 0 {
+			go e.deleteTimeoutLogFile()
+		}
+	}()
+
+	// 移除硬连接
+	err := os.RemoveAll(e.logPath)
+	if err != nil {
+		log.Fatalln("remove file error: " + err.Error())
+	}
+
+	// 重新打开的不是已统计行数的文件时需要重新获取文件总行数，
+	// 否则直接沿用内存中的行数，避免每次重新打开都去读取文件
+	for e.currLogPath != e.countedLogPath {
+		lines, ok, err := e.segmentLines(e.currLogPath)
+		if err != nil {
+			log.Fatalln("get file lines error: " + err.Error())
+		}
+		if !ok {
+			// 该文件无法继续写入，递增后缀标识
+			e.currIndex++
+			e.currLogPath = fmt.Sprintf(e.logPathFormat, e.currTime.Format("2006-01-02"), e.currIndex)
+			continue
+		}
+		e.currLines = lines
+		e.countedLogPath = e.currLogPath
+	}
+
+	// 创建或追加文件
+	logPath := e.currLogPath
+	file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		log.Fatalln("open file error: " + err.Error())
+	}
+	defer func() {
+		file.Sync()  // 同步IO底层缓存到磁盘
+		file.Close() // 关闭文件句柄
+		// 记录分段索引，便于下次快速获取文件行数
+		if err := writeSegmentIndex(logPath, e.indexSize(), e.currLines); err != nil {
+			printWarningMsg(err.Error())
+		}
+	}()
+
+	// 赋值当前文件大小
+	fileStat, _ := file.Stat()
+	e.currSize = uint64(fileStat.Size())
+	e.unsyncedSize = 0
+
+	// 打开日志分段的输出
+	e.out, err = e.openOutput(file, e.currSize)
+	if err != nil {
+		log.Fatalln("open file output error: " + err.Error())
+	}
+
+	// 创建写入缓冲区
+	writer := bufio.NewWriter(e.out)
+	defer func() {
+		writer.Flush() // 结束时刷新到文件中
+		e.finishSeal() // 结束时将分块缓冲区中的内容加密为结束分块
+	}()
+
+	// 创建硬连接
+	err = os.Link(e.currLogPath, e.logPath)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		log.Fatalln("create file link error: " + err.Error())
+	}
+
+	// 阻塞，执行监听写入
+	return e.listenBufioWrite(file, writer)
+}
+
+// 监听日志并通过bufio写入
+//
+//	@return	是否需要继续写入（需要重新打开日志文件时返回true，写入器关闭后返回false）
+func (e *fileWriter) listenBufioWrite(file *os.File, writer *bufio.Writer) bool {
+	// 计算距离第二天凌晨0点还有多少时间
+	tmpTime := e.currTime.AddDate(0, 0, 1)
+	zeroTime := time.Date(tmpTime.Year(), tmpTime.Month(), tmpTime.Day(), 0, 0, 0, 0, time.Local)
+
+	// 分割文件的信号管道，
+	fileSplitChan := time.After(zeroTime.Sub(e.currTime))
+
+	// 在指定时间间隔强制刷新一次缓冲区
+	specifiedTimeAfter := time.NewTicker(time.Minute * 5)
+	defer specifiedTimeAfter.Stop()
+
+	// 定时同步文件
+	syncTickerChan, syncTickerStop := e.syncTicker()
+	defer syncTickerStop()
+
+	// 定时检查是否需要写入丢弃提示
+	dropNoticeTicker := time.NewTicker(dropNoticeInterval)
+	defer dropNoticeTicker.Stop()
+
+	// 二级缓冲区中残留的日志需要继续写入
+	if e.spillPending() {
+		select {
+		case e.spillSignal <- struct{}{}:
+		default:
+		}
+	}
+
+	// 监听文件写入或重新打开新的日志文件
+	for {
+		select {
+
+		// 是否监听到日志来了
+		case logBytes := <-e.fileWriteChan:
+			// 检查内容
+			if logBytes == nil {
+				// 跳过
+				break
+			}
+
+			// 批量写入模式
+			if e.batchWrite {
+				if e.writeBatch(file, writer, logBytes) && e.fileSplit() {
+					return true
+				}
+				break
+			}
+
+			// 写入日志并判断是否需要分隔文件了
+			if e.writeLog(file, writer, logBytes) && e.fileSplit() {
+				// 结束当前文件的写入
+				return true
+			}
+
+			// 管道已空，继续写入二级缓冲区中的日志
+			if e.writeSpill(file, writer) {
+				return true
+			}
+
+		// 是否有日志溢出到了二级缓冲区
+		case <-e.spillSignal:
+			if e.writeSpill(file, writer) {
+				return true
+			}
+
+		// 是否需要定时同步了
+		case <-syncTickerChan:
+			e.syncFile(file, writer)
+
+		// 是否需要写入丢弃提示了
+		case <-dropNoticeTicker.C:
+			if notice := e.dropNotice(nil); len(notice) > 0 {
+				if e.writeLog(file, writer, notice) && e.fileSplit() {
+					return true
+				}
+			}
+
+		// 是否接收到日志刷新信号
+		case <-e.flushStartSignal:
+			e.writePending(file, writer)
+			// 发送刷新完成信号
+			e.flushOverSignal <- struct{}{}
+
+		// 是否接收到关闭信号
+		case <-e.closeSignal:
+			e.writePending(file, writer)
+			return false
+
+		// 是否到达凌晨0点了
+		case <-fileSplitChan:
+			if e.fileSplit() || writer.Buffered() > 0 {
+				return true
+			}
+
+		// 是否需要强制刷新了
+		case <-specifiedTimeAfter.C:
+			if e.fileSplit() || writer.Buffered() > 0 {
+				return true
+			}
+		}
+	}
+}
+
+// 写入管道及二级缓冲区中剩余的日志并同步文件
+//
+//	@param	file	文件句柄
+//	@param	writer	写入缓冲区
+func (e *fileWriter) writePending(file *os.File, writer *bufio.Writer) {
+	// 管道中是否还有内容
+	num := len(e.fileWriteChan)
+	for i := 0; i < num; i++ {
+		logStr := <-e.fileWriteChan
+		if logStr == nil {
+			continue
+		}
+		e.writeLog(nil, writer, logStr)
+	}
+	// 二级缓冲区中是否还有内容
+	for logStr := e.unspill(); logStr != nil; logStr = e.unspill() {
+		e.writeLog(nil, writer, logStr)
+	}
+	// 是否需要写入丢弃提示
+	if notice := e.dropNotice(nil); len(notice) > 0 {
+		e.writeLog(nil, writer, notice)
+	}
+
+	// 缓冲区内有内容时执行缓冲区刷新
+	e.syncFile(file, writer)
+	// 提交已写入文件的日志
+	if e.ring != nil {
+		e.ring.commit(num)
+	}
+
+	// 刷新分段索引
+	if err := writeSegmentIndex(e.countedLogPath, e.indexSize(), e.currLines); err != nil {
+		printWarningMsg(err.Error())
+	}
+}
+
+// 写入一条日志
+//
+//	@param	file		文件句柄（为空时使用缓冲区写入）
+//	@param	writer		写入缓冲区
+//	@param	logBytes	日志内容
+//	@return	大小或行数是否超过了限制
+func (e *fileWriter) writeLog(file *os.File, writer *bufio.Writer, logBytes []byte) bool {
+	var count int
+	var err error
+
+	// 审计模式下追加链值
+	content := logBytes
+	if e.chain != nil {
+		e.auditBuf = e.chain.Append(e.auditBuf[:0], logBytes)
+		content = e.auditBuf
+	}
+
+	// 判断写入模式
+	if e.fileWriteAsync || file == nil {
+		// 异步模式使用缓冲区写入
+		count, err = writer.Write(content)
+	} else {
+		// 使用文件句柄直接写入
+		count, err = e.out.Write(content)
+	}
+	if err != nil {
+		printWarningMsg(err.Error())
+	}
+
+	// 将字节流对象放回对象池
+	e.logBytesPool.Put(logBytes)
+
+	// 增加当前文件已写入的大小
+	e.currSize += uint64(count)
+	// 增加当前文件已写入的行数
+	e.currLines++
+
+	// 根据同步策略同步文件
+	if file != nil {
+		e.syncAfterWrite(file, writer, count)
+	} else {
+		e.unsyncedSize += uint64(count)
+	}
+
+	// 判断大小或行数是否超过
+	return e.currSize >= e.maxSize || e.currLines >= e.maxLines
+}
+
+// 写入二级缓冲区中的日志
+//
+//	@param	file	文件句柄
+//	@param	writer	写入缓冲区
+//	@return	是否需要结束当前文件的写入
+func (e *fileWriter) writeSpill(file *os.File, writer *bufio.Writer) bool {
+	for logBytes := e.unspill(); logBytes != nil; logBytes = e.unspill() {
+		// 写入日志
+		var exceeded bool
+		if e.batchWrite {
+			exceeded = e.writeBatch(file, writer, logBytes)
+		} else {
+			exceeded = e.writeLog(file, writer, logBytes)
+		}
+		// 判断是否需要分隔文件了
+		if exceeded && e.fileSplit() {
+			// 通知下一个文件继续写入剩余内容
+			select {
+			case e.spillSignal <- struct{}{}:
+			default:
+			}
+			return true
+		}
+	}
+	return false
+}
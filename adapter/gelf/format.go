@@ -0,0 +1,176 @@
+/**
+ *@Title GELF日志格式
+ *@Desc GELF 1.1格式编码，字段映射为以下划线开头的附加字段
+ */
+
+package gelf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/convert"
+)
+
+// syslog严重性
+const (
+	severityCrit    uint8 = 2
+	severityErr     uint8 = 3
+	severityWarning uint8 = 4
+	severityInfo    uint8 = 6
+	severityDebug   uint8 = 7
+)
+
+// Severity 获取日志级别对应的GELF级别（syslog严重性）
+//
+//	Trace、Debug对应debug，Info对应informational，Warn对应warning，
+//	Error对应err，Fatal对应crit
+//
+//	@param	l	日志级别
+//	@return	GELF级别
+func Severity(l logger.Level) uint8 {
+	switch l {
+	case logger.Trace, logger.Debug:
+		return severityDebug
+	case logger.Info:
+		return severityInfo
+	case logger.Warn:
+		return severityWarning
+	case logger.Error:
+		return severityErr
+	case logger.Fatal:
+		return severityCrit
+	default:
+		return severityInfo
+	}
+}
+
+// GELF日志编码器
+//
+//	{"version":"1.1","host":"...","short_message":"...","full_message":"...","timestamp":1.000001,"level":6,
+//	"_file":"...","_line":1,"_func":"...","_字段":值,...}
+type gelfEncoder struct {
+	host      string // 主机名
+	skipStack bool   // 是否忽略调用栈信息
+}
+
+// Encode 编码输出方法
+func (e *gelfEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return e.encode(dst, t, l, "", 0, "", msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (e *gelfEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	if e.skipStack {
+		fn = ""
+	}
+	return e.encode(dst, t, l, fn, ln, mn, msg, val...)
+}
+
+// 编码一条日志
+func (e *gelfEncoder) encode(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	dst = append(dst, `{"version":"1.1","host":`...)
+	dst = encoder.AppendJSONString(dst, e.host)
+
+	// 多行消息的第一行作为short_message，完整消息作为full_message
+	short := msg
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		short = strings.TrimRight(msg[:i], "\r")
+	}
+	if len(short) == 0 {
+		short = "-"
+	}
+	dst = append(dst, `,"short_message":`...)
+	dst = encoder.AppendJSONString(dst, short)
+	if len(short) != len(msg) {
+		dst = append(dst, `,"full_message":`...)
+		dst = encoder.AppendJSONString(dst, msg)
+	}
+
+	// 时间戳（秒，保留微秒）
+	dst = append(dst, `,"timestamp":`...)
+	us := t.UnixNano() / int64(time.Microsecond)
+	sec, frac := us/1e6, us%1e6
+	if frac < 0 {
+		sec, frac = sec-1, frac+1e6
+	}
+	dst = strconv.AppendInt(dst, sec, 10)
+	dst = append(dst, '.')
+	for p := int64(100000); p > frac && p > 1; p /= 10 {
+		dst = append(dst, '0')
+	}
+	dst = strconv.AppendInt(dst, frac, 10)
+
+	// 级别
+	dst = append(dst, `,"level":`...)
+	dst = strconv.AppendUint(dst, uint64(Severity(l)), 10)
+
+	// 调用栈
+	if len(fn) > 0 {
+		dst = append(dst, `,"_file":`...)
+		dst = encoder.AppendJSONString(dst, fn)
+		dst = append(dst, `,"_line":`...)
+		dst = strconv.AppendInt(dst, int64(ln), 10)
+		dst = append(dst, `,"_func":`...)
+		dst = encoder.AppendJSONString(dst, mn)
+	}
+
+	// 附加字段
+	for i := range val {
+		dst = append(dst, ',', '"')
+		dst = appendFieldName(dst, val[i].Key)
+		dst = append(dst, '"', ':')
+		dst = appendFieldValue(dst, val[i])
+	}
+	return append(dst, '}')
+}
+
+// 追加附加字段名
+//
+//	附加字段名以下划线开头，仅允许字母、数字、下划线、点及中划线，其他字符将被替换为下划线；
+//	_id为GELF保留字段，键名为id时将使用__id
+func appendFieldName(dst []byte, key string) []byte {
+	dst = append(dst, '_')
+	if key == "id" {
+		dst = append(dst, '_')
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c == '_' || c == '.' || c == '-' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// 追加附加字段值
+//
+//	GELF附加字段仅支持数字及字符串，整型及有限浮点数保留为数字，其他类型转换为字符串
+func appendFieldValue(dst []byte, val field.Field) []byte {
+	switch val.Type {
+	case field.TypeInt8, field.TypeInt16, field.TypeInt, field.TypeInt32, field.TypeInt64, field.TypeDuration:
+		return strconv.AppendInt(dst, val.Integer, 10)
+	case field.TypeUint8, field.TypeUint16, field.TypeUint, field.TypeUint32, field.TypeUint64, field.TypeByte, field.TypeUintptr:
+		return strconv.AppendUint(dst, uint64(val.Integer), 10)
+	case field.TypeFloat32, field.TypeFloat64:
+		f := convert.Float64FromInt64(val.Integer)
+		bitSize := 64
+		if val.Type == field.TypeFloat32 {
+			f, bitSize = float64(convert.Float32FromInt64(val.Integer)), 32
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return encoder.AppendJSONString(dst, strconv.FormatFloat(f, 'g', -1, bitSize))
+		}
+		return strconv.AppendFloat(dst, f, 'g', -1, bitSize)
+	case field.TypeString, field.TypeError:
+		return encoder.AppendJSONString(dst, val.String)
+	default:
+		return encoder.AppendJSONString(dst, string(encoder.AppendValue(nil, val)))
+	}
+}
@@ -0,0 +1,381 @@
+/**
+ *@Title GELF日志记录适配器
+ *@Desc 通过UDP（支持分块及zlib、gzip压缩）或TCP（空字节分帧）将GELF日志发送到Graylog
+ */
+
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName GELF日志适配器默认名称
+const DefaultName = "belog-gelf-adapter"
+
+// Compression UDP压缩方式
+type Compression uint8
+
+const (
+	CompressionNone Compression = iota // 不压缩
+	CompressionGzip                    // gzip压缩
+	CompressionZlib                    // zlib压缩
+)
+
+// GELF分块协议常量
+const (
+	chunkHeaderSize = 12  // 分块头长度（魔数2字节+消息ID8字节+序号1字节+总数1字节）
+	maxChunkCount   = 128 // 最大分块数
+)
+
+// Options GELF日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// Default: belog-gelf-adapter
+	Name string
+
+	// 网络类型
+	//
+	// 可选值：udp、udp4、udp6、tcp、tcp4、tcp6
+	//
+	// Default: udp
+	Network string
+
+	// Graylog GELF输入地址（host:port）
+	Address string
+
+	// 主机名
+	//
+	// Default: os.Hostname()
+	Host string
+
+	// UDP压缩方式（TCP不支持压缩）
+	//
+	// Default: CompressionNone
+	Compression Compression
+
+	// UDP单个分块的最大长度（含分块头）
+	//
+	// 消息超过该长度时将分块发送，最多128个分块，超出后丢弃该条日志
+	//
+	// Unit: 字节, Default: 1420, Min: 128, Max: 8192
+	ChunkSize uint
+
+	// 是否忽略调用栈信息
+	//
+	// Default: false
+	DisabledStack bool
+
+	// 连接及写入超时时间
+	//
+	// Unit: 毫秒, Default: 3000, Min: 100, Max: 60000
+	Timeout uint
+
+	// 断开后的重连间隔
+	//
+	// 连接断开后将在下一次写入时重连，重连失败后在该间隔内的日志将被丢弃
+	//
+	// Unit: 毫秒, Default: 1000, Max: 3600000
+	ReconnectInterval uint
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 判断网络类型
+	switch p.Network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		if len(p.Network) > 0 {
+			printWarningMsg("gelf network `" + p.Network + "` is not supported, use the default value udp")
+		}
+		p.Network = "udp"
+	}
+	// 主机名
+	if len(p.Host) == 0 {
+		p.Host, _ = os.Hostname()
+	}
+	// 压缩方式
+	if p.Compression > CompressionZlib {
+		printWarningMsg("gelf compression error, use the default value none")
+		p.Compression = CompressionNone
+	}
+	if p.Compression != CompressionNone && strings.HasPrefix(p.Network, "tcp") {
+		printWarningMsg("gelf compression is not supported over tcp, disable compression")
+		p.Compression = CompressionNone
+	}
+	// 分块长度
+	if p.ChunkSize < 128 || p.ChunkSize > 8192 {
+		if p.ChunkSize != 0 {
+			printWarningMsg("gelf chunk size min value is 128,max value is 8192, use the default value 1420")
+		}
+		p.ChunkSize = 1420
+	}
+	// 超时时间
+	if p.Timeout < 100 || p.Timeout > 60000 {
+		if p.Timeout != 0 {
+			printWarningMsg("gelf timeout min value is 100(ms),max value is 60000(ms), use the default value 3000(ms)")
+		}
+		p.Timeout = 3000
+	}
+	// 重连间隔
+	if p.ReconnectInterval == 0 || p.ReconnectInterval > 3600000 {
+		if p.ReconnectInterval != 0 {
+			printWarningMsg("gelf reconnect interval max value is 3600000(ms), use the default value 1000(ms)")
+		}
+		p.ReconnectInterval = 1000
+	}
+}
+
+// Adapter GELF日志适配器
+type Adapter struct {
+	name              string        // 适配器名称
+	network           string        // 网络类型
+	address           string        // Graylog GELF输入地址
+	tcp               bool          // 是否为TCP连接
+	compression       Compression   // UDP压缩方式
+	chunkSize         int           // UDP单个分块的最大长度
+	timeout           time.Duration // 连接及写入超时时间
+	reconnectInterval time.Duration // 重连间隔
+	encoder           *gelfEncoder  // 日志编码器
+	mutex             sync.Mutex    // 连接锁
+	conn              net.Conn      // 当前连接（断开时为nil）
+	lastDial          time.Time     // 上一次连接失败的时间
+	messageID         uint64        // 下一个分块消息ID
+	buf               bytes.Buffer  // 压缩及帧缓冲区
+	chunk             []byte        // 分块缓冲区
+	gzipWriter        *gzip.Writer  // gzip压缩器
+	zlibWriter        *zlib.Writer  // zlib压缩器
+}
+
+// New 创建GELF日志适配器
+//
+//	注意：该适配器实现了logger.EncoderAdapter，日志内容由适配器自带的GELF编码器生成，
+//	记录器的编码器设置对其无效
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 地址不能为空
+	if len(strings.TrimSpace(options.Address)) == 0 {
+		return nil, errors.New("the `Address` of options is empty")
+	}
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化GELF日志适配器
+	e := &Adapter{
+		name:              options.Name,
+		network:           options.Network,
+		address:           options.Address,
+		tcp:               strings.HasPrefix(options.Network, "tcp"),
+		compression:       options.Compression,
+		chunkSize:         int(options.ChunkSize),
+		timeout:           time.Duration(options.Timeout) * time.Millisecond,
+		reconnectInterval: time.Duration(options.ReconnectInterval) * time.Millisecond,
+		encoder: &gelfEncoder{
+			host:      options.Host,
+			skipStack: options.DisabledStack,
+		},
+	}
+	// 分块消息ID以随机数开始，避免多个实例的消息ID冲突
+	var seed [8]byte
+	_, _ = rand.Read(seed[:])
+	e.messageID = binary.BigEndian.Uint64(seed[:])
+
+	// 建立连接
+	if err := e.connect(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Encoder 获取适配器自带的编码器
+func (e *Adapter) Encoder() logger.Encoder {
+	return e.encoder
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.write(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, _ string, _ int, _ string) {
+	e.write(content)
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	GELF日志适配器无缓冲区，每条日志都会立即发送
+func (e *Adapter) Flush() {}
+
+// Close 关闭GELF连接
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// 建立连接（需持有连接锁或尚未共享）
+func (e *Adapter) connect() error {
+	conn, err := net.DialTimeout(e.network, e.address, e.timeout)
+	if err != nil {
+		return err
+	}
+	e.conn = conn
+	return nil
+}
+
+// 压缩消息（UDP）
+func (e *Adapter) compress(content []byte) []byte {
+	e.buf.Reset()
+	switch e.compression {
+	case CompressionGzip:
+		if e.gzipWriter == nil {
+			e.gzipWriter = gzip.NewWriter(&e.buf)
+		} else {
+			e.gzipWriter.Reset(&e.buf)
+		}
+		_, _ = e.gzipWriter.Write(content)
+		_ = e.gzipWriter.Close()
+	case CompressionZlib:
+		if e.zlibWriter == nil {
+			e.zlibWriter = zlib.NewWriter(&e.buf)
+		} else {
+			e.zlibWriter.Reset(&e.buf)
+		}
+		_, _ = e.zlibWriter.Write(content)
+		_ = e.zlibWriter.Close()
+	default:
+		return content
+	}
+	return e.buf.Bytes()
+}
+
+// 发送一条UDP消息，超过分块长度时分块发送
+func (e *Adapter) writeUDP(content []byte) error {
+	payload := e.compress(content)
+	if len(payload) <= e.chunkSize {
+		_, err := e.conn.Write(payload)
+		return err
+	}
+
+	// 分块发送
+	dataSize := e.chunkSize - chunkHeaderSize
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > maxChunkCount {
+		printWarningMsg("belog gelf adapter message too large, " + strconv.Itoa(len(payload)) + " bytes dropped")
+		return nil
+	}
+	id := e.messageID
+	e.messageID++
+	for i := 0; i < count; i++ {
+		data := payload[i*dataSize:]
+		if len(data) > dataSize {
+			data = data[:dataSize]
+		}
+		e.chunk = append(e.chunk[:0], 0x1e, 0x0f)
+		e.chunk = append(e.chunk, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(e.chunk[2:10], id)
+		e.chunk = append(e.chunk, byte(i), byte(count))
+		e.chunk = append(e.chunk, data...)
+		if _, err := e.conn.Write(e.chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 发送一条TCP消息（以空字节结尾）
+func (e *Adapter) writeTCP(content []byte) error {
+	e.buf.Reset()
+	e.buf.Write(content)
+	e.buf.WriteByte(0)
+	_, err := e.conn.Write(e.buf.Bytes())
+	return err
+}
+
+// 发送日志，失败时重连并重试一次
+func (e *Adapter) write(content []byte) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for retry := 0; retry < 2; retry++ {
+		// 连接已断开时重连
+		if e.conn == nil {
+			if time.Since(e.lastDial) < e.reconnectInterval {
+				return
+			}
+			if err := e.connect(); err != nil {
+				e.lastDial = time.Now()
+				printWarningMsg("belog gelf adapter reconnect failed: " + err.Error())
+				return
+			}
+		}
+
+		// 发送日志
+		_ = e.conn.SetWriteDeadline(time.Now().Add(e.timeout))
+		var err error
+		if e.tcp {
+			err = e.writeTCP(content)
+		} else {
+			err = e.writeUDP(content)
+		}
+		if err == nil {
+			return
+		}
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
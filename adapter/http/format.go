@@ -0,0 +1,295 @@
+/**
+ *@Title HTTP日志请求格式
+ *@Desc 通用JSON数组、Elasticsearch批量接口及Loki推送接口的请求体格式化
+ */
+
+package http
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// Formatter 请求体格式化接口
+//
+//	单条日志由Encode或EncodeStack编码（调用栈文件名为空时不含调用栈），
+//	每批日志由Format组装为一个请求体
+type Formatter interface {
+	logger.Encoder
+
+	// Format 将一批日志组装为请求体
+	//
+	//	@param	dst		填充目标
+	//	@param	records	按记录顺序排列的单条日志编码结果
+	//	@return	填充后的内容
+	Format(dst []byte, records [][]byte) []byte
+
+	// ContentType 请求体类型
+	ContentType() string
+}
+
+// 追加JSON格式的日志对象
+//
+//	{"时间键": "...", "level": "...", "message": "...", ["caller": "...", "func": "...",] 字段...}
+func appendRecordJSON(dst []byte, timeKey string, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	dst = append(dst, '{')
+	dst = encoder.AppendJSONString(dst, timeKey)
+	dst = append(dst, ':', '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	dst = append(dst, `","level":`...)
+	dst = encoder.AppendJSONString(dst, l.String())
+	dst = append(dst, `,"message":`...)
+	dst = encoder.AppendJSONString(dst, msg)
+	if len(fn) > 0 {
+		dst = append(dst, `,"caller":`...)
+		// 去掉文件名结尾的双引号后追加行号
+		dst = encoder.AppendJSONString(dst, fn)
+		dst = append(dst[:len(dst)-1], ':')
+		dst = strconv.AppendInt(dst, int64(ln), 10)
+		dst = append(dst, `","func":`...)
+		dst = encoder.AppendJSONString(dst, mn)
+	}
+	for i := range val {
+		dst = append(dst, ',')
+		dst = encoder.AppendJSONString(dst, val[i].Key)
+		dst = append(dst, ':')
+		dst = appendJSONValue(dst, val[i])
+	}
+	return append(dst, '}')
+}
+
+// 追加JSON格式的字段值
+//
+//	字符串类型需要完整转义，其他类型与JSON编码器一致
+func appendJSONValue(dst []byte, val field.Field) []byte {
+	switch val.Type {
+	case field.TypeString, field.TypeError:
+		return encoder.AppendJSONString(dst, val.String)
+	default:
+		return encoder.AppendJSONValue(dst, val)
+	}
+}
+
+// JSONFormatter 通用JSON数组格式
+//
+//	请求体：[{"time": "...", "level": "...", "message": "...", 字段...}, ...]
+type JSONFormatter struct{}
+
+// Encode 编码输出方法
+func (f JSONFormatter) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return appendRecordJSON(dst, "time", t, l, "", 0, "", msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (f JSONFormatter) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	return appendRecordJSON(dst, "time", t, l, fn, ln, mn, msg, val...)
+}
+
+// Format 将一批日志组装为请求体
+func (f JSONFormatter) Format(dst []byte, records [][]byte) []byte {
+	dst = append(dst, '[')
+	for i, record := range records {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, record...)
+	}
+	return append(dst, ']')
+}
+
+// ContentType 请求体类型
+func (f JSONFormatter) ContentType() string {
+	return "application/json"
+}
+
+// ElasticsearchFormatter Elasticsearch批量接口（_bulk）格式
+//
+//	请求体为NDJSON，每条日志前追加一行create操作，时间字段为@timestamp，
+//	请求地址示例：http://127.0.0.1:9200/_bulk
+type ElasticsearchFormatter struct {
+	Index string // 索引或数据流名称
+}
+
+// Encode 编码输出方法
+func (f ElasticsearchFormatter) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return appendRecordJSON(dst, "@timestamp", t, l, "", 0, "", msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (f ElasticsearchFormatter) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	return appendRecordJSON(dst, "@timestamp", t, l, fn, ln, mn, msg, val...)
+}
+
+// Format 将一批日志组装为请求体
+func (f ElasticsearchFormatter) Format(dst []byte, records [][]byte) []byte {
+	for _, record := range records {
+		dst = append(dst, `{"create":{"_index":`...)
+		dst = encoder.AppendJSONString(dst, f.Index)
+		dst = append(dst, "}}\n"...)
+		dst = append(dst, record...)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+// ContentType 请求体类型
+func (f ElasticsearchFormatter) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// LokiFormatter Loki推送接口格式
+//
+//	相同标签的日志合并为一个流，日志行为JSON格式，
+//	请求地址示例：http://127.0.0.1:3100/loki/api/v1/push
+type LokiFormatter struct {
+	// 固定标签
+	Labels map[string]string
+
+	// 作为标签的字段键名
+	//
+	// 键名为level时使用日志级别；作为标签的字段不会写入日志行，
+	// 日志中不存在的字段不会生成标签
+	LabelFields []string
+}
+
+// Encode 编码输出方法
+func (f LokiFormatter) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return f.encode(dst, t, l, "", 0, "", msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (f LokiFormatter) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	return f.encode(dst, t, l, fn, ln, mn, msg, val...)
+}
+
+// 编码单条日志
+//
+//	格式：标签JSON对象\n纳秒时间戳\n日志行JSON字符串（均不含换行）
+func (f LokiFormatter) encode(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	// 固定标签（按键名排序，保证相同的标签组合编码一致）
+	keys := make([]string, 0, len(f.Labels))
+	for k := range f.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	dst = append(dst, '{')
+	n := 0
+	for _, k := range keys {
+		dst = appendLabel(dst, n, k, f.Labels[k])
+		n++
+	}
+
+	// 字段标签
+	var isLabel []bool
+	for _, key := range f.LabelFields {
+		if key == "level" {
+			dst = appendLabel(dst, n, key, l.String())
+			n++
+			continue
+		}
+		for i := range val {
+			if val[i].Key != key {
+				continue
+			}
+			if isLabel == nil {
+				isLabel = make([]bool, len(val))
+			}
+			isLabel[i] = true
+			dst = appendLabel(dst, n, key, string(encoder.AppendValue(nil, val[i])))
+			n++
+			break
+		}
+	}
+	dst = append(dst, '}', '\n')
+
+	// 时间戳
+	dst = strconv.AppendInt(dst, t.UnixNano(), 10)
+	dst = append(dst, '\n')
+
+	// 日志行（去除作为标签的字段）
+	lineFields := val
+	if isLabel != nil {
+		lineFields = make([]field.Field, 0, len(val))
+		for i := range val {
+			if !isLabel[i] {
+				lineFields = append(lineFields, val[i])
+			}
+		}
+	}
+	line := appendRecordJSON(nil, "time", t, l, fn, ln, mn, msg, lineFields...)
+	return encoder.AppendJSONString(dst, string(line))
+}
+
+// 追加标签
+//
+//	标签名仅允许字母、数字及下划线，且不能以数字开头，其他字符将被替换为下划线
+func appendLabel(dst []byte, n int, key string, value string) []byte {
+	if n > 0 {
+		dst = append(dst, ',')
+	}
+	dst = append(dst, '"')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	dst = append(dst, '"')
+	dst = append(dst, ':')
+	return encoder.AppendJSONString(dst, value)
+}
+
+// Format 将一批日志组装为请求体
+//
+//	{"streams": [{"stream": {标签}, "values": [["纳秒时间戳", "日志行"], ...]}, ...]}
+func (f LokiFormatter) Format(dst []byte, records [][]byte) []byte {
+	// 按标签分组，保持首次出现的顺序
+	var order [][]byte
+	groups := make(map[string][][]byte)
+	for _, record := range records {
+		i := bytes.IndexByte(record, '\n')
+		if i < 0 {
+			continue
+		}
+		labels := record[:i]
+		if _, ok := groups[string(labels)]; !ok {
+			order = append(order, labels)
+		}
+		groups[string(labels)] = append(groups[string(labels)], record[i+1:])
+	}
+
+	dst = append(dst, `{"streams":[`...)
+	for i, labels := range order {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"stream":`...)
+		dst = append(dst, labels...)
+		dst = append(dst, `,"values":[`...)
+		for j, value := range groups[string(labels)] {
+			if j > 0 {
+				dst = append(dst, ',')
+			}
+			k := bytes.IndexByte(value, '\n')
+			dst = append(dst, `["`...)
+			dst = append(dst, value[:k]...)
+			dst = append(dst, `",`...)
+			dst = append(dst, value[k+1:]...)
+			dst = append(dst, ']')
+		}
+		dst = append(dst, "]}"...)
+	}
+	return append(dst, "]}"...)
+}
+
+// ContentType 请求体类型
+func (f LokiFormatter) ContentType() string {
+	return "application/json"
+}
@@ -0,0 +1,479 @@
+/**
+ *@Title HTTP日志记录适配器
+ *@Desc 按条数、容量及时间间隔批量打包日志，gzip压缩后推送到HTTP接口（Loki、Elasticsearch或通用JSON）
+ */
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	stdhttp "net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName HTTP日志适配器默认名称
+const DefaultName = "belog-http-adapter"
+
+// Options HTTP日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// Default: belog-http-adapter
+	Name string
+
+	// 推送地址
+	URL string
+
+	// 请求体格式
+	//
+	// 可选：JSONFormatter、ElasticsearchFormatter、LokiFormatter或自定义实现
+	//
+	// Default: JSONFormatter{}
+	Formatter Formatter
+
+	// 附加请求头（如Authorization、X-Scope-OrgID）
+	Headers map[string]string
+
+	// HTTP客户端
+	//
+	// Default: 超时时间为Timeout的客户端
+	Client *stdhttp.Client
+
+	// 禁用gzip压缩
+	//
+	// Default: false
+	DisabledGzip bool
+
+	// 单批最大日志条数
+	//
+	// Default: 1000, Min: 1, Max: 100000
+	BatchCount uint
+
+	// 单批最大日志容量（压缩前）
+	//
+	// Unit: KB, Default: 1024, Min: 1, Max: 102400
+	BatchSize uint
+
+	// 批量推送间隔
+	//
+	// 未满一批的日志在该间隔后推送
+	//
+	// Unit: 毫秒, Default: 1000, Min: 10, Max: 3600000
+	BatchInterval uint
+
+	// 待推送日志队列容量
+	//
+	// 队列已满时将丢弃新的日志
+	//
+	// Default: 10000, Min: 1, Max: 10000000
+	QueueSize uint
+
+	// 请求超时时间
+	//
+	// Unit: 毫秒, Default: 10000, Min: 100, Max: 600000
+	Timeout uint
+
+	// 最大重试次数
+	//
+	// 响应状态码为429或5xx以及网络异常时按指数退避重试，超过次数后丢弃该批日志
+	//
+	// Default: 5, Max: 100
+	MaxRetries uint
+
+	// 最小重试间隔
+	//
+	// 响应包含Retry-After时优先使用该值
+	//
+	// Unit: 毫秒, Default: 500, Min: 10, Max: 60000
+	RetryMinInterval uint
+
+	// 最大重试间隔
+	//
+	// Unit: 毫秒, Default: 30000, Min: RetryMinInterval, Max: 3600000
+	RetryMaxInterval uint
+
+	// Flush等待推送完成的超时时间
+	//
+	// Unit: 毫秒, Default: 10000, Max: 3600000
+	FlushTimeout uint
+}
+
+// Stats HTTP日志适配器统计信息
+type Stats struct {
+	Sent    uint64 // 累计推送成功的日志条数
+	Dropped uint64 // 累计丢弃的日志条数（队列已满或重试失败）
+	Retries uint64 // 累计重试次数
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 将参数限制在指定范围内，超出范围时使用默认值
+func limit(name string, unit string, v *uint, def uint, min uint, max uint) {
+	if *v >= min && *v <= max {
+		return
+	}
+	if *v != 0 {
+		printWarningMsg("http " + name + " min value is " + strconv.FormatUint(uint64(min), 10) + unit +
+			",max value is " + strconv.FormatUint(uint64(max), 10) + unit +
+			", use the default value " + strconv.FormatUint(uint64(def), 10) + unit)
+	}
+	*v = def
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 请求体格式
+	if p.Formatter == nil {
+		p.Formatter = JSONFormatter{}
+	}
+	limit("batch count", "", &p.BatchCount, 1000, 1, 100000)
+	limit("batch size", "(KB)", &p.BatchSize, 1024, 1, 102400)
+	limit("batch interval", "(ms)", &p.BatchInterval, 1000, 10, 3600000)
+	limit("queue size", "", &p.QueueSize, 10000, 1, 10000000)
+	limit("timeout", "(ms)", &p.Timeout, 10000, 100, 600000)
+	if p.MaxRetries > 100 {
+		printWarningMsg("http max retries max value is 100, use the default value 5")
+		p.MaxRetries = 5
+	} else if p.MaxRetries == 0 {
+		p.MaxRetries = 5
+	}
+	limit("retry min interval", "(ms)", &p.RetryMinInterval, 500, 10, 60000)
+	limit("retry max interval", "(ms)", &p.RetryMaxInterval, 30000, p.RetryMinInterval, 3600000)
+	if p.RetryMaxInterval < p.RetryMinInterval {
+		p.RetryMaxInterval = p.RetryMinInterval
+	}
+	limit("flush timeout", "(ms)", &p.FlushTimeout, 10000, 1, 3600000)
+}
+
+// Adapter HTTP日志适配器
+type Adapter struct {
+	sent    uint64 // 累计推送成功的日志条数（原子操作，需保持64位对齐）
+	dropped uint64 // 累计丢弃的日志条数（原子操作，需保持64位对齐）
+	retries uint64 // 累计重试次数（原子操作，需保持64位对齐）
+
+	name          string             // 适配器名称
+	url           string             // 推送地址
+	formatter     Formatter          // 请求体格式
+	headers       map[string]string  // 附加请求头
+	client        *stdhttp.Client    // HTTP客户端
+	gzip          bool               // 是否启用gzip压缩
+	batchCount    int                // 单批最大日志条数
+	batchSize     int                // 单批最大日志容量
+	batchInterval time.Duration      // 批量推送间隔
+	maxRetries    int                // 最大重试次数
+	retryMin      time.Duration      // 最小重试间隔
+	retryMax      time.Duration      // 最大重试间隔
+	flushTimeout  time.Duration      // Flush等待推送完成的超时时间
+	queue         chan []byte        // 待推送日志队列
+	flushReq      chan chan struct{} // 刷新请求
+	stop          chan struct{}      // 停止通知
+	done          chan struct{}      // 推送协程退出通知
+	closed        int32              // 是否已关闭（原子操作）
+	gzipWriter    *gzip.Writer       // gzip压缩器（仅推送协程使用）
+	body          bytes.Buffer       // 请求体缓冲区（仅推送协程使用）
+	formatBuf     []byte             // 格式化缓冲区（仅推送协程使用）
+}
+
+// New 创建HTTP日志适配器
+//
+//	注意：该适配器实现了logger.EncoderAdapter，日志内容由Formatter编码，
+//	记录器的编码器设置对其无效
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 推送地址不能为空
+	if len(strings.TrimSpace(options.URL)) == 0 {
+		return nil, errors.New("the `URL` of options is empty")
+	}
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化HTTP日志适配器
+	e := &Adapter{
+		name:          options.Name,
+		url:           options.URL,
+		formatter:     options.Formatter,
+		headers:       options.Headers,
+		client:        options.Client,
+		gzip:          !options.DisabledGzip,
+		batchCount:    int(options.BatchCount),
+		batchSize:     int(options.BatchSize) * 1024,
+		batchInterval: time.Duration(options.BatchInterval) * time.Millisecond,
+		maxRetries:    int(options.MaxRetries),
+		retryMin:      time.Duration(options.RetryMinInterval) * time.Millisecond,
+		retryMax:      time.Duration(options.RetryMaxInterval) * time.Millisecond,
+		flushTimeout:  time.Duration(options.FlushTimeout) * time.Millisecond,
+		queue:         make(chan []byte, options.QueueSize),
+		flushReq:      make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if e.client == nil {
+		e.client = &stdhttp.Client{Timeout: time.Duration(options.Timeout) * time.Millisecond}
+	}
+	if e.gzip {
+		e.gzipWriter = gzip.NewWriter(ioutil.Discard)
+	}
+
+	// 启动推送协程
+	go e.run()
+	return e, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Encoder 获取适配器自带的编码器
+func (e *Adapter) Encoder() logger.Encoder {
+	return e.formatter
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.enqueue(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, _ string, _ int, _ string) {
+	e.enqueue(content)
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	立即推送队列中的日志并等待完成，超过FlushTimeout后返回
+func (e *Adapter) Flush() {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		return
+	}
+	done := make(chan struct{})
+	timer := time.NewTimer(e.flushTimeout)
+	defer timer.Stop()
+	select {
+	case e.flushReq <- done:
+	case <-e.done:
+		return
+	case <-timer.C:
+		printWarningMsg("belog http adapter flush timeout, logs are still pending")
+		return
+	}
+	select {
+	case <-done:
+	case <-timer.C:
+		printWarningMsg("belog http adapter flush timeout, logs are still pending")
+	}
+}
+
+// Close 关闭适配器
+//
+//	推送队列中剩余的日志后停止，此后写入的日志将被丢弃
+func (e *Adapter) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.closed, 0, 1) {
+		return nil
+	}
+	close(e.stop)
+	<-e.done
+	return nil
+}
+
+// Stats 获取适配器统计信息
+func (e *Adapter) Stats() Stats {
+	return Stats{
+		Sent:    atomic.LoadUint64(&e.sent),
+		Dropped: atomic.LoadUint64(&e.dropped),
+		Retries: atomic.LoadUint64(&e.retries),
+	}
+}
+
+// 日志加入推送队列
+func (e *Adapter) enqueue(content []byte) {
+	if atomic.LoadInt32(&e.closed) == 1 {
+		atomic.AddUint64(&e.dropped, 1)
+		return
+	}
+	// 日志内容由记录器复用，需要复制
+	select {
+	case e.queue <- append([]byte(nil), content...):
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// 推送协程
+func (e *Adapter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.batchInterval)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	size := 0
+	add := func(record []byte) {
+		batch = append(batch, record)
+		size += len(record)
+		if len(batch) >= e.batchCount || size >= e.batchSize {
+			e.post(batch)
+			batch, size = batch[:0], 0
+		}
+	}
+	// 推送队列中已有的全部日志
+	drain := func() {
+		for {
+			select {
+			case record := <-e.queue:
+				add(record)
+			default:
+				if len(batch) > 0 {
+					e.post(batch)
+					batch, size = batch[:0], 0
+				}
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-e.queue:
+			add(record)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				e.post(batch)
+				batch, size = batch[:0], 0
+			}
+		case done := <-e.flushReq:
+			drain()
+			close(done)
+		case <-e.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// 推送一批日志，失败时按指数退避重试
+func (e *Adapter) post(batch [][]byte) {
+	// 组装请求体
+	e.formatBuf = e.formatter.Format(e.formatBuf[:0], batch)
+	e.body.Reset()
+	if e.gzip {
+		e.gzipWriter.Reset(&e.body)
+		_, _ = e.gzipWriter.Write(e.formatBuf)
+		_ = e.gzipWriter.Close()
+	} else {
+		e.body.Write(e.formatBuf)
+	}
+
+	backoff := e.retryMin
+	for attempt := 0; ; attempt++ {
+		retry, wait, err := e.do(e.body.Bytes())
+		if err == nil {
+			atomic.AddUint64(&e.sent, uint64(len(batch)))
+			return
+		}
+		if !retry || attempt >= e.maxRetries {
+			atomic.AddUint64(&e.dropped, uint64(len(batch)))
+			printWarningMsg("belog http adapter post failed, " + strconv.Itoa(len(batch)) + " logs dropped: " + err.Error())
+			return
+		}
+		atomic.AddUint64(&e.retries, 1)
+
+		// 等待重试
+		if wait <= 0 {
+			wait = backoff
+			if backoff *= 2; backoff > e.retryMax {
+				backoff = e.retryMax
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-e.stop:
+			// 关闭时仍然重试，但不再等待
+			timer.Stop()
+		}
+	}
+}
+
+// 发送一次请求
+//
+//	@param	body	请求体
+//	@return	是否需要重试
+//	@return	服务端要求的重试等待时间（Retry-After）
+//	@return	异常信息
+func (e *Adapter) do(body []byte) (bool, time.Duration, error) {
+	req, err := stdhttp.NewRequest(stdhttp.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", e.formatter.ContentType())
+	if e.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		// Elasticsearch批量接口部分失败时仍返回200
+		if bytes.Contains(respBody, []byte(`"errors":true`)) {
+			printWarningMsg("belog http adapter: some logs were rejected by the server")
+		}
+		return false, 0, nil
+	case resp.StatusCode == stdhttp.StatusTooManyRequests || resp.StatusCode >= 500:
+		var wait time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			wait = time.Duration(s) * time.Second
+			if wait > e.retryMax {
+				wait = e.retryMax
+			}
+		}
+		return true, wait, errors.New(resp.Status + ": " + string(respBody))
+	default:
+		return false, 0, errors.New(resp.Status + ": " + string(respBody))
+	}
+}
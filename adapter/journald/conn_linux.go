@@ -0,0 +1,121 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// 各架构memfd_create系统调用号（标准库syscall包未覆盖全部架构）
+var memfdCreateTrap = map[string]uintptr{
+	"386":      356,
+	"amd64":    319,
+	"arm":      385,
+	"arm64":    279,
+	"loong64":  279,
+	"mips":     4354,
+	"mipsle":   4354,
+	"mips64":   5314,
+	"mips64le": 5314,
+	"ppc64":    360,
+	"ppc64le":  360,
+	"riscv64":  279,
+	"s390x":    350,
+}
+
+// memfd相关常量
+const (
+	mfdCloexec       = 0x0001 // MFD_CLOEXEC
+	mfdAllowSealing  = 0x0002 // MFD_ALLOW_SEALING
+	fcntlAddSeals    = 1033   // F_ADD_SEALS
+	sealAll          = 0x000f // F_SEAL_SEAL|F_SEAL_SHRINK|F_SEAL_GROW|F_SEAL_WRITE
+	sharedMemoryPath = "/dev/shm"
+)
+
+// journald连接
+//
+//	使用未连接的数据报套接字，每次发送时指定journald地址，
+//	journald重启后无需重连
+type journalConn struct {
+	conn *net.UnixConn // 数据报套接字
+	addr *net.UnixAddr // journald地址
+}
+
+// 连接journald
+func dialJournal(path string) (*journalConn, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journalConn{conn: conn, addr: &net.UnixAddr{Name: path, Net: "unixgram"}}, nil
+}
+
+// 发送一条日志
+//
+//	日志超过数据报长度上限时写入memfd（不支持时使用/dev/shm下的临时文件），
+//	通过SCM_RIGHTS传递文件描述符
+func (c *journalConn) send(content []byte) error {
+	_, _, err := c.conn.WriteMsgUnix(content, nil, c.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	file, err := tempFile(content)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, _, err = c.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), c.addr)
+	return err
+}
+
+// 关闭连接
+func (c *journalConn) close() error {
+	return c.conn.Close()
+}
+
+// 创建保存日志内容的临时文件
+//
+//	优先使用密封的memfd，journald要求memfd必须密封
+func tempFile(content []byte) (*os.File, error) {
+	if trap, ok := memfdCreateTrap[runtime.GOARCH]; ok {
+		name := []byte("belog-journald\x00")
+		fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(&name[0])), mfdCloexec|mfdAllowSealing, 0)
+		if errno == 0 {
+			file := os.NewFile(fd, "memfd:belog-journald")
+			if _, err := file.Write(content); err != nil {
+				file.Close()
+				return nil, err
+			}
+			if _, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, fcntlAddSeals, sealAll); errno != 0 {
+				file.Close()
+				return nil, errno
+			}
+			return file, nil
+		}
+	}
+
+	// 内核不支持memfd时使用已删除的临时文件
+	file, err := os.CreateTemp(sharedMemoryPath, "belog-journald-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(file.Name())
+	if _, err = file.Write(content); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
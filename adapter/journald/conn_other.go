@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package journald
+
+import "errors"
+
+// journald连接（非Linux系统不支持）
+type journalConn struct{}
+
+// 连接journald
+func dialJournal(string) (*journalConn, error) {
+	return nil, errors.New("belog/journald: journald is only supported on linux")
+}
+
+// 发送一条日志
+func (c *journalConn) send([]byte) error {
+	return nil
+}
+
+// 关闭连接
+func (c *journalConn) close() error {
+	return nil
+}
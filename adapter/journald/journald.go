@@ -0,0 +1,298 @@
+/**
+ *@Title journald日志记录适配器
+ *@Desc 通过systemd-journald原生协议写入带结构化字段的日志（仅支持Linux）
+ */
+
+package journald
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName journald日志适配器默认名称
+const DefaultName = "belog-journald-adapter"
+
+// DefaultSocketPath journald原生协议套接字路径
+const DefaultSocketPath = "/run/systemd/journal/socket"
+
+// 字段名最大长度
+const maxFieldNameSize = 64
+
+// 适配器自行写入的字段名（同名的日志字段需要添加"F_"前缀）
+var reservedFieldNames = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+// Options journald日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// Default: belog-journald-adapter
+	Name string
+
+	// journald套接字路径
+	//
+	// Default: /run/systemd/journal/socket
+	SocketPath string
+
+	// 日志标识（SYSLOG_IDENTIFIER）
+	//
+	// Default: 当前可执行文件名
+	Identifier string
+
+	// 是否忽略调用栈信息（CODE_FILE、CODE_LINE、CODE_FUNC）
+	//
+	// Default: false
+	DisabledStack bool
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 套接字路径
+	if len(p.SocketPath) == 0 {
+		p.SocketPath = DefaultSocketPath
+	}
+	// 日志标识
+	if len(p.Identifier) == 0 {
+		p.Identifier = filepath.Base(os.Args[0])
+	}
+}
+
+// Priority 获取日志级别对应的journald优先级（syslog严重性）
+//
+//	Trace、Debug对应debug(7)，Info对应info(6)，Warn对应warning(4)，
+//	Error对应err(3)，Fatal对应crit(2)
+//
+//	@param	l	日志级别
+//	@return	journald优先级
+func Priority(l logger.Level) uint8 {
+	switch l {
+	case logger.Trace, logger.Debug:
+		return 7
+	case logger.Info:
+		return 6
+	case logger.Warn:
+		return 4
+	case logger.Error:
+		return 3
+	case logger.Fatal:
+		return 2
+	default:
+		return 6
+	}
+}
+
+// journald原生协议编码器
+//
+//	每个字段为一行"KEY=value"，值包含换行时使用"KEY\n"+8字节小端序长度+值+"\n"，
+//	调用栈字段由适配器在PrintStack中追加，日志时间使用journald接收时间
+type journaldEncoder struct {
+	identifier string // 日志标识
+}
+
+// Encode 编码输出方法
+func (e *journaldEncoder) Encode(dst []byte, _ time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	dst = appendVariable(dst, "MESSAGE", msg)
+	dst = append(dst, "PRIORITY="...)
+	dst = strconv.AppendUint(dst, uint64(Priority(l)), 10)
+	dst = append(dst, '\n')
+	dst = appendVariable(dst, "SYSLOG_IDENTIFIER", e.identifier)
+	var name []byte
+	for i := range val {
+		name = appendFieldName(name[:0], val[i].Key)
+		if val[i].Type == field.TypeString || val[i].Type == field.TypeError {
+			dst = appendVariable(dst, string(name), val[i].String)
+		} else {
+			dst = appendVariable(dst, string(name), string(encoder.AppendValue(nil, val[i])))
+		}
+	}
+	return dst
+}
+
+// EncodeStack 含调用栈编码输出方法
+//
+//	调用栈字段由适配器追加
+func (e *journaldEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, _ string, _ int, _ string, msg string, val ...field.Field) []byte {
+	return e.Encode(dst, t, l, msg, val...)
+}
+
+// 追加字段
+func appendVariable(dst []byte, name string, value string) []byte {
+	dst = append(dst, name...)
+	if strings.IndexByte(value, '\n') < 0 {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+		return append(dst, '\n')
+	}
+	// 多行值使用二进制格式
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	dst = append(dst, '\n')
+	dst = append(dst, size[:]...)
+	dst = append(dst, value...)
+	return append(dst, '\n')
+}
+
+// 追加字段名
+//
+//	字段名转换为大写，仅允许大写字母、数字及下划线，其他字符将被替换为下划线；
+//	下划线开头的字段为journald保留字段，开头的下划线将被去除，
+//	去除后为空、以数字开头或与适配器写入的字段（如MESSAGE、CODE_FILE）同名时添加"F_"前缀，
+//	超过64个字符时截断
+func appendFieldName(dst []byte, key string) []byte {
+	key = strings.TrimLeft(key, "_")
+	start := len(dst)
+	if len(key) == 0 || '0' <= key[0] && key[0] <= '9' {
+		dst = append(dst, 'F', '_')
+	}
+	for i := 0; i < len(key) && len(dst) < maxFieldNameSize; i++ {
+		c := key[i]
+		switch {
+		case 'a' <= c && c <= 'z':
+			c -= 'a' - 'A'
+		case 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_':
+		default:
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	// 避免覆盖适配器写入的字段
+	if reservedFieldNames[string(dst[start:])] {
+		dst = append(dst[:start], append([]byte("F_"), dst[start:]...)...)
+	}
+	return dst
+}
+
+// Adapter journald日志适配器
+type Adapter struct {
+	name       string           // 适配器名称
+	socketPath string           // journald套接字路径
+	skipStack  bool             // 是否忽略调用栈信息
+	encoder    *journaldEncoder // 日志编码器
+	mutex      sync.Mutex       // 发送锁
+	conn       *journalConn     // journald连接
+	buf        []byte           // 调用栈日志缓冲区
+}
+
+// New 创建journald日志适配器
+//
+//	注意：该适配器实现了logger.EncoderAdapter，日志内容由适配器自带的编码器生成，
+//	记录器的编码器设置对其无效；非Linux系统将返回异常
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 判断参数有效性
+	options.validity()
+
+	// 连接journald
+	conn, err := dialJournal(options.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 实例化journald日志适配器
+	return &Adapter{
+		name:       options.Name,
+		socketPath: options.SocketPath,
+		skipStack:  options.DisabledStack,
+		encoder:    &journaldEncoder{identifier: options.Identifier},
+		conn:       conn,
+	}, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Encoder 获取适配器自带的编码器
+func (e *Adapter) Encoder() logger.Encoder {
+	return e.encoder
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.send(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, fileName string, lineNo int, methodName string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.skipStack {
+		e.send(content)
+		return
+	}
+	e.buf = append(e.buf[:0], content...)
+	e.buf = appendVariable(e.buf, "CODE_FILE", fileName)
+	e.buf = append(e.buf, "CODE_LINE="...)
+	e.buf = strconv.AppendInt(e.buf, int64(lineNo), 10)
+	e.buf = append(e.buf, '\n')
+	e.buf = appendVariable(e.buf, "CODE_FUNC", methodName)
+	e.send(e.buf)
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	journald日志适配器无缓冲区，每条日志都会立即发送
+func (e *Adapter) Flush() {}
+
+// Close 关闭journald连接
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.conn.close()
+}
+
+// 发送日志（需持有发送锁）
+func (e *Adapter) send(content []byte) {
+	if err := e.conn.send(content); err != nil {
+		printWarningMsg("belog journald adapter send failed: " + err.Error())
+	}
+}
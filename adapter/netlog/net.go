@@ -0,0 +1,601 @@
+/**
+ *@Title 网络日志记录适配器
+ *@Desc 通过TCP（可选TLS）或UDP将日志发送到远程收集端，断开时缓存到内存及磁盘队列，重连后按顺序重放
+ */
+
+package netlog
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName 网络日志适配器默认名称
+const DefaultName = "belog-net-adapter"
+
+// UDP单个数据报的最大长度
+const maxDatagramSize = 65507
+
+// 单次发送的最大长度
+const maxBatchSize = 64 * 1024
+
+// Options 网络日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// 同时作为磁盘队列文件名，同一目录下的多个适配器需要使用不同的名称
+	//
+	// Default: belog-net-adapter
+	Name string
+
+	// 网络类型
+	//
+	// 可选值：tcp、tcp4、tcp6、udp、udp4、udp6
+	//
+	// Default: tcp
+	Network string
+
+	// 收集端地址（host:port）
+	Address string
+
+	// TLS配置（仅TCP）
+	//
+	// 不为空时使用TLS连接
+	TLSConfig *tls.Config
+
+	// 内存缓冲区容量
+	//
+	// 连接断开或发送速度跟不上时日志将缓存在内存中
+	//
+	// Unit: KB, Default: 1024, Min: 1, Max: 1048576
+	BufferSize uint
+
+	// 磁盘队列目录
+	//
+	// 内存缓冲区已满时日志将追加到磁盘队列，为空时将丢弃新的日志；
+	// 关闭适配器时未发送的日志也将写入磁盘队列，下次启动后继续发送
+	SpoolDir string
+
+	// 磁盘队列容量
+	//
+	// 磁盘队列已满时将丢弃新的日志
+	//
+	// Unit: MB, Default: 100, Min: 1, Max: 102400
+	SpoolMaxSize uint
+
+	// 连接及写入超时时间
+	//
+	// Unit: 毫秒, Default: 5000, Min: 100, Max: 60000
+	Timeout uint
+
+	// 最小重连间隔
+	//
+	// 连接失败后重连间隔从该值开始按指数增长
+	//
+	// Unit: 毫秒, Default: 500, Min: 10, Max: 60000
+	ReconnectMinInterval uint
+
+	// 最大重连间隔
+	//
+	// Unit: 毫秒, Default: 30000, Min: ReconnectMinInterval, Max: 3600000
+	ReconnectMaxInterval uint
+
+	// Flush等待日志写入套接字的超时时间
+	//
+	// Unit: 毫秒, Default: 5000, Max: 3600000
+	FlushTimeout uint
+}
+
+// Stats 网络日志适配器统计信息
+type Stats struct {
+	Sent    uint64 // 累计发送的日志条数（写入连接成功即计入，不代表收集端已收到）
+	Dropped uint64 // 累计丢弃的日志条数
+	Spilled uint64 // 累计写入磁盘队列的日志条数
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 判断网络类型
+	switch p.Network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+	case "":
+		p.Network = "tcp"
+	default:
+		printWarningMsg("net network `" + p.Network + "` is not supported, use the default value tcp")
+		p.Network = "tcp"
+	}
+	// 判断TLS配置
+	if p.TLSConfig != nil && strings.HasPrefix(p.Network, "udp") {
+		p.TLSConfig = nil
+		printWarningMsg("net TLS is only supported over tcp, TLS config ignored")
+	}
+	// 判断内存缓冲区容量
+	if p.BufferSize < 1 || p.BufferSize > 1048576 {
+		if p.BufferSize != 0 {
+			printWarningMsg("net buffer size min value is 1(KB),max value is 1048576(KB), use the default value 1024(KB)")
+		}
+		p.BufferSize = 1024
+	}
+	// 判断磁盘队列容量
+	if len(p.SpoolDir) > 0 && (p.SpoolMaxSize < 1 || p.SpoolMaxSize > 102400) {
+		if p.SpoolMaxSize != 0 {
+			printWarningMsg("net spool max size min value is 1(MB),max value is 102400(MB), use the default value 100(MB)")
+		}
+		p.SpoolMaxSize = 100
+	}
+	// 判断超时时间
+	if p.Timeout < 100 || p.Timeout > 60000 {
+		if p.Timeout != 0 {
+			printWarningMsg("net timeout min value is 100(ms),max value is 60000(ms), use the default value 5000(ms)")
+		}
+		p.Timeout = 5000
+	}
+	// 判断重连间隔
+	if p.ReconnectMinInterval < 10 || p.ReconnectMinInterval > 60000 {
+		if p.ReconnectMinInterval != 0 {
+			printWarningMsg("net reconnect min interval min value is 10(ms),max value is 60000(ms), use the default value 500(ms)")
+		}
+		p.ReconnectMinInterval = 500
+	}
+	if p.ReconnectMaxInterval < p.ReconnectMinInterval || p.ReconnectMaxInterval > 3600000 {
+		if p.ReconnectMaxInterval != 0 {
+			printWarningMsg("net reconnect max interval must be between the min interval and 3600000(ms), use the default value 30000(ms)")
+		}
+		p.ReconnectMaxInterval = 30000
+		if p.ReconnectMaxInterval < p.ReconnectMinInterval {
+			p.ReconnectMaxInterval = p.ReconnectMinInterval
+		}
+	}
+	// 判断Flush超时时间
+	if p.FlushTimeout == 0 || p.FlushTimeout > 3600000 {
+		if p.FlushTimeout != 0 {
+			printWarningMsg("net flush timeout max value is 3600000(ms), use the default value 5000(ms)")
+		}
+		p.FlushTimeout = 5000
+	}
+}
+
+// Adapter 网络日志适配器
+//
+//	投递语义为至少一次：日志写入连接成功即视为已发送（TCP下只表示已交给操作系统），
+//	连接断开时已写入但收集端未收到的日志将丢失；
+//	一批日志写入失败时整批将在重连后重新发送，其中已被收集端收到的日志会重复
+type Adapter struct {
+	sent    uint64 // 累计发送的日志条数（原子操作，需保持64位对齐）
+	dropped uint64 // 累计丢弃的日志条数（原子操作，需保持64位对齐）
+	spilled uint64 // 累计写入磁盘队列的日志条数（原子操作，需保持64位对齐）
+
+	name         string        // 适配器名称
+	network      string        // 网络类型
+	address      string        // 收集端地址
+	tlsConfig    *tls.Config   // TLS配置
+	datagram     bool          // 是否为UDP
+	timeout      time.Duration // 连接及写入超时时间
+	reconnectMin time.Duration // 最小重连间隔
+	reconnectMax time.Duration // 最大重连间隔
+	flushTimeout time.Duration // Flush等待日志写入套接字的超时时间
+
+	mutex     sync.Mutex    // 队列锁
+	memory    [][]byte      // 内存缓冲区中的日志（最旧的在前）
+	memBytes  int           // 内存缓冲区已用容量
+	memMax    int           // 内存缓冲区容量上限
+	spool     *spool        // 磁盘队列（未配置时为nil）
+	inflight  bool          // 是否有正在发送的日志
+	drained   chan struct{} // 发送完成通知（Flush等待时创建，全部日志写入套接字后关闭）
+	closed    bool          // 是否已关闭
+	notify    chan struct{} // 新日志通知
+	stop      chan struct{} // 停止发送通知
+	done      chan struct{} // 发送协程退出通知
+	closeOnce sync.Once     // 关闭锁
+}
+
+// New 创建网络日志适配器
+//
+//	连接在后台建立，创建时收集端不可用不会返回异常
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 收集端地址不能为空
+	if len(strings.TrimSpace(options.Address)) == 0 {
+		return nil, errors.New("the `Address` of options is empty")
+	}
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化网络日志适配器
+	e := &Adapter{
+		name:         options.Name,
+		network:      options.Network,
+		address:      options.Address,
+		tlsConfig:    options.TLSConfig,
+		datagram:     strings.HasPrefix(options.Network, "udp"),
+		timeout:      time.Duration(options.Timeout) * time.Millisecond,
+		reconnectMin: time.Duration(options.ReconnectMinInterval) * time.Millisecond,
+		reconnectMax: time.Duration(options.ReconnectMaxInterval) * time.Millisecond,
+		flushTimeout: time.Duration(options.FlushTimeout) * time.Millisecond,
+		memMax:       int(options.BufferSize) * 1024,
+		notify:       make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	// 打开磁盘队列
+	if len(options.SpoolDir) > 0 {
+		s, err := openSpool(options.SpoolDir, options.Name, int64(options.SpoolMaxSize)*1024*1024)
+		if err != nil {
+			return nil, err
+		}
+		e.spool = s
+	}
+
+	// 启动发送协程
+	go e.run()
+	return e, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.enqueue(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, _ string, _ int, _ string) {
+	e.enqueue(content)
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	等待内存缓冲区及磁盘队列中的日志全部写入套接字，超过FlushTimeout后返回；
+//	写入套接字仅表示日志已交给操作系统发送，不保证收集端已收到或已处理
+func (e *Adapter) Flush() {
+	e.mutex.Lock()
+	if !e.pendingLocked() {
+		e.mutex.Unlock()
+		return
+	}
+	if e.drained == nil {
+		e.drained = make(chan struct{})
+	}
+	drained := e.drained
+	e.mutex.Unlock()
+
+	// 等待发送协程通知全部日志已写入套接字
+	timer := time.NewTimer(e.flushTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-e.done:
+	case <-timer.C:
+		printWarningMsg("belog net adapter flush timeout, logs are still pending")
+	}
+}
+
+// Close 关闭适配器
+//
+//	等待未发送的日志写入套接字（最长FlushTimeout），
+//	仍未发送的日志将写入磁盘队列（已配置时），此后写入的日志将被丢弃
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		e.Flush()
+
+		// 停止发送协程
+		e.mutex.Lock()
+		e.closed = true
+		e.mutex.Unlock()
+		close(e.stop)
+		<-e.done
+
+		// 未发送的日志写入磁盘队列
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+		if e.spool == nil {
+			atomic.AddUint64(&e.dropped, uint64(len(e.memory)))
+			e.memory = nil
+			return
+		}
+		// 内存缓冲区中的日志早于磁盘队列中的日志，插入到队列头部
+		if len(e.memory) > 0 {
+			n, perr := e.spool.prepend(e.memory)
+			if perr != nil {
+				printWarningMsg("belog net adapter spool write failed: " + perr.Error())
+			}
+			atomic.AddUint64(&e.spilled, uint64(n))
+			atomic.AddUint64(&e.dropped, uint64(len(e.memory)-n))
+		}
+		e.memory = nil
+		err = e.spool.close()
+	})
+	return err
+}
+
+// Stats 获取适配器统计信息
+func (e *Adapter) Stats() Stats {
+	return Stats{
+		Sent:    atomic.LoadUint64(&e.sent),
+		Dropped: atomic.LoadUint64(&e.dropped),
+		Spilled: atomic.LoadUint64(&e.spilled),
+	}
+}
+
+// 是否有未发送的日志（需持有队列锁）
+func (e *Adapter) pendingLocked() bool {
+	return e.inflight || len(e.memory) > 0 || (e.spool != nil && !e.spool.empty())
+}
+
+// 没有未发送的日志时通知等待中的Flush（需持有队列锁）
+func (e *Adapter) notifyDrainedLocked() {
+	if e.drained != nil && !e.pendingLocked() {
+		close(e.drained)
+		e.drained = nil
+	}
+}
+
+// 日志加入发送队列
+//
+//	磁盘队列不为空时新日志也需要追加到磁盘队列，以保证发送顺序
+func (e *Adapter) enqueue(content []byte) {
+	// UDP无法发送超过数据报长度的日志
+	if e.datagram && len(content) > maxDatagramSize {
+		atomic.AddUint64(&e.dropped, 1)
+		return
+	}
+
+	e.mutex.Lock()
+	switch {
+	case e.closed:
+		atomic.AddUint64(&e.dropped, 1)
+	case e.spool != nil && (!e.spool.empty() || e.memBytes+len(content) > e.memMax):
+		e.spillLocked(content)
+	case e.memBytes+len(content) > e.memMax:
+		atomic.AddUint64(&e.dropped, 1)
+	default:
+		// 日志内容由记录器复用，需要复制
+		e.memory = append(e.memory, append([]byte(nil), content...))
+		e.memBytes += len(content)
+	}
+	e.mutex.Unlock()
+
+	// 通知发送协程
+	select {
+	case e.notify <- struct{}{}:
+	default:
+	}
+}
+
+// 日志追加到磁盘队列（需持有队列锁）
+func (e *Adapter) spillLocked(content []byte) {
+	ok, err := e.spool.append(content)
+	if err != nil {
+		printWarningMsg("belog net adapter spool write failed: " + err.Error())
+	}
+	if ok {
+		atomic.AddUint64(&e.spilled, 1)
+	} else {
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// 获取下一批需要发送的日志，没有日志时阻塞等待
+//
+//	@param	batch	填充目标
+//	@return	日志列表（已停止时为nil）
+//	@return	磁盘队列中最后一条日志之后的位置（来自内存缓冲区时为-1）
+func (e *Adapter) next(batch [][]byte) ([][]byte, int64) {
+	for {
+		e.mutex.Lock()
+		// 内存缓冲区中的日志早于磁盘队列中的日志
+		if len(e.memory) > 0 {
+			total := 0
+			for _, record := range e.memory {
+				if total > 0 && total+len(record) > maxBatchSize {
+					break
+				}
+				batch = append(batch, record)
+				total += len(record)
+			}
+			e.inflight = true
+			e.mutex.Unlock()
+			return batch, -1
+		}
+		if e.spool != nil && !e.spool.empty() {
+			var next int64
+			var err error
+			batch, next, err = e.spool.read(batch, maxBatchSize)
+			if err != nil {
+				// 磁盘队列损坏时丢弃剩余的日志
+				printWarningMsg("belog net adapter spool read failed: " + err.Error())
+				_ = e.spool.reset()
+				e.notifyDrainedLocked()
+				e.mutex.Unlock()
+				continue
+			}
+			e.inflight = true
+			e.mutex.Unlock()
+			return batch, next
+		}
+		e.mutex.Unlock()
+
+		// 等待新日志
+		select {
+		case <-e.notify:
+		case <-e.stop:
+			return nil, -1
+		}
+	}
+}
+
+// 确认日志已发送
+//
+//	@param	n		已发送的日志条数
+//	@param	next	磁盘队列中最后一条日志之后的位置（来自内存缓冲区时为-1）
+func (e *Adapter) ack(n int, next int64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	defer e.notifyDrainedLocked()
+	e.inflight = false
+	if n == 0 {
+		return
+	}
+	atomic.AddUint64(&e.sent, uint64(n))
+	if next >= 0 {
+		if err := e.spool.commit(next); err != nil {
+			printWarningMsg("belog net adapter spool commit failed: " + err.Error())
+		}
+		return
+	}
+	for _, record := range e.memory[:n] {
+		e.memBytes -= len(record)
+	}
+	// 释放已发送日志的引用
+	for i := 0; i < n; i++ {
+		e.memory[i] = nil
+	}
+	e.memory = e.memory[n:]
+	if len(e.memory) == 0 {
+		e.memory = nil
+	}
+}
+
+// 建立连接
+func (e *Adapter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: e.timeout}
+	if e.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, e.network, e.address, e.tlsConfig)
+	}
+	return dialer.Dial(e.network, e.address)
+}
+
+// 发送一批日志
+//
+//	@return	成功发送的日志条数
+//	@return	异常信息
+func (e *Adapter) send(conn net.Conn, batch [][]byte, buf []byte) (int, []byte, error) {
+	_ = conn.SetWriteDeadline(time.Now().Add(e.timeout))
+
+	// UDP每条日志一个数据报
+	if e.datagram {
+		for i, record := range batch {
+			if _, err := conn.Write(record); err != nil {
+				return i, buf, err
+			}
+		}
+		return len(batch), buf, nil
+	}
+
+	// TCP合并后一次写入
+	buf = buf[:0]
+	for _, record := range batch {
+		buf = append(buf, record...)
+	}
+	if _, err := conn.Write(buf); err != nil {
+		return 0, buf, err
+	}
+	return len(batch), buf, nil
+}
+
+// 发送协程
+//
+//	连接失败后按指数退避重连，发送失败的日志将在重连后重新发送（TCP下可能重复）
+func (e *Adapter) run() {
+	defer close(e.done)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	backoff := e.reconnectMin
+	warned := false
+	var batch [][]byte
+	var buf []byte
+
+	for {
+		var next int64
+		batch, next = e.next(batch[:0])
+		if batch == nil {
+			return
+		}
+
+		// 建立连接
+		if conn == nil {
+			var err error
+			if conn, err = e.dial(); err != nil {
+				conn = nil
+				e.ack(0, next)
+				if !warned {
+					printWarningMsg("belog net adapter connect failed: " + err.Error())
+					warned = true
+				}
+				// 等待重连
+				select {
+				case <-time.After(backoff):
+				case <-e.stop:
+					return
+				}
+				if backoff *= 2; backoff > e.reconnectMax {
+					backoff = e.reconnectMax
+				}
+				continue
+			}
+			backoff, warned = e.reconnectMin, false
+		}
+
+		// 发送日志
+		var n int
+		var err error
+		n, buf, err = e.send(conn, batch, buf)
+		if err != nil {
+			conn.Close()
+			conn = nil
+			// 磁盘队列只能整批确认
+			if next >= 0 {
+				n = 0
+			}
+		}
+		e.ack(n, next)
+	}
+}
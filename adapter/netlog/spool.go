@@ -0,0 +1,267 @@
+/**
+ *@Title 网络日志磁盘队列
+ *@Desc 内存缓冲区已满时日志将追加到磁盘队列，重连后按顺序重放
+ */
+
+package netlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// 磁盘队列记录头长度（4字节大端序记录长度）
+const spoolRecordHeaderSize = 4
+
+// 磁盘队列
+//
+//	数据文件按"长度+内容"依次追加记录，读取位置单独保存在位置文件中，
+//	全部记录发送完成后数据文件将被清空
+type spool struct {
+	file       *os.File // 数据文件
+	offsetFile *os.File // 读取位置文件
+	maxSize    int64    // 数据文件容量上限
+	readOff    int64    // 下一条未发送记录的位置
+	size       int64    // 数据文件大小
+	header     [8]byte  // 记录头及读取位置缓冲区
+}
+
+// 打开磁盘队列
+//
+//	上次未发送完成的记录将被保留，文件末尾不完整的记录将被截断
+//
+//	@param	dir		队列目录
+//	@param	name	队列名称
+//	@param	maxSize	数据文件容量上限
+//	@return	磁盘队列
+//	@return	异常信息
+func openSpool(dir string, name string, maxSize int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, name+".queue"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	offsetFile, err := os.OpenFile(filepath.Join(dir, name+".offset"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	s := &spool{file: file, offsetFile: offsetFile, maxSize: maxSize}
+	if err = s.recover(); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// 恢复读取位置并截断不完整的记录
+func (s *spool) recover() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	s.size = info.Size()
+
+	// 读取位置
+	if n, _ := s.offsetFile.ReadAt(s.header[:8], 0); n == 8 {
+		s.readOff = int64(binary.BigEndian.Uint64(s.header[:8]))
+	}
+	if s.readOff < 0 || s.readOff > s.size {
+		s.readOff = 0
+	}
+
+	// 逐条校验记录长度
+	off := s.readOff
+	for off < s.size {
+		if _, err = s.file.ReadAt(s.header[:spoolRecordHeaderSize], off); err != nil {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(s.header[:spoolRecordHeaderSize]))
+		if off+spoolRecordHeaderSize+n > s.size {
+			break
+		}
+		off += spoolRecordHeaderSize + n
+	}
+	if off < s.size {
+		if err = s.file.Truncate(off); err != nil {
+			return err
+		}
+		s.size = off
+	}
+	if s.empty() {
+		return s.reset()
+	}
+	return nil
+}
+
+// 队列是否为空
+func (s *spool) empty() bool {
+	return s.readOff >= s.size
+}
+
+// 追加一条记录
+//
+//	@param	record	记录内容
+//	@return	是否追加成功（超过容量上限时失败）
+//	@return	异常信息
+func (s *spool) append(record []byte) (bool, error) {
+	if s.size+spoolRecordHeaderSize+int64(len(record)) > s.maxSize {
+		return false, nil
+	}
+	binary.BigEndian.PutUint32(s.header[:spoolRecordHeaderSize], uint32(len(record)))
+	if _, err := s.file.WriteAt(s.header[:spoolRecordHeaderSize], s.size); err != nil {
+		return false, err
+	}
+	if _, err := s.file.WriteAt(record, s.size+spoolRecordHeaderSize); err != nil {
+		// 回滚不完整的记录
+		_ = s.file.Truncate(s.size)
+		return false, err
+	}
+	s.size += spoolRecordHeaderSize + int64(len(record))
+	return true, nil
+}
+
+// 读取未发送的记录
+//
+//	至少读取一条记录，此后累计长度超过maxBytes时停止读取
+//
+//	@param	dst			填充目标
+//	@param	maxBytes	累计长度上限
+//	@return	记录列表
+//	@return	最后一条记录之后的位置
+//	@return	异常信息
+func (s *spool) read(dst [][]byte, maxBytes int) ([][]byte, int64, error) {
+	off := s.readOff
+	total := 0
+	var header [spoolRecordHeaderSize]byte
+	for off < s.size && (total == 0 || total < maxBytes) {
+		if _, err := s.file.ReadAt(header[:], off); err != nil {
+			return dst, off, err
+		}
+		n := int(binary.BigEndian.Uint32(header[:]))
+		record := make([]byte, n)
+		if _, err := s.file.ReadAt(record, off+spoolRecordHeaderSize); err != nil {
+			return dst, off, err
+		}
+		dst = append(dst, record)
+		off += spoolRecordHeaderSize + int64(n)
+		total += n
+	}
+	return dst, off, nil
+}
+
+// 在未发送的记录之前插入记录
+//
+//	关闭适配器时内存缓冲区中的日志早于磁盘队列中的日志，需要插入到队列头部以保证重放顺序，
+//	插入时将未发送的记录复制到临时文件中重写数据文件；超过容量上限的记录将被丢弃
+//
+//	@param	records	记录列表（最旧的在前）
+//	@return	插入成功的记录条数
+//	@return	异常信息（失败时数据文件保持不变）
+func (s *spool) prepend(records [][]byte) (int, error) {
+	name := s.file.Name()
+	tmp, err := os.OpenFile(name+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	n, size, err := s.writePrepended(tmp, records)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, err
+	}
+
+	// 替换数据文件
+	if err = s.file.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, err
+	}
+	err = os.Rename(tmp.Name(), name)
+	file, oerr := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if oerr != nil {
+		return 0, oerr
+	}
+	s.file = file
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return 0, err
+	}
+	s.readOff, s.size = 0, size
+	return n, s.offsetFile.Truncate(0)
+}
+
+// 将插入的记录及未发送的记录依次写入临时文件
+//
+//	@param	tmp		临时文件
+//	@param	records	插入的记录列表
+//	@return	插入成功的记录条数
+//	@return	临时文件大小
+//	@return	异常信息
+func (s *spool) writePrepended(tmp *os.File, records [][]byte) (int, int64, error) {
+	pending := s.size - s.readOff
+	var size int64
+	n := 0
+	var header [spoolRecordHeaderSize]byte
+	for _, record := range records {
+		if size+spoolRecordHeaderSize+int64(len(record))+pending > s.maxSize {
+			break
+		}
+		binary.BigEndian.PutUint32(header[:], uint32(len(record)))
+		if _, err := tmp.Write(header[:]); err != nil {
+			return 0, 0, err
+		}
+		if _, err := tmp.Write(record); err != nil {
+			return 0, 0, err
+		}
+		size += spoolRecordHeaderSize + int64(len(record))
+		n++
+	}
+	if _, err := io.Copy(tmp, io.NewSectionReader(s.file, s.readOff, pending)); err != nil {
+		return 0, 0, err
+	}
+	return n, size + pending, tmp.Sync()
+}
+
+// 确认记录已发送
+//
+//	@param	next	已发送的最后一条记录之后的位置
+//	@return	异常信息
+func (s *spool) commit(next int64) error {
+	if next <= s.readOff || next > s.size {
+		return errors.New("belog/netlog: invalid spool offset")
+	}
+	s.readOff = next
+	if s.empty() {
+		return s.reset()
+	}
+	binary.BigEndian.PutUint64(s.header[:8], uint64(s.readOff))
+	_, err := s.offsetFile.WriteAt(s.header[:8], 0)
+	return err
+}
+
+// 清空队列
+func (s *spool) reset() error {
+	s.readOff, s.size = 0, 0
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	return s.offsetFile.Truncate(0)
+}
+
+// 关闭队列
+func (s *spool) close() error {
+	_ = s.file.Sync()
+	_ = s.offsetFile.Sync()
+	err := s.file.Close()
+	if cerr := s.offsetFile.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
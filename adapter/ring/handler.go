@@ -0,0 +1,115 @@
+/**
+ *@Title 内存环形缓冲区日志导出
+ *@Desc 通过HTTP按查询条件导出NDJSON格式的日志记录
+ */
+
+package ring
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 导出的日志记录
+type dumpRecord struct {
+	Seq     uint64 `json:"seq"`
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Func    string `json:"func,omitempty"`
+	Content string `json:"content"`
+}
+
+// 解析时间（RFC3339格式或Unix毫秒时间戳）
+func parseTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), true
+}
+
+// ParseQuery 从URL查询参数解析日志查询条件
+//
+//	level	最小日志级别（名称或数字）
+//	max		最大日志级别（名称或数字）
+//	since	起始时间（RFC3339格式或Unix毫秒时间戳）
+//	until	截止时间（RFC3339格式或Unix毫秒时间戳）
+//	q		日志内容包含的子串
+//	limit	最多返回的条数
+//
+//	@param	r	HTTP请求
+//	@return	查询条件
+//	@return	无效的参数名（为空时表示解析成功）
+func ParseQuery(r *http.Request) (Query, string) {
+	var q Query
+	values := r.URL.Query()
+	var ok bool
+	var err error
+	if s := values.Get("level"); len(s) > 0 {
+		if q.MinLevel, err = logger.ParseLevel(s); err != nil {
+			return q, "level"
+		}
+	}
+	if s := values.Get("max"); len(s) > 0 {
+		if q.MaxLevel, err = logger.ParseLevel(s); err != nil {
+			return q, "max"
+		}
+	}
+	if s := values.Get("since"); len(s) > 0 {
+		if q.Since, ok = parseTime(s); !ok {
+			return q, "since"
+		}
+	}
+	if s := values.Get("until"); len(s) > 0 {
+		if q.Until, ok = parseTime(s); !ok {
+			return q, "until"
+		}
+	}
+	q.Contains = values.Get("q")
+	if s := values.Get("limit"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return q, "limit"
+		}
+		q.Limit = n
+	}
+	return q, ""
+}
+
+// ServeHTTP 以NDJSON格式导出匹配的日志记录
+//
+//	查询参数见ParseQuery，每行为一条记录：
+//	{"seq":0,"time":"...","level":"info","file":"...","line":1,"func":"...","content":"..."}
+//
+//	注意：日志可能包含敏感信息，请勿在公网暴露该接口
+func (e *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q, invalid := ParseQuery(r)
+	if len(invalid) > 0 {
+		http.Error(w, "invalid query parameter `"+invalid+"`", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, rec := range e.Records(q) {
+		_ = enc.Encode(dumpRecord{
+			Seq:     rec.Seq,
+			Time:    rec.Time.Format(time.RFC3339Nano),
+			Level:   rec.Level.String(),
+			File:    rec.FileName,
+			Line:    rec.LineNo,
+			Func:    rec.FuncName,
+			Content: string(rec.Content),
+		})
+	}
+}
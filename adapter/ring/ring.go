@@ -0,0 +1,246 @@
+/**
+ *@Title 内存环形缓冲区日志适配器
+ *@Desc 在内存中保留最近的N条日志（含时间、级别及调用栈信息），支持按条件查询，用于排查线上问题
+ */
+
+package ring
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName 内存环形缓冲区日志适配器默认名称
+const DefaultName = "belog-ring-adapter"
+
+// Options 内存环形缓冲区日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// Default: belog-ring-adapter
+	Name string
+
+	// 保留的日志条数
+	//
+	// 超出后最旧的日志将被覆盖
+	//
+	// Default: 10000, Min: 1, Max: 10000000
+	Size uint
+
+	// 需要记录的最小日志级别
+	//
+	// 低于记录器级别的日志也会输出到该适配器（参见logger.LevelAdapter），
+	// 因此可以在记录器只输出警告日志时，在环形缓冲区中保留调试日志
+	//
+	// Default: logger.Trace
+	MinLevel logger.Level
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 保留的日志条数
+	if p.Size == 0 || p.Size > 10000000 {
+		if p.Size != 0 {
+			printWarningMsg("ring size min value is 1,max value is 10000000, use the default value 10000")
+		}
+		p.Size = 10000
+	}
+	// 最小日志级别
+	if p.MinLevel < logger.Trace || p.MinLevel > logger.Fatal {
+		if p.MinLevel != 0 {
+			printWarningMsg("ring min level is invalid, use the default value trace")
+		}
+		p.MinLevel = logger.Trace
+	}
+}
+
+// Record 日志记录
+type Record struct {
+	Seq      uint64       // 记录序号（从0开始递增）
+	Time     time.Time    // 日志记录时间
+	Level    logger.Level // 日志级别
+	Content  []byte       // 日志内容（由记录器编码器生成）
+	FileName string       // 日志记录调用文件路径（无调用栈时为空）
+	LineNo   int          // 日志记录调用文件行号
+	FuncName string       // 日志记录调用函数名
+}
+
+// Query 日志查询条件
+//
+//	零值条件不参与过滤
+type Query struct {
+	MinLevel logger.Level // 最小日志级别
+	MaxLevel logger.Level // 最大日志级别
+	Since    time.Time    // 起始时间（含）
+	Until    time.Time    // 截止时间（不含）
+	Contains string       // 日志内容包含的子串
+	Limit    int          // 最多返回的条数（保留最新的记录）
+}
+
+// 判断记录是否匹配查询条件
+func (q *Query) match(r *Record) bool {
+	if q.MinLevel != 0 && r.Level < q.MinLevel {
+		return false
+	}
+	if q.MaxLevel != 0 && r.Level > q.MaxLevel {
+		return false
+	}
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !r.Time.Before(q.Until) {
+		return false
+	}
+	if len(q.Contains) > 0 && !bytes.Contains(r.Content, []byte(q.Contains)) {
+		return false
+	}
+	return true
+}
+
+// Adapter 内存环形缓冲区日志适配器
+//
+//	写入时通过原子递增的序号占用槽位，槽位中的记录以CAS方式替换为序号更大的记录，
+//	写入及查询均不加锁；实现了logger.LevelAdapter，不受记录器级别限制
+type Adapter struct {
+	next     uint64           // 下一条记录的序号（原子操作，需保持64位对齐）
+	name     string           // 适配器名称
+	minLevel logger.Level     // 需要记录的最小日志级别
+	slots    []unsafe.Pointer // 记录槽位，存储*Record
+}
+
+// New 创建内存环形缓冲区日志适配器
+//
+//	注意：查询日志时需将返回值断言为*ring.Adapter
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+func New(options Options) logger.Adapter {
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化内存环形缓冲区日志适配器
+	return &Adapter{
+		name:     options.Name,
+		minLevel: options.MinLevel,
+		slots:    make([]unsafe.Pointer, options.Size),
+	}
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(logTime time.Time, level logger.Level, content []byte) {
+	if level < e.minLevel {
+		return
+	}
+	e.store(&Record{Time: logTime, Level: level, Content: content})
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(logTime time.Time, level logger.Level, content []byte, fileName string, lineNo int, methodName string) {
+	if level < e.minLevel {
+		return
+	}
+	e.store(&Record{Time: logTime, Level: level, Content: content, FileName: fileName, LineNo: lineNo, FuncName: methodName})
+}
+
+// MinLevel 获取需要记录的最小日志级别
+//
+//	低于记录器级别但不低于该级别的日志也会由记录器输出到该适配器
+func (e *Adapter) MinLevel() logger.Level {
+	return e.minLevel
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	内存环形缓冲区无需刷新
+func (e *Adapter) Flush() {}
+
+// 保存一条记录
+func (e *Adapter) store(r *Record) {
+	// 日志内容由记录器复用，需要复制
+	r.Content = append([]byte(nil), bytes.TrimRight(r.Content, "\r\n")...)
+	r.Seq = atomic.AddUint64(&e.next, 1) - 1
+
+	// 并发写入同一槽位时保留序号更大的记录
+	slot := &e.slots[r.Seq%uint64(len(e.slots))]
+	for {
+		old := atomic.LoadPointer(slot)
+		if old != nil && (*Record)(old).Seq > r.Seq {
+			return
+		}
+		if atomic.CompareAndSwapPointer(slot, old, unsafe.Pointer(r)) {
+			return
+		}
+	}
+}
+
+// Len 获取当前保留的日志条数
+func (e *Adapter) Len() int {
+	n := atomic.LoadUint64(&e.next)
+	if n > uint64(len(e.slots)) {
+		return len(e.slots)
+	}
+	return int(n)
+}
+
+// Records 查询日志记录
+//
+//	结果按记录顺序（从旧到新）排列，查询期间被覆盖或尚未写入完成的记录将被跳过
+//
+//	@param	q	查询条件
+//	@return	匹配的日志记录（只读，请勿修改）
+func (e *Adapter) Records(q Query) []Record {
+	end := atomic.LoadUint64(&e.next)
+	start := uint64(0)
+	if size := uint64(len(e.slots)); end > size {
+		start = end - size
+	}
+
+	var records []Record
+	for seq := start; seq < end; seq++ {
+		r := (*Record)(atomic.LoadPointer(&e.slots[seq%uint64(len(e.slots))]))
+		if r == nil || r.Seq != seq || !q.match(r) {
+			continue
+		}
+		records = append(records, *r)
+	}
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records
+}
@@ -0,0 +1,269 @@
+/**
+ *@Title syslog日志格式
+ *@Desc RFC 5424及RFC 3164格式的日志编码
+ */
+
+package syslog
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// Format syslog日志格式
+type Format uint8
+
+// syslog日志格式定义
+const (
+	RFC5424 Format = 0 // RFC 5424格式，字段写入结构化数据（默认）
+	RFC3164 Format = 1 // RFC 3164（BSD）格式，字段以行格式追加在日志描述之后
+)
+
+// Facility syslog设施
+type Facility uint8
+
+// syslog设施定义
+const (
+	Kern     Facility = 0 // 仅供内核使用
+	User     Facility = 1 // 默认
+	Mail     Facility = 2
+	Daemon   Facility = 3
+	Auth     Facility = 4
+	Syslog   Facility = 5
+	Lpr      Facility = 6
+	News     Facility = 7
+	Uucp     Facility = 8
+	Cron     Facility = 9
+	AuthPriv Facility = 10
+	Ftp      Facility = 11
+	Local0   Facility = 16
+	Local1   Facility = 17
+	Local2   Facility = 18
+	Local3   Facility = 19
+	Local4   Facility = 20
+	Local5   Facility = 21
+	Local6   Facility = 22
+	Local7   Facility = 23
+)
+
+// syslog严重性
+const (
+	severityCrit    = 2
+	severityErr     = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+)
+
+// Severity 获取日志级别对应的syslog严重性
+//
+//	Trace、Debug对应debug，Info对应informational，Warn对应warning，
+//	Error对应err，Fatal对应crit
+//
+//	@param	l	日志级别
+//	@return	syslog严重性
+func Severity(l logger.Level) uint8 {
+	switch l {
+	case logger.Trace, logger.Debug:
+		return severityDebug
+	case logger.Info:
+		return severityInfo
+	case logger.Warn:
+		return severityWarning
+	case logger.Error:
+		return severityErr
+	case logger.Fatal:
+		return severityCrit
+	default:
+		return severityInfo
+	}
+}
+
+// syslog日志编码器
+type syslogEncoder struct {
+	format    Format   // 日志格式
+	facility  Facility // 设施
+	hostname  string   // 主机名（RFC 3164格式下为空时省略）
+	appName   string   // 应用名称
+	procID    string   // 进程ID
+	sdID      string   // 结构化数据标识
+	skipStack bool     // 是否忽略调用栈
+}
+
+// Encode 编码输出方法
+//
+//	@param	dst	填充目标
+//	@param	t	日志记录时间
+//	@param	l	日志级别
+//	@param	msg	日志描述
+//	@param	val	日志内容字段
+//	@return	填充后的内容
+func (e *syslogEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	return e.encode(dst, t, l, "", 0, "", msg, val...)
+}
+
+// EncodeStack 含调用栈编码输出方法
+//
+//	@param	dst	填充目标
+//	@param	t	日志记录时间
+//	@param	l	日志级别
+//	@param	fn	调用栈文件名
+//	@param	ln	调用栈行号
+//	@param	mn	调用栈函数名
+//	@param	msg	日志描述
+//	@param	val	日志内容字段
+//	@return 填充后的内容
+func (e *syslogEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	if e.skipStack {
+		fn = ""
+	}
+	return e.encode(dst, t, l, fn, ln, mn, msg, val...)
+}
+
+// 编码日志（fn为空时不含调用栈）
+func (e *syslogEncoder) encode(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	// PRI
+	dst = append(dst, '<')
+	dst = strconv.AppendUint(dst, uint64(e.facility)*8+uint64(Severity(l)), 10)
+	dst = append(dst, '>')
+
+	if e.format == RFC3164 {
+		return e.encode3164(dst, t, fn, ln, msg, val...)
+	}
+	return e.encode5424(dst, t, fn, ln, mn, msg, val...)
+}
+
+// 编码RFC 5424格式
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ID PARAM="VALUE" ...] MSG
+func (e *syslogEncoder) encode5424(dst []byte, t time.Time, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	dst = append(dst, '1', ' ')
+	dst = t.AppendFormat(dst, "2006-01-02T15:04:05.000000Z07:00")
+	dst = append(dst, ' ')
+	dst = appendHeaderValue(dst, e.hostname, 255)
+	dst = append(dst, ' ')
+	dst = appendHeaderValue(dst, e.appName, 48)
+	dst = append(dst, ' ')
+	dst = appendHeaderValue(dst, e.procID, 128)
+	dst = append(dst, " - "...)
+
+	// 结构化数据
+	if len(val) == 0 && len(fn) == 0 {
+		dst = append(dst, '-')
+	} else {
+		dst = append(dst, '[')
+		dst = append(dst, e.sdID...)
+		if len(fn) > 0 {
+			dst = appendParam(dst, "file", []byte(fn))
+			dst = appendParam(dst, "line", strconv.AppendInt(nil, int64(ln), 10))
+			dst = appendParam(dst, "func", []byte(mn))
+		}
+		var tmp []byte
+		for i := range val {
+			tmp = encoder.AppendValue(tmp[:0], val[i])
+			dst = appendParam(dst, val[i].Key, tmp)
+		}
+		dst = append(dst, ']')
+	}
+
+	// 日志描述
+	if len(msg) > 0 {
+		dst = append(dst, ' ')
+		dst = append(dst, msg...)
+	}
+	return dst
+}
+
+// 编码RFC 3164格式
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG, k:v, k:v
+func (e *syslogEncoder) encode3164(dst []byte, t time.Time, fn string, ln int, msg string, val ...field.Field) []byte {
+	dst = t.AppendFormat(dst, time.Stamp)
+	dst = append(dst, ' ')
+	if len(e.hostname) > 0 {
+		dst = append(dst, e.hostname...)
+		dst = append(dst, ' ')
+	}
+	dst = append(dst, e.appName...)
+	dst = append(dst, '[')
+	dst = append(dst, e.procID...)
+	dst = append(dst, "]: "...)
+
+	// 调用栈
+	if len(fn) > 0 {
+		dst = append(dst, '[')
+		dst = append(dst, fn...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(ln), 10)
+		dst = append(dst, "] "...)
+	}
+
+	// 日志描述及字段
+	dst = append(dst, msg...)
+	for i := range val {
+		dst = append(dst, ", "...)
+		dst = append(dst, val[i].Key...)
+		dst = append(dst, ':')
+		dst = encoder.AppendValue(dst, val[i])
+	}
+	return dst
+}
+
+// 追加RFC 5424头部字段（仅允许可打印ASCII字符，为空时使用"-"）
+func appendHeaderValue(dst []byte, s string, maxLen int) []byte {
+	if len(s) == 0 {
+		return append(dst, '-')
+	}
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// 追加结构化数据参数
+//
+//	参数名仅保留允许的字符并截断到32个字符，
+//	参数值中的'"'、'\'及']'需要转义
+func appendParam(dst []byte, name string, value []byte) []byte {
+	dst = append(dst, ' ')
+	dst = appendSDName(dst, name)
+	dst = append(dst, '=', '"')
+	for _, c := range value {
+		if c == '"' || c == '\\' || c == ']' {
+			dst = append(dst, '\\')
+		}
+		dst = append(dst, c)
+	}
+	return append(dst, '"')
+}
+
+// 追加结构化数据名称（SD-NAME）
+//
+//	仅允许除'='、' '、']'、'"'以外的可打印ASCII字符，最长32个字符
+func appendSDName(dst []byte, name string) []byte {
+	if len(name) == 0 {
+		return append(dst, '_')
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
@@ -0,0 +1,349 @@
+/**
+ *@Title syslog日志记录适配器
+ *@Desc 将日志发送到本机syslog服务（/dev/log）或通过UDP、TCP发送到远程syslog服务
+ */
+
+package syslog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName syslog日志适配器默认名称
+const DefaultName = "belog-syslog-adapter"
+
+// DefaultSDID 默认结构化数据标识
+//
+//	32473为RFC 5612中用于示例的企业编号，可替换为自己的企业编号
+const DefaultSDID = "belog@32473"
+
+// 本机syslog服务的套接字路径
+var localSocketPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Options syslog日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// Default: belog-syslog-adapter
+	Name string
+
+	// 网络类型
+	//
+	// 可选值：udp、tcp、unix、unixgram，为空时连接本机syslog服务（/dev/log）
+	//
+	// Default: ""
+	Network string
+
+	// syslog服务地址
+	//
+	// 网络类型为udp、tcp时为host:port，为unix、unixgram时为套接字路径
+	Address string
+
+	// 日志格式
+	//
+	// Default: RFC5424
+	Format Format
+
+	// 设施
+	//
+	// Kern仅供内核使用，设置为Kern（零值）时将使用User
+	//
+	// Default: User
+	Facility Facility
+
+	// 应用名称
+	//
+	// Default: 当前可执行文件名
+	AppName string
+
+	// 主机名
+	//
+	// 连接本机syslog服务且使用RFC3164格式时不写入主机名
+	//
+	// Default: os.Hostname()
+	Hostname string
+
+	// 结构化数据标识（仅RFC5424格式）
+	//
+	// Default: belog@32473
+	SDID string
+
+	// 是否忽略调用栈信息
+	//
+	// Default: false
+	DisabledStack bool
+
+	// 连接及写入超时时间
+	//
+	// Unit: 毫秒, Default: 3000, Min: 100, Max: 60000
+	Timeout uint
+
+	// 断开后的重连间隔
+	//
+	// 连接断开后将在下一次写入时重连，重连失败后在该间隔内的日志将被丢弃
+	//
+	// Unit: 毫秒, Default: 1000, Max: 3600000
+	ReconnectInterval uint
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 判断网络类型
+	switch p.Network {
+	case "", "unix", "unixgram":
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		printWarningMsg("syslog network `" + p.Network + "` is not supported, use the local syslog service")
+		p.Network = ""
+	}
+	// 判断日志格式
+	if p.Format > RFC3164 {
+		p.Format = RFC5424
+		printWarningMsg("syslog format error, use the default value RFC5424")
+	}
+	// 判断设施
+	if p.Facility == Kern {
+		p.Facility = User
+	}
+	if p.Facility > Local7 {
+		p.Facility = User
+		printWarningMsg("syslog facility max value is 23(local7), use the default value 1(user)")
+	}
+	// 应用名称
+	if len(p.AppName) == 0 {
+		p.AppName = filepath.Base(os.Args[0])
+	}
+	// 主机名
+	if len(p.Hostname) == 0 {
+		p.Hostname, _ = os.Hostname()
+	}
+	// 结构化数据标识
+	if len(p.SDID) == 0 || strings.ContainsAny(p.SDID, " =]\"") || len(p.SDID) > 32 {
+		if len(p.SDID) > 0 {
+			printWarningMsg("syslog SD-ID `" + p.SDID + "` is invalid, use the default value " + DefaultSDID)
+		}
+		p.SDID = DefaultSDID
+	}
+	// 超时时间
+	if p.Timeout < 100 || p.Timeout > 60000 {
+		if p.Timeout != 0 {
+			printWarningMsg("syslog timeout min value is 100(ms),max value is 60000(ms), use the default value 3000(ms)")
+		}
+		p.Timeout = 3000
+	}
+	// 重连间隔
+	if p.ReconnectInterval == 0 || p.ReconnectInterval > 3600000 {
+		if p.ReconnectInterval != 0 {
+			printWarningMsg("syslog reconnect interval max value is 3600000(ms), use the default value 1000(ms)")
+		}
+		p.ReconnectInterval = 1000
+	}
+}
+
+// Adapter syslog日志适配器
+type Adapter struct {
+	name              string         // 适配器名称
+	network           string         // 网络类型
+	address           string         // syslog服务地址
+	timeout           time.Duration  // 连接及写入超时时间
+	reconnectInterval time.Duration  // 重连间隔
+	encoder           *syslogEncoder // 日志编码器
+	mutex             sync.Mutex     // 连接锁
+	conn              net.Conn       // 当前连接（断开时为nil）
+	connNetwork       string         // 当前连接的网络类型
+	lastDial          time.Time      // 上一次连接失败的时间
+	buf               []byte         // 帧缓冲区
+}
+
+// New 创建syslog日志适配器
+//
+//	注意：该适配器实现了logger.EncoderAdapter，日志内容由适配器自带的编码器生成，
+//	记录器的编码器设置对其无效
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化syslog日志适配器
+	e := &Adapter{
+		name:              options.Name,
+		network:           options.Network,
+		address:           options.Address,
+		timeout:           time.Duration(options.Timeout) * time.Millisecond,
+		reconnectInterval: time.Duration(options.ReconnectInterval) * time.Millisecond,
+		encoder: &syslogEncoder{
+			format:    options.Format,
+			facility:  options.Facility,
+			hostname:  options.Hostname,
+			appName:   options.AppName,
+			procID:    strconv.Itoa(os.Getpid()),
+			sdID:      options.SDID,
+			skipStack: options.DisabledStack,
+		},
+	}
+	// 本机syslog服务使用RFC3164格式时不写入主机名
+	if len(options.Network) == 0 && options.Format == RFC3164 {
+		e.encoder.hostname = ""
+	}
+
+	// 建立连接
+	if err := e.connect(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Encoder 获取适配器自带的编码器
+func (e *Adapter) Encoder() logger.Encoder {
+	return e.encoder
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.write(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, _ string, _ int, _ string) {
+	e.write(content)
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	syslog日志适配器无缓冲区，每条日志都会立即发送
+func (e *Adapter) Flush() {}
+
+// Close 关闭syslog连接
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// 建立连接（需持有连接锁或尚未共享）
+func (e *Adapter) connect() error {
+	// 远程或指定套接字
+	if len(e.network) > 0 {
+		conn, err := net.DialTimeout(e.network, e.address, e.timeout)
+		if err != nil {
+			return err
+		}
+		e.conn, e.connNetwork = conn, e.network
+		return nil
+	}
+
+	// 本机syslog服务，依次尝试常见的套接字路径及类型
+	paths := localSocketPaths
+	if len(e.address) > 0 {
+		paths = []string{e.address}
+	}
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, path, e.timeout)
+			if err == nil {
+				e.conn, e.connNetwork = conn, network
+				return nil
+			}
+		}
+	}
+	return errors.New("belog/syslog: unix syslog delivery error")
+}
+
+// 按网络类型组装帧
+//
+//	TCP使用RFC 6587的八位组计数（"长度 内容"），
+//	unix流式套接字以换行结尾，数据报不需要分帧
+func (e *Adapter) frame(content []byte) []byte {
+	e.buf = e.buf[:0]
+	switch e.connNetwork {
+	case "tcp", "tcp4", "tcp6":
+		e.buf = strconv.AppendInt(e.buf, int64(len(content)), 10)
+		e.buf = append(e.buf, ' ')
+		e.buf = append(e.buf, content...)
+	case "unix":
+		e.buf = append(e.buf, content...)
+		if len(content) == 0 || content[len(content)-1] != '\n' {
+			e.buf = append(e.buf, '\n')
+		}
+	default:
+		return content
+	}
+	return e.buf
+}
+
+// 发送日志，失败时重连并重试一次
+func (e *Adapter) write(content []byte) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for retry := 0; retry < 2; retry++ {
+		// 连接已断开时重连
+		if e.conn == nil {
+			if time.Since(e.lastDial) < e.reconnectInterval {
+				return
+			}
+			if err := e.connect(); err != nil {
+				e.lastDial = time.Now()
+				printWarningMsg("belog syslog adapter reconnect failed: " + err.Error())
+				return
+			}
+		}
+
+		// 发送日志
+		_ = e.conn.SetWriteDeadline(time.Now().Add(e.timeout))
+		if _, err := e.conn.Write(e.frame(content)); err == nil {
+			return
+		}
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
@@ -0,0 +1,232 @@
+/**
+ *@Title 通用输出流日志记录适配器
+ *@Desc 将日志写入任意io.Writer（如测试中的bytes.Buffer、连接伴生进程的管道）
+ */
+
+package writer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/logger"
+)
+
+// DefaultName 通用输出流日志适配器默认名称
+const DefaultName = "belog-writer-adapter"
+
+// Options 通用输出流日志适配器参数
+type Options struct {
+
+	// 适配器名称
+	//
+	// 同一记录器挂载多个通用输出流日志适配器时，需要为每个适配器指定不同的名称，
+	// 否则后挂载的适配器将覆盖先挂载的适配器
+	//
+	// Default: belog-writer-adapter
+	Name string
+
+	// 日志输出目标
+	//
+	// 不能为空
+	Writer io.Writer
+
+	// 写入缓冲区容量
+	//
+	// 为0时每条日志直接写入输出目标，否则在缓冲区写满或调用Flush时写入
+	//
+	// Unit: KB, Default: 0, Max: 65536
+	BufferSize uint
+
+	// 禁用写入锁
+	//
+	// 适配器的写入方法可能被并发调用，默认会为输出目标加锁；
+	// 输出目标本身并发安全（如*os.File）且未启用缓冲区时可禁用写入锁以减少开销
+	//
+	// Default: false
+	DisabledLock bool
+}
+
+// 输出目标可选实现的刷新接口
+type flusher interface {
+	Flush() error
+}
+
+// 输出目标可选实现的持久化接口
+type syncer interface {
+	Sync() error
+}
+
+// Adapter 通用输出流日志适配器
+type Adapter struct {
+	name   string        // 适配器名称
+	writer io.Writer     // 输出目标
+	buffer *bufio.Writer // 写入缓冲器（未启用缓冲区时为nil）
+	mutex  *sync.Mutex   // 写入锁（禁用写入锁时为nil）
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() {
+	// 判断适配器名称是否为空
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		p.Name = DefaultName
+	}
+	// 判断缓冲区容量
+	if p.BufferSize > 65536 {
+		p.BufferSize = 0
+		printWarningMsg("writer buffer size max value is 65536(KB), use the default value 0(KB)")
+	}
+	// 启用缓冲区时必须加锁
+	if p.BufferSize > 0 && p.DisabledLock {
+		p.DisabledLock = false
+		printWarningMsg("writer lock cannot be disabled when the buffer is enabled")
+	}
+}
+
+// New 创建通用输出流日志适配器
+//
+//	@param	options	适配器参数
+//	@return	适配器实例
+//	@return	异常信息
+func New(options Options) (logger.Adapter, error) {
+	// 输出目标不能为空
+	if options.Writer == nil {
+		return nil, errors.New("the `Writer` of options is null")
+	}
+	// 判断参数有效性
+	options.validity()
+
+	// 实例化通用输出流日志适配器
+	e := &Adapter{
+		name:   options.Name,
+		writer: options.Writer,
+	}
+	if !options.DisabledLock {
+		e.mutex = new(sync.Mutex)
+	}
+	if options.BufferSize > 0 {
+		e.buffer = bufio.NewWriterSize(options.Writer, int(options.BufferSize)*1024)
+	}
+	return e, nil
+}
+
+// Name 用于获取适配器名称
+//
+//	注意：请确保适配器名称不与其他适配器名称冲突
+func (e *Adapter) Name() string {
+	return e.name
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *Adapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	e.write(content)
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *Adapter) PrintStack(_ time.Time, _ logger.Level, content []byte, _ string, _ int, _ string) {
+	e.write(content)
+}
+
+// 写入日志
+func (e *Adapter) write(content []byte) {
+	if e.mutex != nil {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+	}
+	var err error
+	if e.buffer != nil {
+		_, err = e.buffer.Write(content)
+	} else {
+		_, err = e.writer.Write(content)
+	}
+	if err != nil {
+		printWarningMsg("belog writer adapter write failed: " + err.Error())
+	}
+}
+
+// Flush 日志缓存刷新
+//
+//	注意：用于日志缓冲区刷新，接收到该通知后需要立即将缓冲区中的日志持久化
+//
+//	写入缓冲区后，输出目标实现了Flush() error时将一并刷新
+func (e *Adapter) Flush() {
+	if err := e.flush(); err != nil {
+		printWarningMsg("belog writer adapter flush failed: " + err.Error())
+	}
+}
+
+// 刷新缓冲区及输出目标
+func (e *Adapter) flush() error {
+	if e.mutex != nil {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+	}
+	if e.buffer != nil && e.buffer.Buffered() > 0 {
+		if err := e.buffer.Flush(); err != nil {
+			return err
+		}
+	}
+	if f, ok := e.writer.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Sync 将日志持久化
+//
+//	刷新缓冲区后，输出目标实现了Sync() error（如*os.File）时将一并调用
+//
+//	@return	异常信息
+func (e *Adapter) Sync() error {
+	if err := e.flush(); err != nil {
+		return err
+	}
+	if s, ok := e.writer.(syncer); ok {
+		if e.mutex != nil {
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+		}
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close 关闭适配器
+//
+//	刷新缓冲区后，输出目标实现了io.Closer时将一并关闭，关闭后不应再写入日志
+//
+//	@return	异常信息
+func (e *Adapter) Close() error {
+	err := e.flush()
+	if c, ok := e.writer.(io.Closer); ok {
+		if e.mutex != nil {
+			e.mutex.Lock()
+			defer e.mutex.Unlock()
+		}
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
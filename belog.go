@@ -73,3 +73,23 @@ func New(option logger.Option, adapter ...logger.Adapter) (logger.Logger, error)
 	// 返回日志示例指针
 	return logger.New(option, adapter...)
 }
+
+// NewDevelopment 初始化一个适用于本地开发的日志记录器实例
+//
+//	使用美化编码器输出到控制台，记录所有级别的日志及调用位置，
+//	标准输出与标准错误分别检测，输出目标不是终端或设置了NO_COLOR环境变量时不输出颜色
+//
+//	@return 日志记录器实例
+func NewDevelopment() (logger.Logger, error) {
+	enc := encoder.NewPrettyEncoder(encoder.DefaultPrettyOption)
+	return logger.New(
+		logger.Option{
+			EnabledStackPrint: true,
+			Encoder:           enc,
+		},
+		console.New(console.Option{
+			DisabledBuffer: true,
+			Encoder:        enc,
+		}),
+	)
+}
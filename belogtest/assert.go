@@ -0,0 +1,107 @@
+/**
+ *@Title 日志断言
+ *@Desc 断言日志观察适配器是否捕获了指定级别、消息及字段的日志
+ */
+
+package belogtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 判断日志记录是否匹配
+func (e Entry) match(level logger.Level, msg string, fields []field.Field) bool {
+	return e.Level == level && e.Message == msg && e.hasFields(fields)
+}
+
+// 格式化日志记录（用于断言失败信息）
+func (e Entry) String() string {
+	var sb strings.Builder
+	sb.WriteString("[" + e.Level.String() + "] " + e.Message)
+	for _, f := range e.Fields {
+		sb.WriteString(fmt.Sprintf(", %s: %v", f.Key, FieldValue(f)))
+	}
+	return sb.String()
+}
+
+// 格式化全部日志记录（用于断言失败信息）
+func (o *Observer) dump() string {
+	entries := o.All()
+	if len(entries) == 0 {
+		return "\n\t(no logs)"
+	}
+	var sb strings.Builder
+	for _, e := range entries {
+		sb.WriteString("\n\t" + e.String())
+	}
+	return sb.String()
+}
+
+// Logged 判断是否捕获了匹配的日志
+//
+//	@param	level	日志级别
+//	@param	msg		日志消息
+//	@param	fields	日志需包含的字段（可为部分字段，字段值需完全一致）
+//	@return	是否捕获
+func (o *Observer) Logged(level logger.Level, msg string, fields ...field.Field) bool {
+	for _, e := range o.All() {
+		if e.match(level, msg, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged 断言捕获了匹配的日志，未捕获时测试失败并输出全部已捕获的日志
+//
+//	@param	t		测试实例
+//	@param	level	日志级别
+//	@param	msg		日志消息
+//	@param	fields	日志需包含的字段（可为部分字段，字段值需完全一致）
+//	@return	是否捕获
+func (o *Observer) AssertLogged(t testing.TB, level logger.Level, msg string, fields ...field.Field) bool {
+	t.Helper()
+	if o.Logged(level, msg, fields...) {
+		return true
+	}
+	want := Entry{Level: level, Message: msg, Fields: fields}
+	t.Errorf("expected log not found: %s\ncaptured logs:%s", want.String(), o.dump())
+	return false
+}
+
+// AssertNotLogged 断言未捕获匹配的日志
+//
+//	@param	t		测试实例
+//	@param	level	日志级别
+//	@param	msg		日志消息
+//	@param	fields	日志需包含的字段（可为部分字段，字段值需完全一致）
+//	@return	是否未捕获
+func (o *Observer) AssertNotLogged(t testing.TB, level logger.Level, msg string, fields ...field.Field) bool {
+	t.Helper()
+	if !o.Logged(level, msg, fields...) {
+		return true
+	}
+	want := Entry{Level: level, Message: msg, Fields: fields}
+	t.Errorf("unexpected log found: %s\ncaptured logs:%s", want.String(), o.dump())
+	return false
+}
+
+// AssertCount 断言捕获的指定级别日志条数
+//
+//	@param	t		测试实例
+//	@param	level	日志级别
+//	@param	count	期望的条数
+//	@return	是否一致
+func (o *Observer) AssertCount(t testing.TB, level logger.Level, count int) bool {
+	t.Helper()
+	if n := len(o.FilterLevel(level)); n != count {
+		t.Errorf("expected %d %s logs, got %d\ncaptured logs:%s", count, level.String(), n, o.dump())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+/**
+ *@Title 日志观察适配器
+ *@Desc 在测试中捕获解码后的日志记录（级别、消息、类型化字段及调用位置），用于断言代码是否记录了指定日志
+ */
+
+package belogtest
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/convert"
+)
+
+// ObserverName 日志观察适配器名称
+const ObserverName = "belog-observer-adapter"
+
+// Entry 捕获的日志记录
+type Entry struct {
+	Time     time.Time     // 日志记录时间
+	Level    logger.Level  // 日志级别
+	Message  string        // 日志消息
+	Fields   []field.Field // 日志字段
+	FileName string        // 日志记录调用文件路径（未启用调用栈打印时为空）
+	LineNo   int           // 日志记录调用文件行号
+	FuncName string        // 日志记录调用函数名
+}
+
+// Field 获取指定键名的字段
+//
+//	@param	key	字段键名
+//	@return	字段
+//	@return	是否存在
+func (e Entry) Field(key string) (field.Field, bool) {
+	for _, f := range e.Fields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return field.Field{}, false
+}
+
+// Value 获取指定键名的字段值
+//
+//	整型字段返回int64，无符号整型返回uint64，浮点型返回float64，
+//	布尔型返回bool，字符串及错误类型返回string，时间类型返回time.Time，
+//	时间间隔返回time.Duration，空值返回nil，其他类型返回原始值
+//
+//	@param	key	字段键名
+//	@return	字段值
+//	@return	是否存在
+func (e Entry) Value(key string) (interface{}, bool) {
+	f, ok := e.Field(key)
+	if !ok {
+		return nil, false
+	}
+	return FieldValue(f), true
+}
+
+// ContextMap 获取全部字段值（键名重复时保留最后一个）
+func (e Entry) ContextMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields))
+	for _, f := range e.Fields {
+		m[f.Key] = FieldValue(f)
+	}
+	return m
+}
+
+// FieldValue 获取字段的类型化值
+//
+//	返回值类型见Entry.Value
+func FieldValue(f field.Field) interface{} {
+	switch f.Type {
+	case field.TypeInt8, field.TypeInt16, field.TypeInt, field.TypeInt32, field.TypeInt64:
+		return f.Integer
+	case field.TypeDuration:
+		return time.Duration(f.Integer)
+	case field.TypeUint8, field.TypeUint16, field.TypeUint, field.TypeUint32, field.TypeUint64, field.TypeByte, field.TypeUintptr:
+		return uint64(f.Integer)
+	case field.TypeFloat32:
+		return float64(convert.Float32FromInt64(f.Integer))
+	case field.TypeFloat64:
+		return convert.Float64FromInt64(f.Integer)
+	case field.TypeBool:
+		return convert.BoolFromInt64(f.Integer)
+	case field.TypeString, field.TypeError:
+		return f.String
+	case field.TypeTime:
+		return convert.TimeFromInt64(f.Integer)
+	case field.TypeNull:
+		return nil
+	default:
+		return f.Interface
+	}
+}
+
+// 判断两个字段是否相等
+func fieldEqual(a field.Field, b field.Field) bool {
+	return a.Key == b.Key && a.Type == b.Type && a.Integer == b.Integer && a.String == b.String &&
+		reflect.DeepEqual(a.Interface, b.Interface)
+}
+
+// 判断日志记录是否包含全部指定字段
+func (e Entry) hasFields(fields []field.Field) bool {
+	for _, want := range fields {
+		found := false
+		for _, f := range e.Fields {
+			if fieldEqual(f, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Observer 日志观察适配器
+//
+//	实现了logger.EncoderAdapter，在编码阶段直接保存日志消息及字段，
+//	启用调用栈打印（EnabledStackPrint）后可获取调用位置
+type Observer struct {
+	mutex   sync.Mutex // 记录锁
+	entries []Entry    // 捕获的日志记录
+}
+
+// NewObserver 创建日志观察适配器
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Name 用于获取适配器名称
+func (o *Observer) Name() string {
+	return ObserverName
+}
+
+// Encoder 获取适配器自带的编码器
+func (o *Observer) Encoder() logger.Encoder {
+	return (*observerEncoder)(o)
+}
+
+// Print 普通日志打印方法（日志已在编码阶段保存）
+func (o *Observer) Print(time.Time, logger.Level, []byte) {}
+
+// PrintStack 调用栈日志打印方法（日志已在编码阶段保存）
+func (o *Observer) PrintStack(time.Time, logger.Level, []byte, string, int, string) {}
+
+// Flush 日志缓存刷新
+func (o *Observer) Flush() {}
+
+// 保存一条日志记录
+func (o *Observer) add(e Entry) {
+	// 字段切片由记录器复用，需要复制
+	e.Fields = append([]field.Field(nil), e.Fields...)
+	o.mutex.Lock()
+	o.entries = append(o.entries, e)
+	o.mutex.Unlock()
+}
+
+// Len 获取捕获的日志条数
+func (o *Observer) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.entries)
+}
+
+// All 获取全部捕获的日志记录
+func (o *Observer) All() []Entry {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]Entry(nil), o.entries...)
+}
+
+// TakeAll 获取并清空全部捕获的日志记录
+func (o *Observer) TakeAll() []Entry {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Reset 清空捕获的日志记录
+func (o *Observer) Reset() {
+	o.mutex.Lock()
+	o.entries = nil
+	o.mutex.Unlock()
+}
+
+// Filter 获取匹配的日志记录
+//
+//	@param	match	匹配函数
+//	@return	匹配的日志记录
+func (o *Observer) Filter(match func(Entry) bool) []Entry {
+	var entries []Entry
+	for _, e := range o.All() {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// FilterLevel 获取指定级别的日志记录
+func (o *Observer) FilterLevel(level logger.Level) []Entry {
+	return o.Filter(func(e Entry) bool { return e.Level == level })
+}
+
+// FilterMessage 获取指定消息的日志记录
+func (o *Observer) FilterMessage(msg string) []Entry {
+	return o.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// FilterField 获取包含指定字段的日志记录
+func (o *Observer) FilterField(f field.Field) []Entry {
+	return o.Filter(func(e Entry) bool { return e.hasFields([]field.Field{f}) })
+}
+
+// 日志观察编码器（保存日志记录，不生成日志内容）
+type observerEncoder Observer
+
+// Encode 编码输出方法
+func (e *observerEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	(*Observer)(e).add(Entry{Time: t, Level: l, Message: msg, Fields: val})
+	return dst
+}
+
+// EncodeStack 含调用栈编码输出方法
+func (e *observerEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	(*Observer)(e).add(Entry{Time: t, Level: l, Message: msg, Fields: val, FileName: fn, LineNo: ln, FuncName: mn})
+	return dst
+}
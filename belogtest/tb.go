@@ -0,0 +1,91 @@
+/**
+ *@Title 测试输出适配器
+ *@Desc 通过testing.TB的Log方法输出日志，使日志归属于对应的测试并仅在失败或-v时显示
+ */
+
+package belogtest
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// TBName 测试输出适配器名称
+const TBName = "belog-testing-adapter"
+
+// TBAdapter 测试输出适配器
+type TBAdapter struct {
+	tb   testing.TB // 测试实例
+	done int32      // 测试是否已结束（原子操作）
+}
+
+// NewTB 创建测试输出适配器
+//
+//	测试结束后输出的日志将被丢弃，避免在测试结束后调用t.Log引发panic
+//
+//	@param	tb	测试实例
+//	@return	适配器实例
+func NewTB(tb testing.TB) logger.Adapter {
+	e := &TBAdapter{tb: tb}
+	tb.Cleanup(func() { atomic.StoreInt32(&e.done, 1) })
+	return e
+}
+
+// Name 用于获取适配器名称
+func (e *TBAdapter) Name() string {
+	return TBName
+}
+
+// Print 普通日志打印方法
+//
+//	@param	logTime	日记记录时间
+//	@param	level	日志级别
+//	@param	content	日志内容
+func (e *TBAdapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	if atomic.LoadInt32(&e.done) == 1 {
+		return
+	}
+	e.tb.Helper()
+	e.tb.Log(string(bytes.TrimRight(content, "\r\n")))
+}
+
+// PrintStack 调用栈日志打印方法
+//
+//	@param	logTime		日记记录时间
+//	@param	level		日志级别
+//	@param	content		日志内容
+//	@param	fileName	日志记录调用文件路径
+//	@param	lineNo		日志记录调用文件行号
+//	@param	methodName	日志记录调用函数名
+func (e *TBAdapter) PrintStack(t time.Time, l logger.Level, content []byte, _ string, _ int, _ string) {
+	e.Print(t, l, content)
+}
+
+// Flush 日志缓存刷新
+func (e *TBAdapter) Flush() {}
+
+// NewLogger 创建用于测试的记录器
+//
+//	日志同时输出到测试实例（普通行格式）并由返回的日志观察适配器捕获，
+//	已启用调用栈打印
+//
+//	@param	tb	测试实例
+//	@return	记录器实例
+//	@return	日志观察适配器
+func NewLogger(tb testing.TB) (logger.Logger, *Observer) {
+	tb.Helper()
+	observer := NewObserver()
+	l, err := logger.New(logger.Option{
+		EnabledStackPrint: true,
+		Encoder:           encoder.NewNormalEncoder(encoder.DefaultNormalOption),
+	}, NewTB(tb), observer)
+	if err != nil {
+		tb.Fatalf("belog logger create failed, %s", err)
+	}
+	return l, observer
+}
@@ -0,0 +1,101 @@
+/**
+ *@Title 加密日志解密工具
+ *@Desc 将文件日志适配器加密的日志分段解密后输出到标准输出
+ */
+
+// belog-decrypt 加密日志解密工具
+//
+//	用法：belog-decrypt -key 1:<hex> [-key 2:<hex> ...] app.2021-09-21.1.log [...]
+//
+//	轮换过密钥的日志分段需要提供所有用到的密钥，
+//	分段末尾存在不完整的分块时会输出警告，此前的内容仍会正常输出
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bearki/belog/v3/pkg/crypt"
+)
+
+// 命令行传入的密钥集合
+type keyFlags map[uint32][]byte
+
+// String 实现flag.Value接口
+func (k keyFlags) String() string {
+	return ""
+}
+
+// Set 解析id:hex格式的密钥
+func (k keyFlags) Set(s string) error {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return errors.New("key must be in the form id:hex")
+	}
+	id, err := strconv.ParseUint(s[:i], 10, 32)
+	if err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(s[i+1:])
+	if err != nil {
+		return err
+	}
+	k[uint32(id)] = key
+	return nil
+}
+
+// CurrentKey 解密时不需要当前密钥
+func (k keyFlags) CurrentKey() (uint32, []byte, error) {
+	return 0, nil, errors.New("not supported")
+}
+
+// Key 根据密钥ID获取密钥
+func (k keyFlags) Key(id uint32) ([]byte, error) {
+	if key, ok := k[id]; ok {
+		return key, nil
+	}
+	return nil, errors.New("missing key id " + strconv.FormatUint(uint64(id), 10))
+}
+
+// 解密单个日志分段
+func decrypt(fileName string, kp crypt.KeyProvider, w io.Writer) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, crypt.NewReader(file, kp))
+	return err
+}
+
+func main() {
+	keys := make(keyFlags)
+	flag.Var(keys, "key", "decryption key in the form id:hex, can be repeated")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: belog-decrypt -key id:hex [-key id:hex ...] file [file ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if len(keys) == 0 || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code := 0
+	for _, fileName := range flag.Args() {
+		err := decrypt(fileName, keys, os.Stdout)
+		if errors.Is(err, crypt.ErrTruncated) {
+			fmt.Fprintln(os.Stderr, "warning: "+fileName+": "+err.Error())
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, "error: "+fileName+": "+err.Error())
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
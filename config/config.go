@@ -0,0 +1,410 @@
+/**
+ *@Title belog配置文件
+ *@Desc 根据声明式配置文档（JSON）及BELOG_*环境变量创建完整的日志记录器，配置有误时返回指向出错配置项的异常
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// Error 配置异常
+type Error struct {
+	Key string // 出错的配置项路径（如 adapters[1].options.maxSize），文档格式错误时为空
+	Err error  // 异常原因
+}
+
+// Error 获取异常信息
+func (e *Error) Error() string {
+	if len(e.Key) == 0 {
+		return "belog config: " + e.Err.Error()
+	}
+	return "belog config: " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap 获取异常原因
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// 创建配置异常（已是配置异常时保留原路径）
+func newError(key string, err error) error {
+	var ce *Error
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &Error{Key: key, Err: err}
+}
+
+// Component 适配器或编码器配置
+//
+//	配置文档格式：{"type": "file", "options": {"logPath": "./logs/app.log"}}
+type Component struct {
+	Type    string          `json:"type"`    // 类型名称（需已注册）
+	Options json.RawMessage `json:"options"` // 参数（由构造器解码）
+
+	path string // 配置项路径
+}
+
+// Decode 解码参数
+//
+//	参数键名不区分大小写，未知的参数及类型错误将返回指向出错配置项的异常
+//
+//	@param	v	参数结构体指针（解码前可预先填充默认值）
+//	@return	异常信息
+func (c Component) Decode(v interface{}) error {
+	if len(c.Options) == 0 || bytes.Equal(c.Options, []byte("null")) {
+		return nil
+	}
+	key := c.path + ".options"
+	d := json.NewDecoder(bytes.NewReader(c.Options))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return &Error{Key: jsonErrorKey(key, err), Err: err}
+	}
+	return nil
+}
+
+// 获取JSON解码异常对应的配置项路径
+func jsonErrorKey(key string, err error) string {
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) && len(te.Field) > 0 {
+		return joinKey(key, te.Field)
+	}
+	// 未知字段：json: unknown field "xxx"
+	const unknownField = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, unknownField) {
+		return joinKey(key, strings.Trim(msg[len(unknownField):], `"`))
+	}
+	return key
+}
+
+// AsyncConfig 异步输出配置
+type AsyncConfig struct {
+	Enabled   bool   `json:"enabled"`
+	QueueSize uint   `json:"queueSize"`
+	Overflow  string `json:"overflow"` // block（默认）、dropNewest、dropOldest
+}
+
+// SamplingConfig 日志采样配置
+type SamplingConfig struct {
+	Enabled    bool `json:"enabled"`
+	Tick       uint `json:"tick"`
+	First      uint `json:"first"`
+	Thereafter uint `json:"thereafter"`
+}
+
+// DedupConfig 重复日志折叠配置
+type DedupConfig struct {
+	Enabled bool   `json:"enabled"`
+	Window  uint   `json:"window"`
+	Key     string `json:"key"` // content（默认）、caller
+}
+
+// Config 日志记录器配置
+type Config struct {
+	Level    string         `json:"level"`    // 最小记录级别（Default: trace）
+	Skip     uint           `json:"skip"`     // 需要额外跳过的调用栈层数
+	Stack    bool           `json:"stack"`    // 是否记录调用栈
+	Encoder  *Component     `json:"encoder"`  // 编码器（Default: json）
+	Async    AsyncConfig    `json:"async"`    // 异步输出
+	Sampling SamplingConfig `json:"sampling"` // 日志采样
+	Dedup    DedupConfig    `json:"dedup"`    // 重复日志折叠
+	Adapters []Component    `json:"adapters"` // 适配器列表
+}
+
+// 各配置节允许的键
+var sectionKeys = map[string][]string{
+	"":         {"level", "skip", "stack", "encoder", "async", "sampling", "dedup", "adapters"},
+	"encoder":  {"type", "options"},
+	"async":    {"enabled", "queueSize", "overflow"},
+	"sampling": {"enabled", "tick", "first", "thereafter"},
+	"dedup":    {"enabled", "window", "key"},
+	"adapters": {"type", "options"},
+}
+
+// 校验配置节中的键（不区分大小写）
+func checkKeys(key string, section string, m map[string]interface{}) error {
+	allowed := sectionKeys[section]
+	for k := range m {
+		ok := false
+		for _, a := range allowed {
+			if strings.EqualFold(k, a) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return &Error{Key: joinKey(key, k), Err: errors.New("unknown key")}
+		}
+	}
+	return nil
+}
+
+// 拼接配置项路径
+func joinKey(parent string, key string) string {
+	if len(parent) == 0 {
+		return key
+	}
+	return parent + "." + key
+}
+
+// 获取配置节（不区分大小写）
+func lookupKey(m map[string]interface{}, key string) (string, interface{}, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// 校验配置文档结构
+func validate(doc map[string]interface{}) error {
+	if err := checkKeys("", "", doc); err != nil {
+		return err
+	}
+	for _, section := range []string{"encoder", "async", "sampling", "dedup"} {
+		k, v, ok := lookupKey(doc, section)
+		if !ok || v == nil {
+			continue
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return &Error{Key: k, Err: errors.New("must be an object")}
+		}
+		if err := checkKeys(k, section, m); err != nil {
+			return err
+		}
+	}
+	if k, v, ok := lookupKey(doc, "adapters"); ok && v != nil {
+		list, ok := v.([]interface{})
+		if !ok {
+			return &Error{Key: k, Err: errors.New("must be an array")}
+		}
+		for i, item := range list {
+			key := fmt.Sprintf("%s[%d]", k, i)
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return &Error{Key: key, Err: errors.New("must be an object")}
+			}
+			if err := checkKeys(key, "adapters", m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 解析JSON配置文档
+func parseJSON(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, &Error{Err: err}
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	return doc, nil
+}
+
+// Parse 解析配置文档
+//
+//	@param	doc	配置文档（由JSON、YAML等格式解析得到，键为字符串）
+//	@param	env	是否应用BELOG_*环境变量覆盖（将直接修改doc）
+//	@return	日志记录器配置
+//	@return	异常信息
+func Parse(doc map[string]interface{}, env bool) (*Config, error) {
+	// 应用环境变量覆盖
+	if env {
+		if err := applyEnv(doc, os.Environ()); err != nil {
+			return nil, err
+		}
+	}
+
+	// 校验文档结构
+	if err := validate(doc); err != nil {
+		return nil, err
+	}
+
+	// 解码配置
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	c := new(Config)
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, &Error{Key: jsonErrorKey("", err), Err: err}
+	}
+	return c, nil
+}
+
+// New 根据JSON配置文档创建日志记录器
+//
+//	BELOG_*环境变量将覆盖文档中的配置
+//
+//	@param	data	JSON配置文档
+//	@return	日志记录器实例
+//	@return	异常信息
+func New(data []byte) (logger.Logger, error) {
+	doc, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromMap(doc)
+}
+
+// NewFromFile 根据JSON配置文件创建日志记录器
+//
+//	@param	path	配置文件路径
+//	@return	日志记录器实例
+//	@return	异常信息
+func NewFromFile(path string) (logger.Logger, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(data)
+}
+
+// NewFromMap 根据已解析的配置文档创建日志记录器
+//
+//	用于支持其他文档格式，BELOG_*环境变量将覆盖文档中的配置
+//
+//	@param	doc	配置文档（键为字符串，值为JSON兼容类型）
+//	@return	日志记录器实例
+//	@return	异常信息
+func NewFromMap(doc map[string]interface{}) (logger.Logger, error) {
+	c, err := Parse(doc, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.Build()
+}
+
+// 关闭已创建的适配器（配置有误时释放连接、协程等资源）
+func closeAdapters(adapters []logger.Adapter) {
+	for _, a := range adapters {
+		a.Flush()
+		if c, ok := a.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
+
+// Build 创建日志记录器
+//
+//	@return	日志记录器实例
+//	@return	异常信息
+func (c *Config) Build() (logger.Logger, error) {
+	option := logger.Option{EnabledStackPrint: c.Stack}
+
+	// 日志级别
+	level := logger.Trace
+	if len(c.Level) > 0 {
+		var err error
+		if level, err = logger.ParseLevel(c.Level); err != nil {
+			return nil, &Error{Key: "level", Err: err}
+		}
+	}
+
+	// 编码器
+	if c.Encoder == nil {
+		option.Encoder = encoder.NewJsonEncoder(encoder.DefaultJsonOption)
+	} else {
+		c.Encoder.path = "encoder"
+		factory, ok := lookupEncoder(c.Encoder.Type)
+		if !ok {
+			return nil, &Error{Key: "encoder.type", Err: fmt.Errorf("unknown encoder type %q", c.Encoder.Type)}
+		}
+		e, err := factory(*c.Encoder)
+		if err != nil {
+			return nil, newError("encoder", err)
+		}
+		option.Encoder = e
+	}
+
+	// 异步输出
+	if c.Async.Enabled {
+		option.Async = logger.AsyncOption{Enabled: true, QueueSize: c.Async.QueueSize}
+		switch strings.ToLower(c.Async.Overflow) {
+		case "", "block":
+			option.Async.Overflow = logger.OverflowBlock
+		case "dropnewest":
+			option.Async.Overflow = logger.OverflowDropNewest
+		case "dropoldest":
+			option.Async.Overflow = logger.OverflowDropOldest
+		default:
+			return nil, &Error{Key: "async.overflow", Err: fmt.Errorf("unknown overflow policy %q", c.Async.Overflow)}
+		}
+	}
+
+	// 日志采样
+	if c.Sampling.Enabled {
+		option.Sampling = logger.SamplingOption{
+			Enabled:    true,
+			Tick:       c.Sampling.Tick,
+			First:      c.Sampling.First,
+			Thereafter: c.Sampling.Thereafter,
+		}
+	}
+
+	// 重复日志折叠
+	if c.Dedup.Enabled {
+		option.Dedup = logger.DedupOption{Enabled: true, Window: c.Dedup.Window}
+		switch strings.ToLower(c.Dedup.Key) {
+		case "", "content":
+			option.Dedup.Key = logger.DedupByContent
+		case "caller":
+			option.Dedup.Key = logger.DedupByCaller
+		default:
+			return nil, &Error{Key: "dedup.key", Err: fmt.Errorf("unknown dedup key %q", c.Dedup.Key)}
+		}
+	}
+
+	// 适配器
+	adapters := make([]logger.Adapter, 0, len(c.Adapters))
+	names := make(map[string]bool, len(c.Adapters))
+	for i, ac := range c.Adapters {
+		ac.path = fmt.Sprintf("adapters[%d]", i)
+		factory, ok := lookupAdapter(ac.Type)
+		if !ok {
+			closeAdapters(adapters)
+			return nil, &Error{Key: ac.path + ".type", Err: fmt.Errorf("unknown adapter type %q", ac.Type)}
+		}
+		a, err := factory(ac)
+		if err != nil {
+			closeAdapters(adapters)
+			return nil, newError(ac.path, err)
+		}
+		if names[a.Name()] {
+			closeAdapters(append(adapters, a))
+			return nil, &Error{Key: ac.path, Err: fmt.Errorf("duplicate adapter name %q", a.Name())}
+		}
+		names[a.Name()] = true
+		adapters = append(adapters, a)
+	}
+
+	// 创建日志记录器
+	l, err := logger.New(option, adapters...)
+	if err != nil {
+		closeAdapters(adapters)
+		return nil, err
+	}
+	l.SetLevel(level)
+	l.SetSkip(c.Skip)
+	return l, nil
+}
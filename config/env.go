@@ -0,0 +1,217 @@
+/**
+ *@Title 环境变量覆盖
+ *@Desc 使用BELOG_*环境变量覆盖配置文档中的配置项，便于在不修改配置文件的情况下调整线上日志
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix 环境变量前缀
+//
+//	支持的环境变量（配置项名称中的下划线将被忽略，如QUEUE_SIZE对应queueSize）：
+//
+//	BELOG_LEVEL、BELOG_SKIP、BELOG_STACK
+//	BELOG_ENCODER_TYPE、BELOG_ENCODER_<参数>
+//	BELOG_ASYNC_<配置项>、BELOG_SAMPLING_<配置项>、BELOG_DEDUP_<配置项>
+//	BELOG_ADAPTERS_<适配器名称>_<参数>（适配器名称取options.name，未设置时取type，非字母数字字符替换为下划线）
+//
+//	环境变量的值为合法JSON时按JSON解析（数字、布尔值、数组、对象），否则作为字符串
+const EnvPrefix = "BELOG_"
+
+// 顶层配置项对应的环境变量
+var envTopKeys = map[string]string{
+	"LEVEL": "level",
+	"SKIP":  "skip",
+	"STACK": "stack",
+}
+
+// 配置节对应的环境变量前缀
+var envSections = map[string]string{
+	"ASYNC_":    "async",
+	"SAMPLING_": "sampling",
+	"DEDUP_":    "dedup",
+}
+
+// 解析环境变量的值
+func parseEnvValue(s string) interface{} {
+	if !json.Valid([]byte(s)) {
+		return s
+	}
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return s
+	}
+	return v
+}
+
+// 环境变量名称转为配置项名称（忽略下划线，解码时不区分大小写）
+func envKey(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// 名称转为环境变量形式（大写，非字母数字字符替换为下划线）
+func envName(s string) string {
+	b := []byte(strings.ToUpper(s))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// 设置配置项（已存在时不区分大小写覆盖）
+func setKey(m map[string]interface{}, key string, v interface{}) {
+	if k, _, ok := lookupKey(m, key); ok {
+		m[k] = v
+		return
+	}
+	m[key] = v
+}
+
+// 获取子对象（不存在时创建）
+func childMap(m map[string]interface{}, key string, path string) (map[string]interface{}, error) {
+	k, v, ok := lookupKey(m, key)
+	if !ok || v == nil {
+		child := make(map[string]interface{})
+		if !ok {
+			k = key
+		}
+		m[k] = child
+		return child, nil
+	}
+	child, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, &Error{Key: joinKey(path, k), Err: errors.New("must be an object")}
+	}
+	return child, nil
+}
+
+// 获取适配器的环境变量名称
+func adapterEnvName(m map[string]interface{}) string {
+	if _, opts, ok := lookupKey(m, "options"); ok {
+		if om, ok := opts.(map[string]interface{}); ok {
+			if _, name, ok := lookupKey(om, "name"); ok {
+				if s, ok := name.(string); ok && len(s) > 0 {
+					return envName(s)
+				}
+			}
+		}
+	}
+	if _, typ, ok := lookupKey(m, "type"); ok {
+		if s, ok := typ.(string); ok {
+			return envName(s)
+		}
+	}
+	return ""
+}
+
+// 应用单个环境变量
+func applyEnvVar(doc map[string]interface{}, name string, value string) error {
+	suffix := strings.ToUpper(name[len(EnvPrefix):])
+	v := parseEnvValue(value)
+
+	// 顶层配置项
+	if key, ok := envTopKeys[suffix]; ok {
+		setKey(doc, key, v)
+		return nil
+	}
+
+	// 编码器
+	if strings.HasPrefix(suffix, "ENCODER_") {
+		m, err := childMap(doc, "encoder", "")
+		if err != nil {
+			return err
+		}
+		rest := suffix[len("ENCODER_"):]
+		if rest == "TYPE" {
+			setKey(m, "type", v)
+			return nil
+		}
+		opts, err := childMap(m, "options", "encoder")
+		if err != nil {
+			return err
+		}
+		setKey(opts, envKey(rest), v)
+		return nil
+	}
+
+	// 异步输出、日志采样、重复日志折叠
+	for prefix, section := range envSections {
+		if strings.HasPrefix(suffix, prefix) {
+			m, err := childMap(doc, section, "")
+			if err != nil {
+				return err
+			}
+			setKey(m, envKey(suffix[len(prefix):]), v)
+			return nil
+		}
+	}
+
+	// 适配器参数
+	if strings.HasPrefix(suffix, "ADAPTERS_") {
+		rest := suffix[len("ADAPTERS_"):]
+		k, list, _ := lookupKey(doc, "adapters")
+		items, _ := list.([]interface{})
+		// 匹配名称最长的适配器
+		index, match := -1, ""
+		for i, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			n := adapterEnvName(m)
+			if len(n) > len(match) && strings.HasPrefix(rest, n+"_") {
+				index, match = i, n
+			}
+		}
+		if index < 0 {
+			return &Error{Key: name, Err: errors.New("no adapter matches the environment variable")}
+		}
+		path := fmt.Sprintf("%s[%d]", k, index)
+		opts, err := childMap(items[index].(map[string]interface{}), "options", path)
+		if err != nil {
+			return err
+		}
+		setKey(opts, envKey(rest[len(match)+1:]), v)
+		return nil
+	}
+
+	return &Error{Key: name, Err: errors.New("unknown environment variable")}
+}
+
+// 应用环境变量覆盖
+//
+//	@param	doc		配置文档
+//	@param	environ	环境变量列表（key=value）
+//	@return	异常信息
+func applyEnv(doc map[string]interface{}, environ []string) error {
+	// 按名称排序，保证覆盖顺序一致
+	vars := make([]string, 0, 8)
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, EnvPrefix) {
+			vars = append(vars, kv)
+		}
+	}
+	sort.Strings(vars)
+
+	for _, kv := range vars {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+		if err := applyEnvVar(doc, kv[:i], kv[i+1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/**
+ *@Title 适配器及编码器构造器注册表
+ *@Desc 按类型名称注册适配器及编码器的构造器，第三方适配器可在init中注册后通过配置文件创建
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bearki/belog/v3/adapter/console"
+	"github.com/bearki/belog/v3/adapter/discard"
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/adapter/gelf"
+	"github.com/bearki/belog/v3/adapter/http"
+	"github.com/bearki/belog/v3/adapter/journald"
+	"github.com/bearki/belog/v3/adapter/netlog"
+	"github.com/bearki/belog/v3/adapter/ring"
+	"github.com/bearki/belog/v3/adapter/syslog"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// AdapterFactory 适配器构造器
+//
+//	@param	c	适配器配置（使用c.Decode解码适配器参数）
+//	@return	适配器实例
+//	@return	异常信息
+type AdapterFactory func(c Component) (logger.Adapter, error)
+
+// EncoderFactory 编码器构造器
+//
+//	@param	c	编码器配置（使用c.Decode解码编码器参数）
+//	@return	编码器实例
+//	@return	异常信息
+type EncoderFactory func(c Component) (logger.Encoder, error)
+
+// 构造器注册表
+var registry = struct {
+	mutex    sync.RWMutex
+	adapters map[string]AdapterFactory
+	encoders map[string]EncoderFactory
+}{
+	adapters: make(map[string]AdapterFactory),
+	encoders: make(map[string]EncoderFactory),
+}
+
+// RegisterAdapter 注册适配器构造器
+//
+//	@param	typ		适配器类型名称（不区分大小写）
+//	@param	factory	适配器构造器
+//	@return	异常信息
+func RegisterAdapter(typ string, factory AdapterFactory) error {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if len(typ) == 0 || factory == nil {
+		return errors.New("the adapter type and factory cannot be empty")
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if _, ok := registry.adapters[typ]; ok {
+		return fmt.Errorf("the adapter type %q is already registered", typ)
+	}
+	registry.adapters[typ] = factory
+	return nil
+}
+
+// RegisterEncoder 注册编码器构造器
+//
+//	@param	typ		编码器类型名称（不区分大小写）
+//	@param	factory	编码器构造器
+//	@return	异常信息
+func RegisterEncoder(typ string, factory EncoderFactory) error {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if len(typ) == 0 || factory == nil {
+		return errors.New("the encoder type and factory cannot be empty")
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if _, ok := registry.encoders[typ]; ok {
+		return fmt.Errorf("the encoder type %q is already registered", typ)
+	}
+	registry.encoders[typ] = factory
+	return nil
+}
+
+// 获取适配器构造器
+func lookupAdapter(typ string) (AdapterFactory, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	f, ok := registry.adapters[strings.ToLower(strings.TrimSpace(typ))]
+	return f, ok
+}
+
+// 获取编码器构造器
+func lookupEncoder(typ string) (EncoderFactory, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	f, ok := registry.encoders[strings.ToLower(strings.TrimSpace(typ))]
+	return f, ok
+}
+
+// 注册内置适配器及编码器
+//
+//	参数中的接口类型字段（如io.Writer、*tls.Config）无法通过配置文件设置，将使用默认值
+func init() {
+	// 编码器
+	_ = RegisterEncoder("json", func(c Component) (logger.Encoder, error) {
+		opt := encoder.DefaultJsonOption
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return encoder.NewJsonEncoder(opt), nil
+	})
+	_ = RegisterEncoder("normal", func(c Component) (logger.Encoder, error) {
+		opt := encoder.DefaultNormalOption
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return encoder.NewNormalEncoder(opt), nil
+	})
+	_ = RegisterEncoder("pretty", func(c Component) (logger.Encoder, error) {
+		opt := encoder.DefaultPrettyOption
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return encoder.NewPrettyEncoder(opt), nil
+	})
+
+	// 适配器
+	_ = RegisterAdapter("console", func(c Component) (logger.Adapter, error) {
+		var opt console.Option
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return console.New(opt), nil
+	})
+	_ = RegisterAdapter("discard", func(c Component) (logger.Adapter, error) {
+		if err := c.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return discard.New(), nil
+	})
+	_ = RegisterAdapter("file", func(c Component) (logger.Adapter, error) {
+		var opt file.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return file.New(opt)
+	})
+	_ = RegisterAdapter("gelf", func(c Component) (logger.Adapter, error) {
+		var opt gelf.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return gelf.New(opt)
+	})
+	_ = RegisterAdapter("http", func(c Component) (logger.Adapter, error) {
+		var opt http.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return http.New(opt)
+	})
+	_ = RegisterAdapter("journald", func(c Component) (logger.Adapter, error) {
+		var opt journald.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return journald.New(opt)
+	})
+	_ = RegisterAdapter("net", func(c Component) (logger.Adapter, error) {
+		var opt netlog.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return netlog.New(opt)
+	})
+	_ = RegisterAdapter("ring", func(c Component) (logger.Adapter, error) {
+		var opt ring.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return ring.New(opt), nil
+	})
+	_ = RegisterAdapter("syslog", func(c Component) (logger.Adapter, error) {
+		var opt syslog.Options
+		if err := c.Decode(&opt); err != nil {
+			return nil, err
+		}
+		return syslog.New(opt)
+	})
+}
@@ -0,0 +1,8 @@
+module github.com/bearki/belog/v3/config/yaml
+
+go 1.16
+
+require (
+	github.com/bearki/belog/v3 v3.1.0
+	gopkg.in/yaml.v3 v3.0.1
+)
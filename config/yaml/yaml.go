@@ -0,0 +1,112 @@
+/**
+ *@Title belog YAML配置文件
+ *@Desc 将YAML配置文档转为通用配置文档后交由config包创建日志记录器，该包为独立模块，仅在需要YAML时引入YAML依赖
+ */
+
+package yaml
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/bearki/belog/v3/config"
+	"github.com/bearki/belog/v3/logger"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Parse 解析YAML配置文档
+//
+//	@param	data	YAML配置文档
+//	@return	配置文档（键为字符串，值为JSON兼容类型）
+//	@return	异常信息
+func Parse(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, &config.Error{Err: err}
+	}
+	if doc == nil {
+		return make(map[string]interface{}), nil
+	}
+	v, err := normalize("", doc)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+// 将YAML解码结果转为JSON兼容类型（对象的键必须为字符串）
+func normalize(path string, v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			n, err := normalize(joinKey(path, k), item)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = n
+		}
+		return val, nil
+
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			key, ok := k.(string)
+			if !ok {
+				return nil, &config.Error{Key: path, Err: fmt.Errorf("key %v must be a string", k)}
+			}
+			n, err := normalize(joinKey(path, key), item)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = n
+		}
+		return m, nil
+
+	case []interface{}:
+		for i, item := range val {
+			n, err := normalize(fmt.Sprintf("%s[%d]", path, i), item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = n
+		}
+		return val, nil
+	}
+	return v, nil
+}
+
+// 拼接配置项路径
+func joinKey(parent string, key string) string {
+	if len(parent) == 0 {
+		return key
+	}
+	return parent + "." + key
+}
+
+// New 根据YAML配置文档创建日志记录器
+//
+//	BELOG_*环境变量将覆盖文档中的配置
+//
+//	@param	data	YAML配置文档
+//	@return	日志记录器实例
+//	@return	异常信息
+func New(data []byte) (logger.Logger, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewFromMap(doc)
+}
+
+// NewFromFile 根据YAML配置文件创建日志记录器
+//
+//	@param	path	配置文件路径
+//	@return	日志记录器实例
+//	@return	异常信息
+func NewFromFile(path string) (logger.Logger, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(data)
+}
@@ -0,0 +1,84 @@
+package yaml_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/config"
+	"github.com/bearki/belog/v3/config/yaml"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 注册测试用观察者适配器
+var testObserver = belogtest.NewObserver()
+
+func init() {
+	_ = config.RegisterAdapter("test-observer", func(c config.Component) (logger.Adapter, error) {
+		if err := c.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return testObserver, nil
+	})
+}
+
+// 设置环境变量，返回恢复函数
+func setEnv(kv ...string) func() {
+	for i := 0; i < len(kv); i += 2 {
+		_ = os.Setenv(kv[i], kv[i+1])
+	}
+	return func() {
+		for i := 0; i < len(kv); i += 2 {
+			_ = os.Unsetenv(kv[i])
+		}
+	}
+}
+
+// TestConfigYAML 测试YAML配置及环境变量覆盖
+func TestConfigYAML(t *testing.T) {
+	defer setEnv(
+		"BELOG_LEVEL", "error",
+		"BELOG_ADAPTERS_RECENT_LOGS_SIZE", "2",
+		"BELOG_ASYNC_QUEUE_SIZE", "64",
+	)()
+
+	testObserver.Reset()
+	l, err := yaml.New([]byte(`
+level: trace
+async:
+  enabled: true
+adapters:
+  - type: test-observer
+  - type: ring
+    options:
+      name: recent-logs
+      size: 100
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.(logger.Closer).Close()
+	l.Warn("hidden")
+	for i := 0; i < 3; i++ {
+		l.Error("shown")
+	}
+	l.Flush()
+	testObserver.AssertCount(t, logger.Warn, 0)
+	testObserver.AssertCount(t, logger.Error, 3)
+}
+
+// TestConfigYAMLErrors 测试YAML配置异常
+func TestConfigYAMLErrors(t *testing.T) {
+	for doc, key := range map[string]string{
+		"level: [":                   "",
+		"1: info":                    "1",
+		"async:\n  queueSize: big\n": "async.queueSize",
+	} {
+		_, err := yaml.New([]byte(doc))
+		var ce *config.Error
+		if !errors.As(err, &ce) || ce.Key != key {
+			t.Errorf("%q: expected config error at %q, got %v", doc, key, err)
+		}
+	}
+}
@@ -68,7 +68,8 @@ var DefaultJsonOption = JsonEncoderOption{
 
 // JsonEncoder JSON编码器
 type JsonEncoder struct {
-	opt JsonEncoderOption
+	opt   JsonEncoderOption
+	theme *Theme // 配色主题（可通过WithTheme设置）
 }
 
 // 检查JSON编码器参数有效性
@@ -129,7 +130,8 @@ func NewJsonEncoder(opt JsonEncoderOption) logger.Encoder {
 	opt = checkJsonOptionValid(opt)
 	// 创建编码器
 	return &JsonEncoder{
-		opt: opt,
+		opt:   opt,
+		theme: noTheme,
 	}
 }
 
@@ -144,12 +146,12 @@ func NewJsonEncoder(opt JsonEncoderOption) logger.Encoder {
 func (e *JsonEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
 	// 开始追加内容
 	dst = append(dst, '{')
-	dst = appendTimeJSON(dst, e.opt.TimeKey, t, e.opt.TimeFormat)
+	dst = appendTimeJSON(dst, e.theme, e.opt.TimeKey, t, e.opt.TimeFormat)
 	dst = append(dst, `, `...)
-	dst = appendLevelJSON(dst, e.opt.LevelKey, l, e.opt.LevelFormat)
+	dst = appendLevelJSON(dst, e.theme, e.opt.LevelKey, l, e.opt.LevelFormat)
 	dst = append(dst, `, `...)
 	// 追加消息和字段内容
-	dst = appendFieldAndMsgJSON(dst, e.opt.MsgKey, msg, e.opt.FieldsKey, val...)
+	dst = appendFieldAndMsgJSON(dst, e.theme, e.opt.MsgKey, msg, e.opt.FieldsKey, val...)
 	dst = append(dst, "}\r\n"...)
 	// 追加完成
 	return dst
@@ -169,20 +171,20 @@ func (e *JsonEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string
 func (e *JsonEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
 	// 开始追加内容
 	dst = append(dst, '{')
-	dst = appendTimeJSON(dst, e.opt.TimeKey, t, e.opt.TimeFormat)
+	dst = appendTimeJSON(dst, e.theme, e.opt.TimeKey, t, e.opt.TimeFormat)
 	dst = append(dst, `, `...)
-	dst = appendLevelJSON(dst, e.opt.LevelKey, l, e.opt.LevelFormat)
+	dst = appendLevelJSON(dst, e.theme, e.opt.LevelKey, l, e.opt.LevelFormat)
 	dst = append(dst, `, `...)
 	// 追加调用栈
 	dst = appendStackJSON(
-		dst, e.opt.StackFileFormat, e.opt.StackKey,
+		dst, e.theme, e.opt.StackFileFormat, e.opt.StackKey,
 		e.opt.StackFileKey, fn,
 		e.opt.StackLineNoKey, ln,
 		e.opt.StackMethodKey, mn,
 	)
 	dst = append(dst, `, `...)
 	// 追加消息和字段内容
-	dst = appendFieldAndMsgJSON(dst, e.opt.MsgKey, msg, e.opt.FieldsKey, val...)
+	dst = appendFieldAndMsgJSON(dst, e.theme, e.opt.MsgKey, msg, e.opt.FieldsKey, val...)
 	dst = append(dst, "}\r\n"...)
 	// 追加完成
 	return dst
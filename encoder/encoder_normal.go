@@ -19,7 +19,8 @@ var DefaultNormalOption = NormalEncoderOption{
 
 // NormalEncoder 普通编码器
 type NormalEncoder struct {
-	opt NormalEncoderOption
+	opt   NormalEncoderOption
+	theme *Theme // 配色主题（可通过WithTheme设置）
 }
 
 // 检查普通编码器参数有效性
@@ -39,7 +40,8 @@ func NewNormalEncoder(opt NormalEncoderOption) logger.Encoder {
 	opt = checkNormalOptionValid(opt)
 	// 创建编码器
 	return &NormalEncoder{
-		opt: opt,
+		opt:   opt,
+		theme: noTheme,
 	}
 }
 
@@ -53,12 +55,12 @@ func NewNormalEncoder(opt NormalEncoderOption) logger.Encoder {
 //	@return	填充后的内容
 func (e *NormalEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
 	// 开始追加内容
-	dst = appendTime(dst, t, e.opt.TimeFormat)
+	dst = appendTime(dst, e.theme, t, e.opt.TimeFormat)
 	dst = append(dst, ' ')
-	dst = appendLevel(dst, l, e.opt.LevelFormat)
+	dst = appendLevel(dst, e.theme, l, e.opt.LevelFormat)
 	// 追加消息和字段内容
 	dst = append(dst, ' ', ' ')
-	dst = appendFieldAndMsg(dst, msg, val...)
+	dst = appendFieldAndMsg(dst, e.theme, msg, val...)
 	dst = append(dst, "\r\n"...)
 	// 追加完成
 	return dst
@@ -77,15 +79,15 @@ func (e *NormalEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg stri
 //	@return 填充后的内容
 func (e *NormalEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
 	// 开始追加内容
-	dst = appendTime(dst, t, e.opt.TimeFormat)
+	dst = appendTime(dst, e.theme, t, e.opt.TimeFormat)
 	dst = append(dst, ' ')
-	dst = appendLevel(dst, l, e.opt.LevelFormat)
+	dst = appendLevel(dst, e.theme, l, e.opt.LevelFormat)
 	// 追加调用栈
 	dst = append(dst, ' ')
-	dst = appendStack(dst, e.opt.StackFileFormat, fn, ln, mn)
+	dst = appendStack(dst, e.theme, e.opt.StackFileFormat, fn, ln, mn)
 	// 追加消息和字段内容
 	dst = append(dst, ' ', ' ')
-	dst = appendFieldAndMsg(dst, msg, val...)
+	dst = appendFieldAndMsg(dst, e.theme, msg, val...)
 	dst = append(dst, "\r\n"...)
 	// 追加完成
 	return dst
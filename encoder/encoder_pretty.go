@@ -0,0 +1,390 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/convert"
+	"github.com/bearki/belog/v3/pkg/pool"
+)
+
+// PrettyEncoderOption 美化编码器参数
+//
+//	BaseOption.LevelFormat为true时日志级别徽标使用完整字符串，否则使用三字母缩写；
+//	BaseOption.StackFileFormat为true时调用位置使用完整路径，否则使用"包名/文件名:行号"
+type PrettyEncoderOption struct {
+	BaseOption
+
+	// 是否使用相对时间
+	//
+	// 开启后时间列显示为距编码器创建时的时长（如+12.345s），TimeFormat将被忽略
+	//
+	// Default: false
+	RelativeTime bool
+
+	// 禁用颜色输出
+	//
+	// Default: false
+	DisabledColor bool
+
+	// 调用位置列宽度
+	//
+	// Default: 24, Min: 0, Max: 200
+	CallerWidth int
+
+	// 日志描述列宽度
+	//
+	// 有字段时日志描述将补齐到该宽度，使字段对齐
+	//
+	// Default: 40, Min: 0, Max: 500
+	MessageWidth int
+}
+
+// DefaultPrettyOption 美化编码器默认参数
+var DefaultPrettyOption = PrettyEncoderOption{
+	BaseOption: BaseOption{
+		TimeFormat: "15:04:05.000",
+	},
+	CallerWidth:  24,
+	MessageWidth: 40,
+}
+
+// 美化编码器控制台样式
+const (
+	prettyStyleReset = "\x1b[0m"  // 重置
+	prettyStyleDim   = "\x1b[2m"  // 暗色
+	prettyStyleKey   = "\x1b[36m" // 字段键名
+)
+
+// 美化编码器多行内容的缩进
+const prettyIndent = "    "
+
+// PrettyEncoder 美化编码器
+//
+//	面向本地开发的可读格式，输出示例：
+//
+//	15:04:05.000  INF  main/main.go:42          server started        addr=:8080 pid=1234
+//	    err: dial tcp: connection refused
+//	        main.connect
+//	            /app/main.go:30
+type PrettyEncoder struct {
+	opt   PrettyEncoderOption
+	start time.Time // 编码器创建时间（用于计算相对时间）
+}
+
+// 检查美化编码器参数有效性
+func checkPrettyOptionValid(opt PrettyEncoderOption) PrettyEncoderOption {
+	// 美化编码器的默认时间格式与其他编码器不同
+	if len(opt.TimeFormat) == 0 {
+		opt.TimeFormat = DefaultPrettyOption.TimeFormat
+	}
+	// 检查基础参数有效性
+	opt.BaseOption = checkBaseOptionValid(opt.BaseOption)
+	// 检查列宽
+	if opt.CallerWidth <= 0 || opt.CallerWidth > 200 {
+		opt.CallerWidth = DefaultPrettyOption.CallerWidth
+	}
+	if opt.MessageWidth <= 0 || opt.MessageWidth > 500 {
+		opt.MessageWidth = DefaultPrettyOption.MessageWidth
+	}
+	// 检查完成
+	return opt
+}
+
+// NewPrettyEncoder 创建一个美化格式编码器
+//
+//	@param	opt	编码器参数
+//	@return	美化编码器
+func NewPrettyEncoder(opt PrettyEncoderOption) logger.Encoder {
+	// 检查参数有效性
+	opt = checkPrettyOptionValid(opt)
+	// 创建编码器
+	return &PrettyEncoder{
+		opt:   opt,
+		start: time.Now(),
+	}
+}
+
+// Encode 编码输出方法
+//
+//	@param	dst	填充目标
+//	@param	t	日志记录时间
+//	@param	l	日志级别
+//	@param	msg	日志描述
+//	@param	val	日志内容字段
+//	@return	填充后的内容
+func (e *PrettyEncoder) Encode(dst []byte, t time.Time, l logger.Level, msg string, val ...field.Field) []byte {
+	// 开始追加内容
+	dst = e.appendTime(dst, t)
+	dst = append(dst, ' ', ' ')
+	dst = e.appendLevel(dst, l)
+	dst = append(dst, ' ', ' ')
+	// 追加消息和字段内容
+	dst = e.appendFieldAndMsg(dst, msg, val...)
+	// 追加完成
+	return dst
+}
+
+// EncodeStack 含调用栈编码输出方法
+//
+//	@param	dst	填充目标
+//	@param	t	日志记录时间
+//	@param	l	日志级别
+//	@param	fn	调用栈文件名
+//	@param	ln	调用栈行号
+//	@param	mn	调用栈函数名
+//	@param	msg	日志描述
+//	@param	val	日志内容字段
+//	@return 填充后的内容
+func (e *PrettyEncoder) EncodeStack(dst []byte, t time.Time, l logger.Level, fn string, ln int, mn string, msg string, val ...field.Field) []byte {
+	// 开始追加内容
+	dst = e.appendTime(dst, t)
+	dst = append(dst, ' ', ' ')
+	dst = e.appendLevel(dst, l)
+	dst = append(dst, ' ', ' ')
+	// 追加调用位置
+	dst = e.appendCaller(dst, fn, ln)
+	dst = append(dst, ' ', ' ')
+	// 追加消息和字段内容
+	dst = e.appendFieldAndMsg(dst, msg, val...)
+	// 追加完成
+	return dst
+}
+
+// 美化编码器字段值缓冲区对象池
+var prettyBytesPool = pool.NewBytesPool(100, 0, 256)
+
+// 开始样式
+func (e *PrettyEncoder) beginStyle(dst []byte, style string) []byte {
+	if e.opt.DisabledColor {
+		return dst
+	}
+	return append(dst, style...)
+}
+
+// 结束样式
+func (e *PrettyEncoder) endStyle(dst []byte) []byte {
+	if e.opt.DisabledColor {
+		return dst
+	}
+	return append(dst, prettyStyleReset...)
+}
+
+// 追加带样式的内容
+func (e *PrettyEncoder) appendStyled(dst []byte, style string, s string) []byte {
+	dst = e.beginStyle(dst, style)
+	dst = append(dst, s...)
+	return e.endStyle(dst)
+}
+
+// 追加指定数量的空格
+func appendSpaces(dst []byte, n int) []byte {
+	for ; n > 0; n-- {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
+
+// 追加补齐到指定宽度的空格
+func appendPadding(dst []byte, s string, width int) []byte {
+	return appendSpaces(dst, width-utf8.RuneCountInString(s))
+}
+
+// 追加时间列
+func (e *PrettyEncoder) appendTime(dst []byte, t time.Time) []byte {
+	dst = e.beginStyle(dst, prettyStyleDim)
+	if e.opt.RelativeTime {
+		// 右对齐到10列
+		var tmp [32]byte
+		v := strconv.AppendFloat(append(tmp[:0], '+'), t.Sub(e.start).Seconds(), 'f', 3, 64)
+		v = append(v, 's')
+		dst = appendSpaces(dst, 10-len(v))
+		dst = append(dst, v...)
+	} else {
+		dst = appendTimeValue(false, dst, t, e.opt.TimeFormat)
+	}
+	return e.endStyle(dst)
+}
+
+// 日志级别徽标样式
+var prettyLevelStyles = map[logger.Level]string{
+	logger.Trace: "\x1b[100;97m",
+	logger.Debug: "\x1b[44;97m",
+	logger.Info:  "\x1b[42;30m",
+	logger.Warn:  "\x1b[43;30m",
+	logger.Error: "\x1b[41;97m",
+	logger.Fatal: "\x1b[45;97m",
+}
+
+// 日志级别缩写
+var prettyLevelNames = map[logger.Level]string{
+	logger.Trace: "TRC",
+	logger.Debug: "DBG",
+	logger.Info:  "INF",
+	logger.Warn:  "WRN",
+	logger.Error: "ERR",
+	logger.Fatal: "FTL",
+}
+
+// 日志级别完整字符串（按最长的warning补齐）
+var prettyLevelFullNames = map[logger.Level]string{
+	logger.Trace: "TRACE  ",
+	logger.Debug: "DEBUG  ",
+	logger.Info:  "INFO   ",
+	logger.Warn:  "WARNING",
+	logger.Error: "ERROR  ",
+	logger.Fatal: "FATAL  ",
+}
+
+// 追加日志级别徽标
+func (e *PrettyEncoder) appendLevel(dst []byte, l logger.Level) []byte {
+	name := prettyLevelNames[l]
+	if e.opt.LevelFormat {
+		name = prettyLevelFullNames[l]
+	}
+	dst = e.beginStyle(dst, prettyLevelStyles[l])
+	dst = append(dst, ' ')
+	dst = append(dst, name...)
+	dst = append(dst, ' ')
+	return e.endStyle(dst)
+}
+
+// 追加调用位置列
+func (e *PrettyEncoder) appendCaller(dst []byte, fn string, ln int) []byte {
+	if !e.opt.StackFileFormat {
+		// 保留最后一级文件夹及文件名
+		if i := strings.LastIndexByte(fn, '/'); i > 0 {
+			if j := strings.LastIndexByte(fn[:i], '/'); j >= 0 {
+				fn = fn[j+1:]
+			}
+		}
+	}
+	var tmp [20]byte
+	lineNo := strconv.AppendInt(tmp[:0], int64(ln), 10)
+	dst = e.beginStyle(dst, prettyStyleDim)
+	dst = append(dst, fn...)
+	dst = append(dst, ':')
+	dst = append(dst, lineNo...)
+	dst = e.endStyle(dst)
+	return appendSpaces(dst, e.opt.CallerWidth-utf8.RuneCountInString(fn)-1-len(lineNo))
+}
+
+// 追加日志描述及字段
+//
+//	单行字段以key=value形式追加在日志描述之后，
+//	多行字段（如带调用栈的错误）及JSON字段在日志之后逐个缩进输出
+func (e *PrettyEncoder) appendFieldAndMsg(dst []byte, msg string, val ...field.Field) []byte {
+	dst = append(dst, msg...)
+
+	// 单行字段直接追加，多行字段暂存到缓冲区
+	value := prettyBytesPool.Get()
+	tail := prettyBytesPool.Get()
+	first := true
+	for i := range val {
+		var multiline bool
+		value, multiline = e.appendFieldValue(value[:0], val[i])
+		if multiline {
+			tail = e.appendMultiline(tail, val[i].Key, value)
+			continue
+		}
+		if first {
+			dst = appendPadding(dst, msg, e.opt.MessageWidth)
+			first = false
+		} else {
+			dst = append(dst, ' ')
+		}
+		dst = e.appendStyled(dst, prettyStyleKey+prettyStyleDim, val[i].Key)
+		dst = e.appendStyled(dst, prettyStyleDim, "=")
+		dst = append(dst, value...)
+	}
+	dst = append(dst, "\r\n"...)
+
+	// 多行字段
+	dst = append(dst, tail...)
+	prettyBytesPool.Put(value)
+	prettyBytesPool.Put(tail)
+	return dst
+}
+
+// 追加缩进显示的多行字段
+func (e *PrettyEncoder) appendMultiline(dst []byte, key string, value []byte) []byte {
+	dst = append(dst, prettyIndent...)
+	dst = e.appendStyled(dst, prettyStyleKey+prettyStyleDim, key)
+	dst = e.appendStyled(dst, prettyStyleDim, ":")
+	dst = append(dst, ' ')
+	value = bytes.TrimRight(value, "\r\n")
+	for j := 0; ; j++ {
+		line := value
+		n := bytes.IndexByte(value, '\n')
+		if n >= 0 {
+			line = value[:n]
+		}
+		if j > 0 {
+			dst = append(dst, prettyIndent...)
+			dst = append(dst, prettyIndent...)
+		}
+		dst = append(dst, bytes.TrimRight(line, "\r")...)
+		dst = append(dst, "\r\n"...)
+		if n < 0 {
+			return dst
+		}
+		value = value[n+1:]
+	}
+}
+
+// 追加字段值的显示内容
+//
+//	@param	dst	填充目标
+//	@param	val	字段
+//	@return	填充后的内容
+//	@return	是否需要多行显示
+func (e *PrettyEncoder) appendFieldValue(dst []byte, val field.Field) ([]byte, bool) {
+	start := len(dst)
+	switch {
+	// 对象类型优先使用JSON格式
+	case val.Type == field.TypeObjecter:
+		dst = append(dst, val.Interface.(field.Objecter).ToJSON()...)
+	// 未知类型走反射序列化为JSON
+	case !(field.NormalTypeStart < val.Type && val.Type < field.NormalTypeEnd) &&
+		!(field.SliceTypeStart < val.Type && val.Type < field.SliceTypeEnd):
+		dst = appendValue(true, dst, val)
+		if len(dst) == start {
+			// 无法序列化为JSON时与普通编码器保持一致
+			dst = appendValue(false, dst, val)
+			return dst, bytes.IndexByte(dst[start:], '\n') >= 0
+		}
+	// 内容为JSON对象或数组的字符串
+	case val.Type == field.TypeString && isJSONContainer(val.String):
+		dst = append(dst, val.String...)
+	// 其他类型与普通编码器保持一致
+	default:
+		dst = appendValue(false, dst, val)
+		return dst, bytes.IndexByte(dst[start:], '\n') >= 0
+	}
+
+	// JSON对象或数组缩进显示
+	raw := dst[start:]
+	if len(raw) > 0 && (raw[0] == '{' || raw[0] == '[') {
+		end := len(dst)
+		buf := bytes.NewBuffer(dst)
+		if json.Indent(buf, raw, "", "  ") == nil && buf.Len()-end > len(raw) {
+			return append(dst[:start], buf.Bytes()[end:]...), true
+		}
+	}
+	return dst, false
+}
+
+// 判断字符串是否为JSON对象或数组
+func isJSONContainer(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || !((s[0] == '{' && s[len(s)-1] == '}') || (s[0] == '[' && s[len(s)-1] == ']')) {
+		return false
+	}
+	return json.Valid(convert.StringToBytes(s))
+}
@@ -373,20 +373,8 @@ func appendFieldValues(isJson bool, dst []byte, val field.Field) []byte {
 	return dst
 }
 
-// 追加字段
-func appendField(isJson bool, dst []byte, val field.Field) []byte {
-	// 是否为JSON格式
-	if isJson {
-		// 追加键名
-		dst = append(dst, '"')
-		dst = append(dst, val.Key...)
-		dst = append(dst, `": `...)
-	} else {
-		// 追加键名
-		dst = append(dst, val.Key...)
-		dst = append(dst, `:`...)
-	}
-
+// 追加字段值（含切片类型及未知类型）
+func appendValue(isJson bool, dst []byte, val field.Field) []byte {
 	switch true {
 
 	// 普通类型
@@ -415,6 +403,29 @@ func appendField(isJson bool, dst []byte, val field.Field) []byte {
 
 	}
 
+	// 追加完成
+	return dst
+}
+
+// 追加字段
+func appendField(isJson bool, dst []byte, theme *Theme, val field.Field) []byte {
+	// 是否为JSON格式
+	if isJson {
+		// 追加键名
+		dst = appendKeyJSON(dst, theme, val.Key)
+	} else {
+		// 追加键名
+		dst = theme.Key.begin(dst)
+		dst = append(dst, val.Key...)
+		dst = theme.Key.end(dst)
+		dst = append(dst, `:`...)
+	}
+
+	// 追加值
+	dst = theme.Value.begin(dst)
+	dst = appendValue(isJson, dst, val)
+	dst = theme.Value.end(dst)
+
 	// 组装完成
 	return dst
 }
@@ -422,14 +433,17 @@ func appendField(isJson bool, dst []byte, val field.Field) []byte {
 // 将字段拼接为行格式
 //
 //	@param	dst		目标切片
+//	@param	theme	配色主题
 //	@param	message	日志消息
 //	@param	val		字段列表
 //	@return	序列化后的行格式字段字符串
 //
 // 返回示例: message, k1: v1, k2: v2, ...
-func appendFieldAndMsg(dst []byte, message string, val ...field.Field) []byte {
+func appendFieldAndMsg(dst []byte, theme *Theme, message string, val ...field.Field) []byte {
 	// 追加message内容
+	dst = theme.Message.begin(dst)
 	dst = append(dst, convert.StringToBytes(message)...)
+	dst = theme.Message.end(dst)
 
 	// 字段数是否不为空
 	if len(val) > 0 {
@@ -442,7 +456,7 @@ func appendFieldAndMsg(dst []byte, message string, val ...field.Field) []byte {
 				dst = append(dst, `, `...)
 			}
 			// 追加字段并序列化
-			dst = appendField(false, dst, v)
+			dst = appendField(false, dst, theme, v)
 		}
 	}
 
@@ -453,6 +467,7 @@ func appendFieldAndMsg(dst []byte, message string, val ...field.Field) []byte {
 // appendFieldAndMsgJSON 将字段拼接为json格式
 //
 //	@param	dst			目标切片
+//	@param	theme		配色主题
 //	@param	messageKey	消息的键名
 //	@param	message		消息内容
 //	@param	fieldsKey	包裹所有字段的键名
@@ -460,23 +475,24 @@ func appendFieldAndMsg(dst []byte, message string, val ...field.Field) []byte {
 //	@return	序列化后的JSON格式字段字符串
 //
 // 返回示例: "msg": "message", "fields": {"k1": "v1", ...}
-func appendFieldAndMsgJSON(dst []byte, messageKey string, message string, fieldsKey string, val ...field.Field) []byte {
+func appendFieldAndMsgJSON(dst []byte, theme *Theme, messageKey string, message string, fieldsKey string, val ...field.Field) []byte {
 	// 追加message字段
+	dst = appendKeyJSON(dst, theme, messageKey)
+	dst = theme.Message.begin(dst)
 	dst = append(dst, '"')
-	dst = append(dst, messageKey...)
-	dst = append(dst, `": "`...)
 	if strings.Contains(message, `"`) {
 		message = strings.ReplaceAll(message, `"`, `\"`)
 	}
 	dst = append(dst, convert.StringToBytes(message)...)
 	dst = append(dst, `"`...)
+	dst = theme.Message.end(dst)
 
 	// 字段数是否不为空
 	if len(val) > 0 {
 		// 追加字段集字段
-		dst = append(dst, `, "`...)
-		dst = append(dst, fieldsKey...)
-		dst = append(dst, `": {`...)
+		dst = append(dst, `, `...)
+		dst = appendKeyJSON(dst, theme, fieldsKey)
+		dst = append(dst, '{')
 		// 遍历所有字段
 		for i, v := range val {
 			// 从第二个有效字段开始追加分隔符号
@@ -484,7 +500,7 @@ func appendFieldAndMsgJSON(dst []byte, messageKey string, message string, fields
 				dst = append(dst, `, `...)
 			}
 			// 追加字段并序列化
-			dst = appendField(true, dst, v)
+			dst = appendField(true, dst, theme, v)
 		}
 		// 追加字段结束括号
 		dst = append(dst, '}')
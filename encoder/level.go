@@ -7,12 +7,15 @@ import (
 // appendLevel 追加行格式的日志级别
 //
 //	@param	dst				目标切片
+//	@param	theme			配色主题
 //	@param	l				级别
 //	@param	useFullString	是否使用全称
 //	@return	序列化后的日志级别字符串
 //
 // 返回示例: [T]
-func appendLevel(dst []byte, l logger.Level, useFullString bool) []byte {
+func appendLevel(dst []byte, theme *Theme, l logger.Level, useFullString bool) []byte {
+	style := theme.level(l)
+	dst = style.begin(dst)
 	dst = append(dst, '[')
 	if useFullString {
 		dst = append(dst, l.String()...)
@@ -20,27 +23,29 @@ func appendLevel(dst []byte, l logger.Level, useFullString bool) []byte {
 		dst = append(dst, l.Byte())
 	}
 	dst = append(dst, ']')
-	return dst
+	return style.end(dst)
 }
 
 // appendLevelJSON 追加行格式的日志级别
 //
 //	@param	dst				目标切片
+//	@param	theme			配色主题
 //	@param	levelKey		日志级别JSON键名
 //	@param	l				级别
 //	@param	useFullString	是否使用全称
 //	@return	序列化后的日志级别字符串
 //
 // 返回示例: "level": "T"
-func appendLevelJSON(dst []byte, levelKey string, l logger.Level, useFullString bool) []byte {
+func appendLevelJSON(dst []byte, theme *Theme, levelKey string, l logger.Level, useFullString bool) []byte {
+	dst = appendKeyJSON(dst, theme, levelKey)
+	style := theme.level(l)
+	dst = style.begin(dst)
 	dst = append(dst, '"')
-	dst = append(dst, levelKey...)
-	dst = append(dst, '"', ':', ' ', '"')
 	if useFullString {
 		dst = append(dst, l.String()...)
 	} else {
 		dst = append(dst, l.Byte())
 	}
 	dst = append(dst, '"')
-	return dst
+	return style.end(dst)
 }
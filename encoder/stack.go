@@ -8,6 +8,7 @@ import (
 // 追加行格式的调用栈
 //
 //	@param	dst			目标切片
+//	@param	theme		配色主题
 //	@param	fullPath	是否保留完整路径
 //	@param	fn			完整文件名
 //	@param	ln			行号
@@ -15,7 +16,7 @@ import (
 //	@return	序列化后的调用栈字符串
 //
 // 返回示例: [test.go:100] [test.TestLogger]
-func appendStack(dst []byte, fullPath bool, fn string, ln int, mn string) []byte {
+func appendStack(dst []byte, theme *Theme, fullPath bool, fn string, ln int, mn string) []byte {
 	if !fullPath {
 		// 裁剪为基础文件名
 		index := strings.LastIndexByte(fn, '/')
@@ -31,6 +32,7 @@ func appendStack(dst []byte, fullPath bool, fn string, ln int, mn string) []byte
 	}
 
 	// 追加内容
+	dst = theme.Stack.begin(dst)
 	dst = append(dst, '[')
 	dst = append(dst, fn...)
 	dst = append(dst, ':')
@@ -38,6 +40,7 @@ func appendStack(dst []byte, fullPath bool, fn string, ln int, mn string) []byte
 	dst = append(dst, `] [`...)
 	dst = append(dst, mn...)
 	dst = append(dst, `]`...)
+	dst = theme.Stack.end(dst)
 
 	// OK
 	return dst
@@ -46,6 +49,7 @@ func appendStack(dst []byte, fullPath bool, fn string, ln int, mn string) []byte
 // 追加JSON格式的调用栈
 //
 //	@param	dst			目标切片
+//	@param	theme		配色主题
 //	@param	fullPath	是否保留完整路径
 //	@param	stackKey	调用栈信息键名
 //	@param	fnKey		文件名的JSON键名
@@ -57,7 +61,7 @@ func appendStack(dst []byte, fullPath bool, fn string, ln int, mn string) []byte
 //	@return	序列化后的调用栈字符串
 //
 // 返回示例: "stack": {"file": "test.go", "line": 100, "method": "test.TestLogger"}
-func appendStackJSON(dst []byte, fullPath bool, stackKey string, fnKey string, fn string, lnKey string, ln int, mnKey string, mn string) []byte {
+func appendStackJSON(dst []byte, theme *Theme, fullPath bool, stackKey string, fnKey string, fn string, lnKey string, ln int, mnKey string, mn string) []byte {
 	if !fullPath {
 		// 裁剪为基础文件名
 		index := strings.LastIndexByte(fn, '/')
@@ -73,9 +77,9 @@ func appendStackJSON(dst []byte, fullPath bool, stackKey string, fnKey string, f
 	}
 
 	// 追加内容
-	dst = append(dst, '"')
-	dst = append(dst, stackKey...)
-	dst = append(dst, `": {"`...)
+	dst = appendKeyJSON(dst, theme, stackKey)
+	dst = theme.Stack.begin(dst)
+	dst = append(dst, `{"`...)
 	dst = append(dst, fnKey...)
 	dst = append(dst, `": "`...)
 	dst = append(dst, fn...)
@@ -88,6 +92,7 @@ func appendStackJSON(dst []byte, fullPath bool, stackKey string, fnKey string, f
 	dst = append(dst, `": "`...)
 	dst = append(dst, mn...)
 	dst = append(dst, `"}`...)
+	dst = theme.Stack.end(dst)
 
 	// OK
 	return dst
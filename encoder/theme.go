@@ -0,0 +1,112 @@
+package encoder
+
+import (
+	"github.com/bearki/belog/v3/logger"
+)
+
+// Style 控制台样式（ANSI SGR转义序列，为空时不设置样式）
+type Style string
+
+// 重置样式
+const styleReset = "\x1b[0m"
+
+// 开始样式
+func (s Style) begin(dst []byte) []byte {
+	return append(dst, s...)
+}
+
+// 结束样式
+func (s Style) end(dst []byte) []byte {
+	if len(s) == 0 {
+		return dst
+	}
+	return append(dst, styleReset...)
+}
+
+// Theme 控制台配色主题
+type Theme struct {
+	Time    Style // 时间
+	Trace   Style // 通知级别
+	Debug   Style // 调试级别
+	Info    Style // 普通级别
+	Warn    Style // 警告级别
+	Error   Style // 错误级别
+	Fatal   Style // 致命级别
+	Stack   Style // 调用栈
+	Message Style // 日志描述
+	Key     Style // 字段键名
+	Value   Style // 字段值
+}
+
+// 不设置样式的配色主题
+var noTheme = &Theme{}
+
+// 获取日志级别对应的样式
+func (t *Theme) level(l logger.Level) Style {
+	switch l {
+	case logger.Trace:
+		return t.Trace
+	case logger.Debug:
+		return t.Debug
+	case logger.Info:
+		return t.Info
+	case logger.Warn:
+		return t.Warn
+	case logger.Error:
+		return t.Error
+	case logger.Fatal:
+		return t.Fatal
+	default:
+		return ""
+	}
+}
+
+// 追加带样式的JSON键名及分隔符
+//
+//	@param	dst		目标切片
+//	@param	theme	配色主题
+//	@param	key		键名
+//	@return	追加后的内容
+//
+// 返回示例: "key":
+func appendKeyJSON(dst []byte, theme *Theme, key string) []byte {
+	dst = theme.Key.begin(dst)
+	dst = append(dst, '"')
+	dst = append(dst, key...)
+	dst = append(dst, '"')
+	dst = theme.Key.end(dst)
+	return append(dst, `: `...)
+}
+
+// WithTheme 获取使用指定配色主题的编码器副本
+//
+//	样式按编码时的日志级别、时间、调用栈、描述及字段直接设置，不会因日志内容而错位；
+//	美化编码器使用自带的配色，theme不为nil时输出颜色；
+//	其他编码器不支持配色主题，将原样返回
+//
+//	@param	enc		编码器
+//	@param	theme	配色主题（为nil时不输出颜色）
+//	@return	编码器副本
+func WithTheme(enc logger.Encoder, theme *Theme) logger.Encoder {
+	t := noTheme
+	if theme != nil {
+		tmp := *theme
+		t = &tmp
+	}
+	switch e := enc.(type) {
+	case *NormalEncoder:
+		c := *e
+		c.theme = t
+		return &c
+	case *JsonEncoder:
+		c := *e
+		c.theme = t
+		return &c
+	case *PrettyEncoder:
+		c := *e
+		c.opt.DisabledColor = theme == nil
+		return &c
+	default:
+		return enc
+	}
+}
@@ -59,32 +59,35 @@ func appendTimeValue(isJson bool, dst []byte, t time.Time, format string) []byte
 // 追加行格式的时间
 //
 //	@param	dst		目标切片
-//	@param	key		时间的键名
+//	@param	theme	配色主题
 //	@param	t		实际时间
 //	@param	format	序列化格式（与time.Format保持一致）
 //	@return	序列化后的行格式时间字符串
 //
 // 返回示例: 2006/01/02 15:04:05.000
-func appendTime(dst []byte, t time.Time, format string) []byte {
+func appendTime(dst []byte, theme *Theme, t time.Time, format string) []byte {
 	// 追加时间值
-	return appendTimeValue(false, dst, t, format)
+	dst = theme.Time.begin(dst)
+	dst = appendTimeValue(false, dst, t, format)
+	return theme.Time.end(dst)
 }
 
 // 追加JSON格式的时间
 //
 //	@param	dst		目标切片
+//	@param	theme	配色主题
 //	@param	key		时间的JSON键名
 //	@param	t		实际时间
 //	@param	format	序列化格式（与time.Format保持一致）
 //	@return	序列化后的JSON格式时间字符串
 //
 // 返回示例: "time": "2006/01/02 15:04:05.000"` || `"time": 123456789000
-func appendTimeJSON(dst []byte, key string, t time.Time, format string) []byte {
+func appendTimeJSON(dst []byte, theme *Theme, key string, t time.Time, format string) []byte {
 	// 拼接键名
-	dst = append(dst, '"')
-	dst = append(dst, key...)
-	dst = append(dst, `": `...)
+	dst = appendKeyJSON(dst, theme, key)
 
 	// 追加时间值
-	return appendTimeValue(true, dst, t, format)
+	dst = theme.Time.begin(dst)
+	dst = appendTimeValue(true, dst, t, format)
+	return theme.Time.end(dst)
 }
@@ -0,0 +1,73 @@
+package encoder
+
+import (
+	"unicode/utf8"
+
+	"github.com/bearki/belog/v3/field"
+)
+
+// AppendValue 追加字段值的行格式（与普通编码器的字段值一致）
+//
+//	供自行组装日志格式的适配器（如syslog的结构化数据）使用
+//
+//	@param	dst	填充目标
+//	@param	val	字段
+//	@return	填充后的内容
+func AppendValue(dst []byte, val field.Field) []byte {
+	return appendValue(false, dst, val)
+}
+
+// AppendJSONValue 追加字段值的JSON格式（与JSON编码器的字段值一致）
+//
+//	@param	dst	填充目标
+//	@param	val	字段
+//	@return	填充后的内容
+func AppendJSONValue(dst []byte, val field.Field) []byte {
+	return appendValue(true, dst, val)
+}
+
+// 十六进制字符
+const hexDigits = "0123456789abcdef"
+
+// AppendJSONString 追加完整转义的JSON字符串（含双引号）
+//
+//	供自行组装JSON格式的适配器（如GELF、HTTP请求体）使用，
+//	控制字符转义为\uXXXX，无效的UTF-8字节替换为U+FFFD
+//
+//	@param	dst	填充目标
+//	@param	s	字符串
+//	@return	填充后的内容
+func AppendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, "\ufffd"...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < 0x20 {
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+		i++
+	}
+	return append(dst, '"')
+}
@@ -0,0 +1,154 @@
+/**
+ *@Title 敏感值检测
+ *@Desc 检测字符串中的银行卡号（Luhn校验）、邮箱、JWT及Bearer令牌，并按处理方式替换
+ */
+
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Detector 敏感值检测器（可按位组合）
+type Detector uint8
+
+const (
+	DetectCreditCard Detector = 1 << iota // 银行卡号（13~19位数字，允许空格或横线分隔，需通过Luhn校验）
+	DetectEmail                           // 邮箱地址
+	DetectJWT                             // JWT令牌
+	DetectBearer                          // Bearer令牌
+
+	// DetectAll 全部检测器
+	DetectAll = DetectCreditCard | DetectEmail | DetectJWT | DetectBearer
+)
+
+// 检测用正则表达式
+var (
+	cardRegexp   = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+	emailRegexp  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,})`)
+	jwtRegexp    = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerRegexp = regexp.MustCompile(`(?i)(bearer)\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// Luhn校验
+//
+//	@param	s	仅包含数字、空格及横线的字符串
+//	@return	数字位数
+//	@return	是否通过校验
+func luhn(s string) (int, bool) {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n, n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// 获取字符串末尾的n个数字
+func lastDigits(s string, n int) string {
+	digits := make([]byte, 0, n)
+	for i := len(s) - 1; i >= 0 && len(digits) < n; i-- {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// 检测并替换字符串中的敏感值
+//
+//	@param	s	原始字符串
+//	@return	替换后的字符串
+//	@return	是否检测到敏感值
+func (r *redactor) detect(s string) (string, bool) {
+	found := false
+
+	// JWT需先于邮箱检测（避免载荷被误判）
+	if r.detectors&DetectJWT != 0 && strings.Contains(s, "eyJ") {
+		s = jwtRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			// 至少包含头部及载荷
+			if strings.Count(m, ".") != 2 || len(m) < 16 {
+				return m
+			}
+			found = true
+			return r.replace(m, maskValue)
+		})
+	}
+
+	// Bearer令牌（保留前缀）
+	if r.detectors&DetectBearer != 0 && containsFold(s, "bearer") {
+		s = bearerRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			i := strings.IndexAny(m, " \t\r\n")
+			prefix, token := m[:i], strings.TrimLeft(m[i:], " \t\r\n")
+			if token == maskValue || strings.HasPrefix(token, hashPrefix) {
+				return m
+			}
+			found = true
+			return prefix + " " + r.replace(token, maskValue)
+		})
+	}
+
+	// 邮箱地址（掩码时保留域名）
+	if r.detectors&DetectEmail != 0 && strings.IndexByte(s, '@') >= 0 {
+		s = emailRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			found = true
+			return r.replace(m, maskValue+m[strings.LastIndexByte(m, '@'):])
+		})
+	}
+
+	// 银行卡号（掩码时保留末4位）
+	if r.detectors&DetectCreditCard != 0 && hasDigitRun(s, 13) {
+		s = cardRegexp.ReplaceAllStringFunc(s, func(m string) string {
+			if _, ok := luhn(m); !ok {
+				return m
+			}
+			found = true
+			return r.replace(m, maskValue+lastDigits(m, 4))
+		})
+	}
+
+	return s, found
+}
+
+// 判断字符串中是否存在至少n个数字（允许空格或横线分隔）
+func hasDigitRun(s string, n int) bool {
+	count := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			count++
+			if count >= n {
+				return true
+			}
+		case c == ' ' || c == '-':
+		default:
+			count = 0
+		}
+	}
+	return false
+}
+
+// 忽略大小写判断是否包含子串（sub需为小写）
+func containsFold(s string, sub string) bool {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,508 @@
+/**
+ *@Title 日志字段脱敏钩子
+ *@Desc 在编码前按字段键匹配及值检测处理敏感字段（移除、掩码或替换为HMAC摘要），覆盖日志描述、字符串切片、自定义对象及任意类型字段
+ */
+
+package redact
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 替换值相关常量
+const (
+	maskValue  = "****"  // 掩码
+	hashPrefix = "hmac:" // 摘要前缀
+)
+
+// Action 敏感字段处理方式
+type Action uint8
+
+const (
+	ActionMask Action = 0 // 掩码（银行卡号保留末4位，邮箱保留域名，其余替换为****）
+	ActionDrop Action = 1 // 移除整个字段（任意类型字段中的map、切片仅移除键匹配的项）
+	ActionHash Action = 2 // 替换为 hmac:<HMAC-SHA256前16字节的十六进制>，相同的值得到相同的摘要，便于关联排查
+)
+
+// DefaultKeys 默认的敏感字段键匹配规则
+var DefaultKeys = []string{
+	"password", "passwd", "pwd", "*_password",
+	"secret", "*_secret",
+	"token", "*_token",
+	"api_key", "apikey", "*_api_key",
+	"access_key", "*_access_key", "secret_key", "*_secret_key",
+	"private_key", "*_private_key", "signing_key", "encryption_key",
+	"authorization", "cookie", "set-cookie",
+	"card_number", "credit_card", "cvv",
+}
+
+// Options 日志字段脱敏参数
+type Options struct {
+	// 敏感字段键匹配规则（不区分大小写，支持*、?通配符）
+	//
+	// 键匹配的字段无论值为何种类型均会被处理，
+	// 也用于匹配map的键、结构体的JSON字段名及自定义对象JSON中的键
+	//
+	// Default: DefaultKeys
+	Keys []string
+
+	// 敏感值检测器
+	//
+	// 对日志描述及字符串值（含字符串切片、错误、自定义对象及任意类型中的字符串）进行检测
+	//
+	// Default: DetectAll
+	Detectors Detector
+
+	// 是否禁用敏感值检测
+	//
+	// Default: false
+	DisabledDetectors bool
+
+	// 敏感字段处理方式
+	//
+	// Default: ActionMask
+	Action Action
+
+	// HMAC密钥（Action为ActionHash时必填）
+	HashKey []byte
+}
+
+// 打印警告信息
+func printWarningMsg(msg string) {
+	_, _ = os.Stdout.WriteString(msg + "\r\n")
+}
+
+// 判断参数有效性
+func (p *Options) validity() error {
+	if p.Keys == nil {
+		p.Keys = DefaultKeys
+	}
+	if p.DisabledDetectors {
+		p.Detectors = 0
+	} else if p.Detectors == 0 {
+		p.Detectors = DetectAll
+	}
+	if p.Action > ActionHash {
+		printWarningMsg("redact action is invalid, use the default value ActionMask")
+		p.Action = ActionMask
+	}
+	if p.Action == ActionHash && len(p.HashKey) == 0 {
+		return errors.New("redact hash key cannot be empty when action is ActionHash")
+	}
+	return nil
+}
+
+// 日志字段脱敏钩子
+type redactor struct {
+	keys      []string   // 敏感字段键匹配规则（已转为小写）
+	detectors Detector   // 敏感值检测器
+	action    Action     // 敏感字段处理方式
+	hashPool  *sync.Pool // HMAC计算对象池
+}
+
+// New 创建日志字段脱敏钩子
+//
+//	@param	options	脱敏参数
+//	@return	日志钩子
+//	@return	异常信息
+func New(options Options) (logger.Hook, error) {
+	if err := options.validity(); err != nil {
+		return nil, err
+	}
+
+	// 检查匹配规则
+	keys := make([]string, 0, len(options.Keys))
+	for _, k := range options.Keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if _, err := path.Match(k, ""); err != nil {
+			return nil, fmt.Errorf("redact key pattern %q is invalid, %w", k, err)
+		}
+		keys = append(keys, k)
+	}
+
+	r := &redactor{
+		keys:      keys,
+		detectors: options.Detectors,
+		action:    options.Action,
+	}
+	if options.Action == ActionHash {
+		key := append([]byte(nil), options.HashKey...)
+		r.hashPool = &sync.Pool{New: func() interface{} { return hmac.New(sha256.New, key) }}
+	}
+	return r, nil
+}
+
+// 判断字段键是否匹配敏感字段规则
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if ok, _ := path.Match(k, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 按处理方式替换敏感值
+//
+//	@param	s		敏感值
+//	@param	masked	掩码后的值
+func (r *redactor) replace(s string, masked string) string {
+	if r.action != ActionHash {
+		return masked
+	}
+	h := r.hashPool.Get().(hash.Hash)
+	h.Reset()
+	_, _ = h.Write([]byte(s))
+	sum := h.Sum(nil)
+	r.hashPool.Put(h)
+	return hashPrefix + hex.EncodeToString(sum[:16])
+}
+
+// 获取字段值的字符串形式（用于计算摘要）
+func stringValue(f field.Field) string {
+	switch {
+	case f.Interface != nil:
+		return fmt.Sprint(f.Interface)
+	case f.Type == field.TypeString || f.Type == field.TypeError:
+		return f.String
+	default:
+		return strconv.FormatInt(f.Integer, 10)
+	}
+}
+
+// Fire 日志编码前执行脱敏
+func (r *redactor) Fire(rec *logger.Record) bool {
+	// 日志描述仅检测敏感值（ActionDrop时按掩码处理）
+	if r.detectors != 0 {
+		if s, found := r.detect(rec.Message); found {
+			rec.Message = s
+		}
+	}
+
+	n := 0
+	for _, f := range rec.Fields {
+		if f, keep := r.redact(f); keep {
+			rec.Fields[n] = f
+			n++
+		}
+	}
+	rec.Fields = rec.Fields[:n]
+	return true
+}
+
+// 处理单个字段
+//
+//	@return	处理后的字段
+//	@return	是否保留该字段
+func (r *redactor) redact(f field.Field) (field.Field, bool) {
+	// 字段键匹配时整体处理
+	if r.matchKey(f.Key) {
+		switch r.action {
+		case ActionDrop:
+			return f, false
+		case ActionHash:
+			if vals, ok := f.Interface.([]string); ok {
+				out := make([]string, len(vals))
+				for i, v := range vals {
+					out[i] = r.replace(v, maskValue)
+				}
+				return field.Strings(f.Key, out), true
+			}
+			return field.String(f.Key, r.replace(stringValue(f), maskValue)), true
+		default:
+			return field.String(f.Key, maskValue), true
+		}
+	}
+
+	// 未启用值检测
+	if r.detectors == 0 {
+		return f, true
+	}
+
+	switch f.Type {
+	case field.TypeString, field.TypeError:
+		s, found := r.detect(f.String)
+		if !found {
+			return f, true
+		}
+		if r.action == ActionDrop {
+			return f, false
+		}
+		f.String = s
+		return f, true
+
+	case field.TypeStrings:
+		vals, _ := f.Interface.([]string)
+		out, found := r.detectSlice(len(vals), func(i int) string { return vals[i] })
+		return r.sliceResult(f, out, found)
+
+	case field.TypeErrors:
+		vals, _ := f.Interface.([]error)
+		out, found := r.detectSlice(len(vals), func(i int) string {
+			if vals[i] == nil {
+				return "<nil>"
+			}
+			return vals[i].Error()
+		})
+		return r.sliceResult(f, out, found)
+
+	case field.TypeUnknown:
+		if f.Interface == nil {
+			return f, true
+		}
+		v, found := r.detectValue(f.Interface)
+		if !found {
+			return f, true
+		}
+		// 容器类型已逐项处理，仅移除非容器类型的字段
+		if r.action == ActionDrop && !isContainer(v) {
+			return f, false
+		}
+		return field.Interface(f.Key, v), true
+
+	case field.TypeObjecter:
+		obj, _ := f.Interface.(field.Objecter)
+		o, found := r.detectObject(obj)
+		if !found {
+			return f, true
+		}
+		if r.action == ActionDrop && !o.container {
+			return f, false
+		}
+		return field.Object(f.Key, o), true
+
+	case field.TypeObjecters:
+		vals, _ := f.Interface.([]field.Objecter)
+		var out []field.Objecter
+		drop := false
+		for i, v := range vals {
+			o, found := r.detectObject(v)
+			if found && out == nil {
+				out = make([]field.Objecter, len(vals))
+				copy(out, vals[:i])
+			}
+			if !found {
+				if out != nil {
+					out[i] = v
+				}
+				continue
+			}
+			out[i] = o
+			drop = drop || !o.container
+		}
+		if out == nil {
+			return f, true
+		}
+		if r.action == ActionDrop && drop {
+			return f, false
+		}
+		return field.Objects(f.Key, out), true
+	}
+	return f, true
+}
+
+// 检测切片中的每个字符串
+//
+//	@return	检测后的字符串切片（未检测到敏感值时为nil）
+//	@return	是否检测到敏感值
+func (r *redactor) detectSlice(n int, get func(i int) string) ([]string, bool) {
+	var out []string
+	for i := 0; i < n; i++ {
+		s, found := r.detect(get(i))
+		if found && out == nil {
+			out = make([]string, n)
+			for j := 0; j < i; j++ {
+				out[j] = get(j)
+			}
+		}
+		if out != nil {
+			out[i] = s
+		}
+	}
+	return out, out != nil
+}
+
+// 生成切片字段的处理结果
+func (r *redactor) sliceResult(f field.Field, out []string, found bool) (field.Field, bool) {
+	if !found {
+		return f, true
+	}
+	if r.action == ActionDrop {
+		return f, false
+	}
+	return field.Strings(f.Key, out), true
+}
+
+// 判断处理后的值是否为逐项处理的容器类型
+func isContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]string, map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// 检测任意类型的值
+//
+//	map[string]interface{}、map[string]string及[]interface{}将复制后逐项处理：
+//	键匹配的项按处理方式处理（ActionDrop时移除该项），检测到的敏感值按掩码或摘要替换；
+//	结构体、指针、其他map及切片按JSON形式（与JSON编码器的输出一致）转为上述类型后逐项处理，
+//	检测到敏感值时以处理后的JSON形式替换原值（不导出的结构体字段不会输出）；
+//	其余类型及无法序列化为JSON的值转为字符串后检测
+//
+//	@return	处理后的值
+//	@return	是否检测到敏感值
+func (r *redactor) detectValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		return r.detect(val)
+
+	case map[string]string:
+		out := make(map[string]string, len(val))
+		found := false
+		for k, s := range val {
+			if r.matchKey(k) {
+				found = true
+				if r.action != ActionDrop {
+					out[k] = r.replace(s, maskValue)
+				}
+				continue
+			}
+			var ok bool
+			out[k], ok = r.detect(s)
+			found = found || ok
+		}
+		return out, found
+
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		found := false
+		for k, item := range val {
+			if r.matchKey(k) {
+				found = true
+				if r.action != ActionDrop {
+					out[k] = r.replace(fmt.Sprint(item), maskValue)
+				}
+				continue
+			}
+			var ok bool
+			out[k], ok = r.detectValue(item)
+			found = found || ok
+		}
+		return out, found
+
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		found := false
+		for i, item := range val {
+			var ok bool
+			out[i], ok = r.detectValue(item)
+			found = found || ok
+		}
+		return out, found
+
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		// 数值类型无需检测（银行卡号应以字符串记录）
+		return v, false
+
+	default:
+		// 复合类型按JSON形式逐项处理
+		if isComposite(v) {
+			if tree, err := decodeJSON(json.Marshal(v)); err == nil {
+				if out, found := r.detectValue(tree); found {
+					return out, true
+				}
+				return v, false
+			}
+		}
+		if s, found := r.detect(fmt.Sprintf("%+v", v)); found {
+			return s, true
+		}
+		return v, false
+	}
+}
+
+// 判断是否为按JSON形式逐项处理的复合类型
+func isComposite(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// 将JSON解析为map[string]interface{}、[]interface{}及基础类型组成的值（数值保留为json.Number）
+func decodeJSON(data []byte, err error) (interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err = d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// 脱敏后的自定义对象
+type redactedObject struct {
+	str       string // ToString的输出
+	js        []byte // ToJSON的输出
+	container bool   // JSON形式是否为逐项处理的容器类型
+}
+
+// ToString 将自定义类型转换为字符串
+func (o *redactedObject) ToString() string {
+	return o.str
+}
+
+// ToJSON 将自定义类型转换为JSON
+func (o *redactedObject) ToJSON() []byte {
+	return o.js
+}
+
+// 检测自定义对象的输出
+//
+//	JSON输出按任意类型逐项处理，字符串输出仅检测敏感值；
+//	JSON输出中存在敏感值时字符串输出也替换为处理后的JSON，避免键匹配的值从字符串输出中泄露
+//
+//	@return	脱敏后的自定义对象
+//	@return	是否检测到敏感值
+func (r *redactor) detectObject(obj field.Objecter) (*redactedObject, bool) {
+	if obj == nil {
+		return nil, false
+	}
+	str, strFound := r.detect(obj.ToString())
+	raw := obj.ToJSON()
+	o := &redactedObject{str: str, js: raw}
+	jsFound := false
+	if tree, err := decodeJSON(raw, nil); err == nil {
+		var out interface{}
+		if out, jsFound = r.detectValue(tree); jsFound {
+			o.js, _ = json.Marshal(out)
+			o.str = string(o.js)
+			o.container = isContainer(out)
+		}
+	} else if s, found := r.detect(string(raw)); found {
+		o.js, jsFound = []byte(s), true
+	}
+	return o, strFound || jsFound
+}
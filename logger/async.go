@@ -0,0 +1,291 @@
+/**
+ *@Title belog异步输出核心
+ *@Desc 调用方编码后将日志放入有界队列，由单个分发协程依次输出到各适配器
+ */
+
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/pkg/pool"
+)
+
+// OverflowPolicy 异步队列已满时的处理策略
+type OverflowPolicy uint8
+
+const (
+	OverflowBlock      OverflowPolicy = iota // 阻塞等待队列空闲（不丢失日志）
+	OverflowDropNewest                       // 丢弃当前日志
+	OverflowDropOldest                       // 丢弃队列中最旧的日志
+)
+
+// AsyncOption 异步输出参数
+type AsyncOption struct {
+	// 是否启用异步输出
+	//
+	// 启用后调用方仅负责编码，适配器输出由单个分发协程依次完成，
+	// 记录器不再使用时需通过Closer接口调用Close，否则分发协程将一直运行
+	//
+	// Default: false
+	Enabled bool
+
+	// 队列容量（日志条数）
+	//
+	// Default: 8192, Max: 1048576
+	QueueSize uint
+
+	// 队列已满时的处理策略
+	//
+	// 丢弃的日志条数可通过AsyncStats接口获取
+	//
+	// Default: OverflowBlock
+	Overflow OverflowPolicy
+}
+
+// 判断参数有效性
+func (p *AsyncOption) validity() {
+	if p.QueueSize == 0 || p.QueueSize > 1048576 {
+		p.QueueSize = 8192
+	}
+	if p.Overflow > OverflowDropOldest {
+		p.Overflow = OverflowBlock
+	}
+}
+
+// 异步队列中的日志
+type asyncEntry struct {
+	t       time.Time      // 日志记录时间
+	l       Level          // 日志级别
+	content []byte         // 日志内容（来自异步核心的字节流对象池）
+	stack   bool           // 是否包含调用栈
+	fn      string         // 调用栈文件名
+	ln      int            // 调用栈行号
+	mn      string         // 调用栈函数名
+	adapter EncoderAdapter // 自带编码器的适配器（为空时输出到全部普通适配器）
+	tagger  TagAdapter     // 按日志元数据标记输出的适配器（为空时输出到全部普通适配器）
+	tag     uint64         // 日志标记（仅在tagger不为空时有效）
+}
+
+// 异步输出核心
+type asyncCore struct {
+	dropped  uint64              // 因队列已满丢弃的日志条数（原子操作，需保持64位对齐）
+	mutex    sync.RWMutex        // 关闭锁（入队时持有读锁）
+	closed   bool                // 是否已关闭
+	queue    chan asyncEntry     // 有界日志队列
+	overflow OverflowPolicy      // 队列已满时的处理策略
+	pool     *pool.BytesPool     // 日志字节流对象池
+	flushReq chan chan struct{}  // 刷新请求
+	stop     chan struct{}       // 停止通知
+	done     chan struct{}       // 分发协程退出通知
+	dispatch func(e *asyncEntry) // 分发方法
+}
+
+// 创建异步输出核心并启动分发协程
+func newAsyncCore(opt AsyncOption, dispatch func(e *asyncEntry)) *asyncCore {
+	opt.validity()
+	// 对象池初始化时会预先创建10个字节切片，容量不能小于该值
+	poolSize := int(opt.QueueSize)
+	if poolSize < 16 {
+		poolSize = 16
+	}
+	a := &asyncCore{
+		queue:    make(chan asyncEntry, opt.QueueSize),
+		overflow: opt.Overflow,
+		pool:     pool.NewBytesPool(poolSize, 0, 1024),
+		flushReq: make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		dispatch: dispatch,
+	}
+	go a.run()
+	return a
+}
+
+// 分发协程
+func (a *asyncCore) run() {
+	defer close(a.done)
+	for {
+		select {
+		case e := <-a.queue:
+			a.output(&e)
+		case done := <-a.flushReq:
+			// 输出收到刷新请求时队列中已有的日志
+			a.drain(len(a.queue))
+			close(done)
+		case <-a.stop:
+			a.drain(-1)
+			return
+		}
+	}
+}
+
+// 输出队列中的日志
+//
+//	@param	n	最多输出的条数（小于0时输出至队列为空）
+func (a *asyncCore) drain(n int) {
+	for ; n != 0; n-- {
+		select {
+		case e := <-a.queue:
+			a.output(&e)
+		default:
+			return
+		}
+	}
+}
+
+// 输出一条日志并回收字节流
+func (a *asyncCore) output(e *asyncEntry) {
+	a.dispatch(e)
+	a.pool.Put(e.content)
+}
+
+// 日志入队
+func (a *asyncCore) push(e asyncEntry) {
+	switch a.overflow {
+	case OverflowDropNewest:
+		select {
+		case a.queue <- e:
+		default:
+			atomic.AddUint64(&a.dropped, 1)
+			a.pool.Put(e.content)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case a.queue <- e:
+				return
+			default:
+			}
+			// 队列已满，丢弃最旧的一条后重试
+			select {
+			case old := <-a.queue:
+				atomic.AddUint64(&a.dropped, 1)
+				a.pool.Put(old.content)
+			default:
+			}
+		}
+	default:
+		a.queue <- e
+	}
+}
+
+// 等待队列中已有的日志输出完成
+func (a *asyncCore) flush() {
+	a.mutex.RLock()
+	closed := a.closed
+	a.mutex.RUnlock()
+	if closed {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case a.flushReq <- done:
+		<-done
+	case <-a.done:
+	}
+}
+
+// 关闭异步输出核心（输出队列中剩余的日志后停止分发协程）
+func (a *asyncCore) close() {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return
+	}
+	a.closed = true
+	a.mutex.Unlock()
+	close(a.stop)
+	<-a.done
+}
+
+// 异步输出日志
+//
+//	@return	是否已入队（异步核心已关闭时返回false，由调用方同步输出）
+func (b *belog) asyncPrint(t time.Time, l Level, stack bool, fn string, ln int, mn string, msg string, val ...field.Field) bool {
+	a := b.async
+	a.mutex.RLock()
+	if a.closed {
+		a.mutex.RUnlock()
+		return false
+	}
+
+	// 获取当前适配器注册表
+	r := b.loadAdapters()
+
+	// 普通适配器及标记输出的适配器共用记录器编码器的输出
+	if len(r.adapters) > 0 || len(r.tagAdapters) > 0 {
+		dst := a.pool.Get()
+		if stack {
+			dst = b.encoder.EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
+		} else {
+			dst = b.encoder.Encode(dst, t, l, msg, val...)
+		}
+		// 标记需在调用方生成，每个适配器单独入队（各自持有一份日志内容）
+		for _, adapter := range r.tagAdapters {
+			content := append(a.pool.Get(), dst...)
+			tag := adapter.Tag(l, msg, val)
+			a.push(asyncEntry{t: t, l: l, content: content, stack: stack, fn: fn, ln: ln, mn: mn, tagger: adapter, tag: tag})
+		}
+		if len(r.adapters) > 0 {
+			a.push(asyncEntry{t: t, l: l, content: dst, stack: stack, fn: fn, ln: ln, mn: mn})
+		} else {
+			a.pool.Put(dst)
+		}
+	}
+
+	// 自带编码器的适配器需要单独编码（字段可能引用调用方数据，需在调用方完成编码）
+	for _, adapter := range r.encoderAdapters {
+		dst := a.pool.Get()
+		if stack {
+			dst = adapter.Encoder().EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
+		} else {
+			dst = adapter.Encoder().Encode(dst, t, l, msg, val...)
+		}
+		a.push(asyncEntry{t: t, l: l, content: dst, stack: stack, fn: fn, ln: ln, mn: mn, adapter: adapter})
+	}
+
+	a.mutex.RUnlock()
+	return true
+}
+
+// 分发协程输出一条日志（依次调用各适配器）
+func (b *belog) asyncDispatch(e *asyncEntry) {
+	if e.adapter != nil {
+		if e.stack {
+			e.adapter.PrintStack(e.t, e.l, e.content, e.fn, e.ln, e.mn)
+		} else {
+			e.adapter.Print(e.t, e.l, e.content)
+		}
+		return
+	}
+	if e.tagger != nil {
+		if e.stack {
+			e.tagger.PrintStackTag(e.t, e.l, e.content, e.tag, e.fn, e.ln, e.mn)
+		} else {
+			e.tagger.PrintTag(e.t, e.l, e.content, e.tag)
+		}
+		return
+	}
+
+	for _, adapter := range b.loadAdapters().adapters {
+		if e.stack {
+			adapter.PrintStack(e.t, e.l, e.content, e.fn, e.ln, e.mn)
+		} else {
+			adapter.Print(e.t, e.l, e.content)
+		}
+	}
+}
+
+// AsyncDropped 获取异步队列已满时丢弃的日志条数
+//
+//	未启用异步输出时始终返回0，外部通过AsyncStats接口调用
+func (b *belog) AsyncDropped() uint64 {
+	if b.async == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&b.async.dropped)
+}
@@ -0,0 +1,288 @@
+/**
+ *@Title belog重复日志折叠
+ *@Desc 折叠窗口内相同的日志只记录第一条，被折叠的条数以汇总日志的形式输出
+ */
+
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/field"
+)
+
+// 重复日志折叠槽位数量（哈希冲突时后到的日志将先输出前一条日志的汇总）
+const dedupSlots = 1024
+
+// DedupKey 重复日志的判断依据
+type DedupKey uint8
+
+const (
+	DedupByContent DedupKey = 0 // 级别、消息及字段值均相同
+	DedupByCaller  DedupKey = 1 // 级别及调用位置（PC）相同
+)
+
+// DedupOption 重复日志折叠参数
+type DedupOption struct {
+	// 是否启用重复日志折叠
+	//
+	// Default: false
+	Enabled bool
+
+	// 折叠窗口，从窗口内第一条日志开始计算
+	//
+	// 窗口结束时输出被折叠条数的汇总日志（没有被折叠的日志时不输出）
+	//
+	// Unit: 毫秒, Default: 1000, Max: 3600000
+	Window uint
+
+	// 重复日志的判断依据
+	//
+	//	DedupByContent: 级别及消息相同且字段哈希相同的日志视为重复；
+	//	切片、复数、Objecter及Any等值存放在Field.Interface中的字段无法低成本比较，
+	//	包含这类字段的日志不参与折叠，每一条都会记录
+	//	DedupByCaller: 同一调用位置的日志视为重复，不比较消息及字段
+	//
+	// Default: DedupByContent
+	Key DedupKey
+}
+
+// 判断参数有效性
+func (p *DedupOption) validity() {
+	if p.Window == 0 || p.Window > 3600000 {
+		p.Window = 1000
+	}
+	if p.Key != DedupByCaller {
+		p.Key = DedupByContent
+	}
+}
+
+// 重复日志折叠槽位
+type dedupSlot struct {
+	mutex sync.Mutex
+	used  bool        // 是否已被占用
+	hash  uint64      // 日志哈希
+	until int64       // 折叠窗口结束时间（Unix纳秒）
+	count uint64      // 窗口内被折叠的条数
+	level Level       // 第一条日志的级别
+	msg   string      // 第一条日志的消息
+	timer *time.Timer // 窗口结束时输出汇总的定时器（窗口内有被折叠的日志时启动）
+}
+
+// 重复次数汇总
+type dedupSummary struct {
+	level Level
+	msg   string
+	count uint64
+}
+
+// 重复日志折叠器
+type deduper struct {
+	slots  [dedupSlots]dedupSlot               // 按哈希划分的槽位
+	window int64                               // 折叠窗口（纳秒）
+	key    DedupKey                            // 重复日志的判断依据
+	emit   func(t time.Time, sum dedupSummary) // 窗口结束时的汇总输出方法
+	mutex  sync.Mutex                          // 关闭锁
+	closed bool                                // 是否已关闭（关闭后不再启动定时器）
+}
+
+// 创建重复日志折叠器
+//
+//	@param	opt		重复日志折叠参数
+//	@param	emit	窗口结束时的汇总输出方法
+func newDeduper(opt DedupOption, emit func(t time.Time, sum dedupSummary)) *deduper {
+	opt.validity()
+	return &deduper{
+		window: int64(opt.Window) * int64(time.Millisecond),
+		key:    opt.Key,
+		emit:   emit,
+	}
+}
+
+// FNV-1a 64位哈希参数
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// 追加字符串到FNV-1a哈希（不产生内存分配）
+func fnv64aString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// 追加整型到FNV-1a哈希
+func fnv64aUint(h uint64, v uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= v & 0xff
+		h *= fnvPrime64
+		v >>= 8
+	}
+	return h
+}
+
+// 计算日志内容的哈希
+//
+//	@return	哈希值
+//	@return	是否可参与折叠（字段中包含复杂值时无法低成本比较）
+func contentHash(l Level, msg string, val ...field.Field) (uint64, bool) {
+	h := fnv64aUint(fnvOffset64, uint64(l))
+	h = fnv64aString(h, msg)
+	for i := range val {
+		if val[i].Interface != nil {
+			return 0, false
+		}
+		h = fnv64aString(h, val[i].Key)
+		h = fnv64aUint(h, uint64(val[i].Type))
+		h = fnv64aUint(h, uint64(val[i].Integer))
+		h = fnv64aString(h, val[i].String)
+	}
+	return h, true
+}
+
+// 判断日志是否需要记录
+//
+//	@param	t	日志记录时间
+//	@param	h	日志哈希
+//	@param	l	日志级别
+//	@param	msg	日志消息
+//	@return	同一槽位上一个窗口的重复次数汇总（count为0时无需输出）
+//	@return	是否记录
+func (d *deduper) check(t time.Time, h uint64, l Level, msg string) (dedupSummary, bool) {
+	now := t.UnixNano()
+	s := &d.slots[h%dedupSlots]
+	s.mutex.Lock()
+	// 窗口内的重复日志仅计数（哈希相同时还需比较级别及消息，避免哈希冲突时误折叠）
+	if s.used && s.hash == h && s.level == l && (d.key == DedupByCaller || s.msg == msg) && s.until > now {
+		s.count++
+		// 窗口内第一条被折叠的日志启动定时器，窗口结束时输出汇总
+		if s.timer == nil {
+			d.startTimer(s, time.Duration(s.until-now))
+		}
+		s.mutex.Unlock()
+		return dedupSummary{}, false
+	}
+	// 窗口已结束或槽位被其他日志占用，开始新的窗口
+	sum := dedupSummary{level: s.level, msg: s.msg, count: s.count}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.used, s.hash, s.until, s.count, s.level, s.msg = true, h, now+d.window, 0, l, msg
+	s.mutex.Unlock()
+	return sum, true
+}
+
+// 启动窗口结束时输出汇总的定时器（需持有槽位锁）
+func (d *deduper) startTimer(s *dedupSlot, after time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		return
+	}
+	hash, until := s.hash, s.until
+	s.timer = time.AfterFunc(after, func() {
+		s.mutex.Lock()
+		// 窗口已被新日志替换
+		if s.hash != hash || s.until != until {
+			s.mutex.Unlock()
+			return
+		}
+		s.timer = nil
+		// 汇总已在刷新时输出
+		if s.count == 0 {
+			s.mutex.Unlock()
+			return
+		}
+		sum := dedupSummary{level: s.level, msg: s.msg, count: s.count}
+		s.count = 0
+		s.mutex.Unlock()
+		d.emit(time.Now(), sum)
+	})
+}
+
+// 停止全部定时器，此后的汇总仅在刷新时输出
+func (d *deduper) close() {
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+	for i := range d.slots {
+		s := &d.slots[i]
+		s.mutex.Lock()
+		if s.timer != nil {
+			s.timer.Stop()
+			s.timer = nil
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// 取出全部尚未输出的重复次数汇总
+func (d *deduper) pending() []dedupSummary {
+	var sums []dedupSummary
+	for i := range d.slots {
+		s := &d.slots[i]
+		s.mutex.Lock()
+		if s.count > 0 {
+			sums = append(sums, dedupSummary{level: s.level, msg: s.msg, count: s.count})
+			s.count = 0
+		}
+		s.mutex.Unlock()
+	}
+	return sums
+}
+
+// 输出重复次数汇总日志（不含调用栈）
+func (b *belog) dedupPrint(t time.Time, sum dedupSummary) {
+	b.output(
+		t, sum.level, false, "", 0, "",
+		"previous message repeated "+strconv.FormatUint(sum.count, 10)+" times",
+		field.String("previous", sum.msg),
+		field.Uint64("repeated", sum.count),
+	)
+}
+
+// 输出全部尚未输出的重复次数汇总
+func (b *belog) dedupFlush() {
+	sums := b.dedup.pending()
+	if len(sums) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, sum := range sums {
+		b.dedupPrint(now, sum)
+	}
+}
+
+// 重复日志折叠判断
+//
+//	@return	是否记录
+func (s *StandardBelog) dedupCheck(t time.Time, l Level, msg string, val ...field.Field) bool {
+	var h uint64
+	if s.dedup.key == DedupByCaller {
+		// 0: runtime.Callers, 1: dedupCheck, 2: check, 3: Trace/Debug/..., 4: 调用方
+		var pcs [1]uintptr
+		if runtime.Callers(int(s.stackSkip), pcs[:]) == 0 {
+			return true
+		}
+		h = fnv64aUint(fnv64aUint(fnvOffset64, uint64(l)), uint64(pcs[0]))
+	} else {
+		var ok bool
+		if h, ok = contentHash(l, msg, val...); !ok {
+			return true
+		}
+	}
+
+	sum, logged := s.dedup.check(t, h, l, msg)
+	// 先输出上一个窗口的重复次数汇总
+	if sum.count > 0 {
+		s.dedupPrint(t, sum)
+	}
+	return logged
+}
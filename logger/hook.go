@@ -0,0 +1,157 @@
+/**
+ *@Title belog日志钩子
+ *@Desc 日志编码前按注册顺序依次执行钩子，钩子可修改日志记录或丢弃该日志
+ */
+
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bearki/belog/v3/field"
+)
+
+// Record 日志记录
+//
+//	钩子可直接修改记录中的各项内容，修改后的内容将用于编码输出
+type Record struct {
+	Time     time.Time     // 日志记录时间
+	Level    Level         // 日志级别
+	Message  string        // 日志描述
+	Fields   []field.Field // 日志内容字段（已复制，可直接增删）
+	FileName string        // 调用栈文件名（未启用调用栈打印时为空）
+	LineNo   int           // 调用栈行号（未启用调用栈打印时为0）
+	FuncName string        // 调用栈函数名（未启用调用栈打印时为空）
+}
+
+// AddField 追加日志内容字段
+//
+//	@param	val	需要追加的字段
+func (r *Record) AddField(val ...field.Field) {
+	r.Fields = append(r.Fields, val...)
+}
+
+// RemoveField 移除指定键的日志内容字段
+//
+//	@param	key	需要移除的字段键
+func (r *Record) RemoveField(key string) {
+	n := 0
+	for _, v := range r.Fields {
+		if v.Key != key {
+			r.Fields[n] = v
+			n++
+		}
+	}
+	r.Fields = r.Fields[:n]
+}
+
+// Hook 日志钩子接口
+type Hook interface {
+	// Fire 日志编码前执行
+	//
+	//	注意：钩子在记录日志的协程中同步执行，请勿执行耗时操作；
+	//	记录对象会被复用，请勿在返回后继续持有
+	//
+	//	@param	r	日志记录
+	//	@return	是否继续记录（返回false时丢弃该日志，后续钩子不再执行）
+	Fire(r *Record) bool
+}
+
+// HookFunc 函数形式的日志钩子
+type HookFunc func(r *Record) bool
+
+// Fire 日志编码前执行
+func (f HookFunc) Fire(r *Record) bool {
+	return f(r)
+}
+
+// 日志记录对象池
+var recordPool = sync.Pool{
+	New: func() interface{} {
+		return &Record{Fields: make([]field.Field, 0, 16)}
+	},
+}
+
+// AddHook 添加日志钩子
+//
+//	钩子按添加顺序执行（初始化参数中的钩子最先执行），
+//	在重复日志折叠及日志采样之后执行，需要统计全部日志时请使用Option.PreHooks
+//
+//	@param	hook	钩子实例
+//	@return	异常信息
+func (b *belog) AddHook(hook Hook) error {
+	// 钩子是否为空
+	if hook == nil {
+		return errors.New("the address of `hook` is null pointer")
+	}
+
+	// 串行化写操作
+	b.hooksMutex.Lock()
+	defer b.hooksMutex.Unlock()
+
+	// 复制当前钩子列表后整体替换
+	old := b.loadHooks()
+	hooks := make([]Hook, 0, len(old)+1)
+	hooks = append(hooks, old...)
+	hooks = append(hooks, hook)
+	b.hooks.Store(hooks)
+	return nil
+}
+
+// 获取当前钩子列表
+func (b *belog) loadHooks() []Hook {
+	hooks, _ := b.hooks.Load().([]Hook)
+	return hooks
+}
+
+// 执行钩子后输出日志
+//
+//	@return	是否已由钩子处理（已处理时无需继续输出）
+func (b *belog) hookOutput(t time.Time, l Level, stack bool, fn string, ln int, mn string, msg string, val ...field.Field) bool {
+	hooks := b.loadHooks()
+	if len(hooks) == 0 {
+		return false
+	}
+
+	r := newRecord(t, l, fn, ln, mn, msg, val...)
+	if fireHooks(hooks, r) {
+		b.encodeOutput(r.Time, r.Level, stack, r.FileName, r.LineNo, r.FuncName, r.Message, r.Fields...)
+	}
+	releaseRecord(r)
+	return true
+}
+
+// 从对象池中获取日志记录
+//
+//	复制日志内容，避免钩子修改调用方的字段切片
+func newRecord(t time.Time, l Level, fn string, ln int, mn string, msg string, val ...field.Field) *Record {
+	r := recordPool.Get().(*Record)
+	r.Time, r.Level, r.Message = t, l, msg
+	r.Fields = append(r.Fields[:0], val...)
+	r.FileName, r.LineNo, r.FuncName = fn, ln, mn
+	return r
+}
+
+// 清理引用后回收日志记录
+func releaseRecord(r *Record) {
+	for i := range r.Fields {
+		r.Fields[i] = field.Field{}
+	}
+	r.Fields = r.Fields[:0]
+	r.Message, r.FileName, r.FuncName = "", "", ""
+	recordPool.Put(r)
+}
+
+// 按顺序执行钩子
+//
+//	@return	是否继续记录
+func fireHooks(hooks []Hook, r *Record) bool {
+	for _, hook := range hooks {
+		if !hook.Fire(r) {
+			return false
+		}
+	}
+	return true
+}
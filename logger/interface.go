@@ -62,6 +62,86 @@ type Adapter interface {
 	Flush()
 }
 
+// EncoderAdapter 自带编码器的适配器接口
+//
+//	适配器需要使用特定的日志格式（如syslog、GELF）时可实现该接口，
+//	记录器将使用适配器自带的编码器编码日志后再调用Print或PrintStack，
+//	而不是将记录器编码器的输出交给适配器
+type EncoderAdapter interface {
+	Adapter
+
+	// Encoder 获取适配器自带的编码器
+	Encoder() Encoder
+}
+
+// EncoderWrapAdapter 基于记录器编码器生成编码器的适配器接口
+//
+//	适配器需要在记录器编码器的基础上调整日志格式（如控制台配色）时可实现该接口，
+//	设置适配器时若Encoder返回nil，记录器将自身的编码器交给WrapEncoder，
+//	返回值不为nil时按自带编码器的适配器处理，否则使用记录器编码器的输出
+type EncoderWrapAdapter interface {
+	EncoderAdapter
+
+	// WrapEncoder 基于记录器的编码器生成适配器使用的编码器
+	//
+	//	@param	enc	记录器的编码器
+	//	@return	适配器使用的编码器（为nil时使用记录器编码器的输出）
+	WrapEncoder(enc Encoder) Encoder
+}
+
+// TagAdapter 按日志元数据标记输出的适配器接口
+//
+//	适配器需要根据日志级别或字段分流（如按规则写入不同文件）时可实现该接口，
+//	记录器在记录日志的协程中调用Tag取得每条日志的标记，
+//	再将标记连同记录器编码器的输出交给PrintTag或PrintStackTag（不再调用Print或PrintStack），
+//	启用异步输出时标记随日志一起入队，被队列丢弃的日志不会输出到任何位置
+type TagAdapter interface {
+	Adapter
+
+	// Tag 根据日志元数据生成标记
+	//
+	//	注意：不能修改或持有val
+	//
+	//	@param	level	日志级别
+	//	@param	msg		日志描述
+	//	@param	val		日志内容字段
+	//	@return	日志标记
+	Tag(level Level, msg string, val []field.Field) uint64
+
+	// PrintTag 带标记的普通日志打印方法
+	//
+	//	@param	logTime	日记记录时间
+	//	@param	level	日志级别
+	//	@param	content	日志内容
+	//	@param	tag		日志标记
+	PrintTag(logTime time.Time, level Level, content []byte, tag uint64)
+
+	// PrintStackTag 带标记的调用栈日志打印方法
+	//
+	//	@param	logTime		日记记录时间
+	//	@param	level		日志级别
+	//	@param	content		日志内容
+	//	@param	tag			日志标记
+	//	@param	fileName	日志记录调用文件路径
+	//	@param	lineNo		日志记录调用文件行号
+	//	@param	methodName	日志记录调用函数名
+	PrintStackTag(logTime time.Time, level Level, content []byte, tag uint64, fileName string, lineNo int, methodName string)
+}
+
+// LevelAdapter 自定义最小记录级别的适配器接口
+//
+//	适配器需要保留低于记录器级别的日志（如内存环形缓冲区保留调试日志）时可实现该接口，
+//	低于记录器级别但不低于MinLevel的日志将只输出到这类适配器，
+//	这类日志不执行钩子、重复日志折叠及采样，且始终同步输出；
+//	不低于记录器级别的日志仍按原方式输出，适配器需自行忽略低于MinLevel的日志；
+//	仅对使用记录器编码器输出的普通适配器生效
+type LevelAdapter interface {
+	Adapter
+
+	// MinLevel 获取适配器需要记录的最小日志级别
+	MinLevel() Level
+}
+
 // BaseLogger 基础日志接口
 type BaseLogger interface {
 	SetAdapter(Adapter) error // 适配器设置
@@ -70,6 +150,34 @@ type BaseLogger interface {
 	Flush()                   // 日志缓存刷新
 }
 
+// HookAdder 可添加钩子的记录器接口
+//
+//	New创建的记录器均实现了该接口，需要在创建后添加钩子时通过类型断言调用，
+//	例如：l.(logger.HookAdder).AddHook(hook)
+type HookAdder interface {
+	AddHook(Hook) error // 日志钩子添加
+}
+
+// Closer 可关闭的记录器接口
+//
+//	New创建的记录器均实现了该接口，启用异步输出时需要通过类型断言调用Close，
+//	例如：l.(logger.Closer).Close()
+type Closer interface {
+	Close() // 关闭记录器（输出异步队列中的日志并刷新缓存）
+}
+
+// AsyncStats 异步输出统计接口
+//
+//	New创建的记录器均实现了该接口，需要监控异步队列丢弃的日志时通过类型断言调用，
+//	例如：
+//
+//	if s, ok := l.(logger.AsyncStats); ok {
+//		dropped := s.AsyncDropped()
+//	}
+type AsyncStats interface {
+	AsyncDropped() uint64 // 获取异步队列已满时丢弃的日志条数（未启用异步输出时始终为0）
+}
+
 // Logger 标准日志接口
 type Logger interface {
 	BaseLogger
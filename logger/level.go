@@ -1,5 +1,11 @@
 package logger
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Level 日志级别类型
 type Level uint8
 
@@ -48,3 +54,26 @@ func (l Level) String() string {
 	}
 	return " "
 }
+
+// ParseLevel 解析日志级别
+//
+//	支持级别名称（不区分大小写，warn与warning均可）及级别数字（1~6）
+//
+//	@param	s	日志级别字符串
+//	@return	日志级别
+//	@return	异常信息
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warn" {
+		return Warn, nil
+	}
+	for l, v := range levelStringMap {
+		if v == name {
+			return l, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= int(Trace) && n <= int(Fatal) {
+		return Level(n), nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
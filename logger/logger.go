@@ -12,8 +12,10 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bearki/belog/v3/field"
 	"github.com/bearki/belog/v3/pkg/pool"
 )
 
@@ -25,14 +27,46 @@ const stackBaseSkip uint = 4
 // 日志字节流对象池
 var logBytesPool = pool.NewBytesPool(100, 0, 1024)
 
+// 适配器注册表
+//
+//	注册表创建后不再修改，设置适配器时复制一份新的注册表并整体替换，
+//	日志输出时只需一次原子读取即可获得一致的适配器列表
+type adapterRegistry struct {
+	adapters        []Adapter        // 适配器列表
+	encoderAdapters []EncoderAdapter // 自带编码器的适配器列表
+	tagAdapters     []TagAdapter     // 按日志元数据标记输出的适配器列表
+	levelAdapters   []LevelAdapter   // 自定义最小记录级别的适配器列表（同时位于适配器列表中）
+	bypassLevel     Level            // 自定义最小记录级别中的最低级别（没有这类适配器时为0）
+}
+
+// 基于记录器编码器生成编码器的适配器
+type wrappedAdapter struct {
+	EncoderAdapter         // 原适配器
+	encoder        Encoder // 基于记录器编码器生成的编码器
+}
+
+// Encoder 获取基于记录器编码器生成的编码器
+func (w *wrappedAdapter) Encoder() Encoder {
+	return w.encoder
+}
+
+// 空适配器注册表
+var emptyRegistry = &adapterRegistry{}
+
 // 标准记录器
 type belog struct {
-	minLevel          Level              // 需要记录的最小日志级别
-	adaptersRWMutex   sync.RWMutex       // 适配器配置读写锁
-	adapters          map[string]Adapter // 适配器缓存映射
-	encoder           Encoder            // 编码器
-	stackSkip         uint               // 需要跳过的调用栈层数
-	enabledStackPrint bool               // 是否打印调用栈
+	minLevel          Level        // 需要记录的最小日志级别
+	adaptersMutex     sync.Mutex   // 适配器设置互斥锁（仅用于串行化写操作）
+	registry          atomic.Value // 适配器注册表（*adapterRegistry）
+	encoder           Encoder      // 编码器
+	stackSkip         uint         // 需要跳过的调用栈层数
+	enabledStackPrint bool         // 是否打印调用栈
+	async             *asyncCore   // 异步输出核心（未启用时为nil）
+	sampler           *sampler     // 日志采样器（未启用时为nil）
+	dedup             *deduper     // 重复日志折叠器（未启用时为nil）
+	hooksMutex        sync.Mutex   // 钩子添加互斥锁（仅用于串行化写操作）
+	hooks             atomic.Value // 日志钩子列表（[]Hook）
+	preHooks          []Hook       // 抑制前日志钩子列表（创建后不再修改）
 }
 
 // 获取调用栈信息
@@ -71,6 +105,7 @@ func New(option Option, adapter ...Adapter) (Logger, error) {
 		stackSkip:         stackBaseSkip, // 初始为默认最小跳过层数
 		enabledStackPrint: option.EnabledStackPrint,
 	}
+	bl.registry.Store(emptyRegistry)
 
 	// 初始化适配器
 	for _, v := range adapter {
@@ -80,6 +115,37 @@ func New(option Option, adapter ...Adapter) (Logger, error) {
 		}
 	}
 
+	// 初始化钩子
+	for _, v := range option.Hooks {
+		err := bl.AddHook(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 初始化抑制前钩子
+	for _, v := range option.PreHooks {
+		if v == nil {
+			return nil, errors.New("the address of `hook` is null pointer")
+		}
+		bl.preHooks = append(bl.preHooks, v)
+	}
+
+	// 启用日志采样
+	if option.Sampling.Enabled {
+		bl.sampler = newSampler(option.Sampling)
+	}
+
+	// 启用重复日志折叠
+	if option.Dedup.Enabled {
+		bl.dedup = newDeduper(option.Dedup, bl.dedupPrint)
+	}
+
+	// 启用异步输出
+	if option.Async.Enabled {
+		bl.async = newAsyncCore(option.Async, bl.asyncDispatch)
+	}
+
 	// 返回标准记录器
 	return &StandardBelog{
 		belog: bl,
@@ -101,21 +167,92 @@ func (b *belog) SetAdapter(adapter Adapter) error {
 		return errors.New("the return value of `Name()` is empty")
 	}
 
-	// 加个写锁
-	b.adaptersRWMutex.Lock()
-	defer b.adaptersRWMutex.Unlock()
+	// 串行化写操作
+	b.adaptersMutex.Lock()
+	defer b.adaptersMutex.Unlock()
+
+	// 是否为自带编码器的适配器
+	ea, isEncoder := adapter.(EncoderAdapter)
+	isEncoder = isEncoder && ea.Encoder() != nil
+	// 基于记录器的编码器生成编码器
+	if wa, ok := adapter.(EncoderWrapAdapter); ok && !isEncoder {
+		if enc := wa.WrapEncoder(b.encoder); enc != nil {
+			ea, isEncoder = &wrappedAdapter{EncoderAdapter: wa, encoder: enc}, true
+		}
+	}
+	// 是否为按日志元数据标记输出的适配器（自带编码器时优先按自带编码器处理）
+	ta, isTag := adapter.(TagAdapter)
+	isTag = isTag && !isEncoder
+
+	// 复制当前注册表，同名适配器原位替换（类型变化时移除）
+	old := b.loadAdapters()
+	name := adapter.Name()
+	replaced := false
+	r := &adapterRegistry{
+		adapters:        make([]Adapter, 0, len(old.adapters)+1),
+		encoderAdapters: make([]EncoderAdapter, 0, len(old.encoderAdapters)+1),
+		tagAdapters:     make([]TagAdapter, 0, len(old.tagAdapters)+1),
+	}
+	for _, a := range old.adapters {
+		switch {
+		case a.Name() != name:
+			r.adapters = append(r.adapters, a)
+		case !isEncoder && !isTag:
+			r.adapters = append(r.adapters, adapter)
+			replaced = true
+		}
+	}
+	for _, a := range old.encoderAdapters {
+		switch {
+		case a.Name() != name:
+			r.encoderAdapters = append(r.encoderAdapters, a)
+		case isEncoder:
+			r.encoderAdapters = append(r.encoderAdapters, ea)
+			replaced = true
+		}
+	}
+	for _, a := range old.tagAdapters {
+		switch {
+		case a.Name() != name:
+			r.tagAdapters = append(r.tagAdapters, a)
+		case isTag:
+			r.tagAdapters = append(r.tagAdapters, ta)
+			replaced = true
+		}
+	}
 
-	// map为空需要初始化
-	if b.adapters == nil {
-		b.adapters = make(map[string]Adapter)
+	// 新增适配器
+	if !replaced {
+		switch {
+		case isEncoder:
+			r.encoderAdapters = append(r.encoderAdapters, ea)
+		case isTag:
+			r.tagAdapters = append(r.tagAdapters, ta)
+		default:
+			r.adapters = append(r.adapters, adapter)
+		}
 	}
 
-	// 赋值适配器操作方法
-	b.adapters[adapter.Name()] = adapter
+	// 统计自定义最小记录级别的适配器
+	for _, a := range r.adapters {
+		if la, ok := a.(LevelAdapter); ok {
+			r.levelAdapters = append(r.levelAdapters, la)
+			if l := la.MinLevel(); r.bypassLevel == 0 || l < r.bypassLevel {
+				r.bypassLevel = l
+			}
+		}
+	}
 
+	// 整体替换注册表
+	b.registry.Store(r)
 	return nil
 }
 
+// 获取当前适配器注册表
+func (b *belog) loadAdapters() *adapterRegistry {
+	return b.registry.Load().(*adapterRegistry)
+}
+
 // SetLevel 设置日志记录保存级别
 //
 //	@param	level	日志最小记录级别
@@ -132,11 +269,39 @@ func (b *belog) SetSkip(skip uint) {
 }
 
 // Flush 日志缓存刷新
+//
+//	启用重复日志折叠时将先输出尚未输出的重复次数汇总，
+//	启用异步输出时将先等待队列中已有的日志输出完成
 func (b *belog) Flush() {
+	// 输出重复次数汇总
+	if b.dedup != nil {
+		b.dedupFlush()
+	}
+
+	// 等待异步队列输出完成
+	if b.async != nil {
+		b.async.flush()
+	}
+
 	// 协程等待组
 	var wg sync.WaitGroup
+	r := b.loadAdapters()
 	// 遍历适配器
-	for _, adapter := range b.adapters {
+	for _, adapter := range r.adapters {
+		wg.Add(1)
+		go func(a Adapter) {
+			defer wg.Done()
+			a.Flush()
+		}(adapter)
+	}
+	for _, adapter := range r.encoderAdapters {
+		wg.Add(1)
+		go func(a Adapter) {
+			defer wg.Done()
+			a.Flush()
+		}(adapter)
+	}
+	for _, adapter := range r.tagAdapters {
 		wg.Add(1)
 		go func(a Adapter) {
 			defer wg.Done()
@@ -147,52 +312,117 @@ func (b *belog) Flush() {
 	wg.Wait()
 }
 
+// Close 关闭日志记录器
+//
+//	启用重复日志折叠时将停止汇总定时器并输出尚未输出的重复次数汇总，此后的汇总仅在刷新时输出；
+//	启用异步输出时将输出队列中剩余的日志并停止分发协程，此后的日志将同步输出；
+//	最后刷新全部适配器的缓存
+func (b *belog) Close() {
+	if b.dedup != nil {
+		b.dedup.close()
+		b.dedupFlush()
+	}
+	if b.async != nil {
+		b.async.close()
+	}
+	b.Flush()
+}
+
+// 执行钩子后编码并输出日志到全部适配器
+//
+//	@param	t		日志记录时间
+//	@param	l		日志级别
+//	@param	stack	是否打印调用栈
+//	@param	fn		调用栈文件名
+//	@param	ln		调用栈行号
+//	@param	mn		调用栈函数名
+//	@param	msg		日志描述
+//	@param	val		日志内容字段
+func (b *belog) output(t time.Time, l Level, stack bool, fn string, ln int, mn string, msg string, val ...field.Field) {
+	// 执行钩子
+	if b.hookOutput(t, l, stack, fn, ln, mn, msg, val...) {
+		return
+	}
+	b.encodeOutput(t, l, stack, fn, ln, mn, msg, val...)
+}
+
+// 编码并输出日志到全部适配器（不执行钩子）
+func (b *belog) encodeOutput(t time.Time, l Level, stack bool, fn string, ln int, mn string, msg string, val ...field.Field) {
+	// 异步输出
+	if b.async != nil && b.asyncPrint(t, l, stack, fn, ln, mn, msg, val...) {
+		return
+	}
+
+	// 获取当前适配器注册表
+	r := b.loadAdapters()
+
+	// 从对象池中获取一个日志字节流对象
+	dst := logBytesPool.Get()
+
+	if stack {
+		if len(r.adapters) > 0 || len(r.tagAdapters) > 0 {
+			dst = b.encoder.EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
+			if len(r.adapters) > 0 {
+				b.adapterPrintStack(r.adapters, t, l, dst, fn, ln, mn)
+			}
+			// 标记输出的适配器共用记录器编码器的输出
+			if len(r.tagAdapters) > 0 {
+				b.tagAdapterPrint(r.tagAdapters, t, l, dst, true, fn, ln, mn, msg, val)
+			}
+		}
+		// 自带编码器的适配器需要单独编码
+		if len(r.encoderAdapters) > 0 {
+			b.encoderAdapterPrint(r.encoderAdapters, t, l, true, fn, ln, mn, msg, val...)
+		}
+	} else {
+		if len(r.adapters) > 0 || len(r.tagAdapters) > 0 {
+			dst = b.encoder.Encode(dst, t, l, msg, val...)
+			if len(r.adapters) > 0 {
+				b.adapterPrint(r.adapters, t, l, dst)
+			}
+			// 标记输出的适配器共用记录器编码器的输出
+			if len(r.tagAdapters) > 0 {
+				b.tagAdapterPrint(r.tagAdapters, t, l, dst, false, "", 0, "", msg, val)
+			}
+		}
+		// 自带编码器的适配器需要单独编码
+		if len(r.encoderAdapters) > 0 {
+			b.encoderAdapterPrint(r.encoderAdapters, t, l, false, "", 0, "", msg, val...)
+		}
+	}
+
+	// 避免使用defer，会有些许性能损耗
+	// 回收切片
+	logBytesPool.Put(dst)
+}
+
 // 筛选合适的适配器
-func (b *belog) adapterPrint(t time.Time, l Level, c []byte) {
+func (b *belog) adapterPrint(adapters []Adapter, t time.Time, l Level, c []byte) {
 	// 是否为单适配器输出
-	if len(b.adapters) <= 1 {
+	if len(adapters) <= 1 {
 		// 单适配器输出
-		b.singleAdapterPrint(t, l, c)
+		b.singleAdapterPrint(adapters, t, l, c)
 		return
 	}
 	// 多适配器并发输出
-	b.multipleAdapterPrint(t, l, c)
+	b.multipleAdapterPrint(adapters, t, l, c)
 }
 
 // 单适配器输出
-func (b *belog) singleAdapterPrint(t time.Time, l Level, c []byte) {
-	// 加个读锁
-	b.adaptersRWMutex.RLock()
-
-	// 适配器是否为空
-	if b.adapters == nil {
-		return
-	}
-
+func (b *belog) singleAdapterPrint(adapters []Adapter, t time.Time, l Level, c []byte) {
 	// 遍历所有适配器
-	for _, adapter := range b.adapters {
+	for _, adapter := range adapters {
 		adapter.Print(t, l, c)
 	}
-
-	// 释放读锁
-	b.adaptersRWMutex.RUnlock()
 }
 
 // 多适配器输出
-func (b *belog) multipleAdapterPrint(t time.Time, l Level, c []byte) {
-	// 加个读锁
-	b.adaptersRWMutex.RLock()
-
-	// 适配器是否为空
-	if b.adapters == nil {
-		return
-	}
-
+func (b *belog) multipleAdapterPrint(adapters []Adapter, t time.Time, l Level, c []byte) {
 	// 协程等待分组（WaitGroup会增加1个开销）
 	var wg sync.WaitGroup
 
 	// 遍历所有适配器
-	for _, adapter := range b.adapters {
+	for _, adapter := range adapters {
 		wg.Add(1)
 		go func(a Adapter) {
 			defer wg.Done()
@@ -202,57 +432,35 @@ func (b *belog) multipleAdapterPrint(t time.Time, l Level, c []byte) {
 
 	// 等待所有适配器完成日志记录
 	wg.Wait()
-
-	// 释放读锁
-	b.adaptersRWMutex.RUnlock()
 }
 
 // 筛选合适的调用栈适配器
-func (b *belog) adapterPrintStack(t time.Time, l Level, c []byte, fn string, ln int, mn string) {
+func (b *belog) adapterPrintStack(adapters []Adapter, t time.Time, l Level, c []byte, fn string, ln int, mn string) {
 	// 是否为单适配器输出
-	if len(b.adapters) <= 1 {
+	if len(adapters) <= 1 {
 		// 单适配器输出
-		b.singleAdapterPrintStack(t, l, c, fn, ln, mn)
+		b.singleAdapterPrintStack(adapters, t, l, c, fn, ln, mn)
 		return
 	}
 	// 多适配器并发输出
-	b.multipleAdapterPrintStack(t, l, c, fn, ln, mn)
+	b.multipleAdapterPrintStack(adapters, t, l, c, fn, ln, mn)
 }
 
 // 单适配器输出调用栈
-func (b *belog) singleAdapterPrintStack(t time.Time, l Level, c []byte, fn string, ln int, mn string) {
-	// 加个读锁
-	b.adaptersRWMutex.RLock()
-
-	// 适配器是否为空
-	if b.adapters == nil {
-		return
-	}
-
+func (b *belog) singleAdapterPrintStack(adapters []Adapter, t time.Time, l Level, c []byte, fn string, ln int, mn string) {
 	// 遍历所有适配器
-	for _, adapter := range b.adapters {
+	for _, adapter := range adapters {
 		adapter.PrintStack(t, l, c, fn, ln, mn)
 	}
-
-	// 释放读锁
-	b.adaptersRWMutex.RUnlock()
 }
 
 // 多适配器输出调用栈
-func (b *belog) multipleAdapterPrintStack(t time.Time, l Level, c []byte, fn string, ln int, mn string) {
-	// 加个读锁
-	b.adaptersRWMutex.RLock()
-
-	// 适配器是否为空
-	if b.adapters == nil {
-		return
-	}
-
+func (b *belog) multipleAdapterPrintStack(adapters []Adapter, t time.Time, l Level, c []byte, fn string, ln int, mn string) {
 	// 协程等待分组（WaitGroup会增加1个开销）
 	var wg sync.WaitGroup
 
 	// 遍历所有适配器
-	for _, adapter := range b.adapters {
+	for _, adapter := range adapters {
 		wg.Add(1)
 		go func(a Adapter) {
 			defer wg.Done()
@@ -262,7 +470,96 @@ func (b *belog) multipleAdapterPrintStack(t time.Time, l Level, c []byte, fn str
 
 	// 等待所有适配器完成日志记录
 	wg.Wait()
+}
 
-	// 释放读锁
-	b.adaptersRWMutex.RUnlock()
+// 自带编码器的适配器输出
+//
+//	@param	adapters	自带编码器的适配器列表
+//	@param	t			日志记录时间
+//	@param	l			日志级别
+//	@param	stack		是否打印调用栈
+//	@param	fn			调用栈文件名
+//	@param	ln			调用栈行号
+//	@param	mn			调用栈函数名
+//	@param	msg			日志描述
+//	@param	val			日志内容字段
+func (b *belog) encoderAdapterPrint(adapters []EncoderAdapter, t time.Time, l Level, stack bool, fn string, ln int, mn string, msg string, val ...field.Field) {
+	// 遍历所有自带编码器的适配器
+	for _, adapter := range adapters {
+		dst := logBytesPool.Get()
+		if stack {
+			dst = adapter.Encoder().EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
+			adapter.PrintStack(t, l, dst, fn, ln, mn)
+		} else {
+			dst = adapter.Encoder().Encode(dst, t, l, msg, val...)
+			adapter.Print(t, l, dst)
+		}
+		logBytesPool.Put(dst)
+	}
+}
+
+// 按日志元数据标记输出的适配器输出
+//
+//	@param	adapters	按日志元数据标记输出的适配器列表
+//	@param	t			日志记录时间
+//	@param	l			日志级别
+//	@param	c			记录器编码器输出的日志内容
+//	@param	stack		是否打印调用栈
+//	@param	fn			调用栈文件名
+//	@param	ln			调用栈行号
+//	@param	mn			调用栈函数名
+//	@param	msg			日志描述
+//	@param	val			日志内容字段
+func (b *belog) tagAdapterPrint(adapters []TagAdapter, t time.Time, l Level, c []byte, stack bool, fn string, ln int, mn string, msg string, val []field.Field) {
+	// 遍历所有标记输出的适配器
+	for _, adapter := range adapters {
+		tag := adapter.Tag(l, msg, val)
+		if stack {
+			adapter.PrintStackTag(t, l, c, tag, fn, ln, mn)
+		} else {
+			adapter.PrintTag(t, l, c, tag)
+		}
+	}
+}
+
+// 输出低于记录器级别的日志到自定义最小记录级别的适配器
+//
+//	不执行钩子、重复日志折叠及采样，始终同步输出
+//
+//	@param	l	日志级别
+//	@param	msg	日志描述
+//	@param	val	日志内容字段
+func (b *belog) belowLevelOutput(l Level, msg string, val ...field.Field) {
+	// 是否有需要记录该级别的适配器
+	r := b.loadAdapters()
+	if r.bypassLevel == 0 || l < r.bypassLevel || b.encoder == nil {
+		return
+	}
+
+	// 是否需要调用栈（调用栈层数与format一致）
+	var fn, mn string
+	var ln int
+	if b.enabledStackPrint {
+		fn, ln, mn = getCallStack(b.stackSkip)
+	}
+
+	// 编码后输出到需要记录该级别的适配器
+	t := time.Now()
+	dst := logBytesPool.Get()
+	if b.enabledStackPrint {
+		dst = b.encoder.EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
+	} else {
+		dst = b.encoder.Encode(dst, t, l, msg, val...)
+	}
+	for _, adapter := range r.levelAdapters {
+		if l < adapter.MinLevel() {
+			continue
+		}
+		if b.enabledStackPrint {
+			adapter.PrintStack(t, l, dst, fn, ln, mn)
+		} else {
+			adapter.Print(t, l, dst)
+		}
+	}
+	logBytesPool.Put(dst)
 }
@@ -9,4 +9,42 @@ type Option struct {
 
 	// Encoder 日志内容编码器
 	Encoder Encoder
+
+	// Async 异步输出参数
+	//
+	// Default: 不启用（调用方同步输出到全部适配器）
+	Async AsyncOption
+
+	// Sampling 日志采样参数
+	//
+	// 同一级别、同一消息的日志在每个采样周期内先全部记录First条，
+	// 此后每Thereafter条记录1条，用于避免循环中的日志刷屏
+	//
+	// Default: 不启用
+	Sampling SamplingOption
+
+	// Dedup 重复日志折叠参数
+	//
+	// 折叠窗口内的重复日志只记录第一条，窗口结束后
+	// 输出一条“previous message repeated N times”的汇总日志
+	//
+	// Default: 不启用
+	Dedup DedupOption
+
+	// Hooks 日志钩子
+	//
+	// 日志编码前按顺序执行，可修改日志记录或丢弃该日志；
+	// 在重复日志折叠及日志采样之后执行，被折叠或采样丢弃的日志不会执行
+	//
+	// Default: nil
+	Hooks []Hook
+
+	// PreHooks 抑制前日志钩子
+	//
+	// 在重复日志折叠及日志采样之前按顺序执行，每一条达到记录级别的日志都会执行，
+	// 可用于统计指标或提前丢弃日志（被丢弃的日志不计入折叠及采样）；
+	// 修改后的日志记录将用于折叠、采样及后续的钩子，此时尚未获取调用栈
+	//
+	// Default: nil
+	PreHooks []Hook
 }
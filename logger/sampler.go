@@ -0,0 +1,155 @@
+/**
+ *@Title belog日志采样
+ *@Desc 按（级别, 消息）统计每个时间周期内的日志条数，前N条全部记录，此后每M条记录1条
+ */
+
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 每个级别的采样计数器数量（消息哈希冲突时共享计数器）
+const samplerCountersPerLevel = 4096
+
+// SamplingDecision 采样结果
+type SamplingDecision uint8
+
+const (
+	SamplingLogged  SamplingDecision = 1 // 已记录
+	SamplingDropped SamplingDecision = 2 // 已丢弃
+)
+
+// SamplingHook 采样结果回调（可用于统计指标）
+//
+//	注意：回调在记录日志的协程中同步执行，请勿执行耗时操作
+//
+//	@param	level		日志级别
+//	@param	msg			日志消息
+//	@param	decision	采样结果
+type SamplingHook func(level Level, msg string, decision SamplingDecision)
+
+// SamplingOption 日志采样参数
+type SamplingOption struct {
+	// 是否启用日志采样
+	//
+	// Default: false
+	Enabled bool
+
+	// 采样周期
+	//
+	// Unit: 毫秒, Default: 1000, Max: 3600000
+	Tick uint
+
+	// 每个周期内同一级别、同一消息全部记录的条数
+	//
+	// Default: 100
+	First uint
+
+	// 超过First后每Thereafter条记录1条
+	//
+	// Default: 100
+	Thereafter uint
+
+	// 采样结果回调
+	//
+	// Default: nil
+	Hook SamplingHook
+}
+
+// 判断参数有效性
+func (p *SamplingOption) validity() {
+	if p.Tick == 0 || p.Tick > 3600000 {
+		p.Tick = 1000
+	}
+	if p.First == 0 {
+		p.First = 100
+	}
+	if p.Thereafter == 0 {
+		p.Thereafter = 100
+	}
+}
+
+// 采样计数器
+type samplerCounter struct {
+	resetAt int64  // 当前周期结束时间（Unix纳秒，原子操作）
+	count   uint64 // 当前周期内的条数（原子操作）
+}
+
+// 增加计数
+//
+//	周期结束后重置计数，重置时的并发竞争只会导致少量日志计入错误的周期
+func (c *samplerCounter) incr(now int64, tick int64) uint64 {
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if resetAt > now {
+		return atomic.AddUint64(&c.count, 1)
+	}
+	// 进入新的周期
+	if atomic.CompareAndSwapInt64(&c.resetAt, resetAt, now+tick) {
+		atomic.StoreUint64(&c.count, 1)
+		return 1
+	}
+	return atomic.AddUint64(&c.count, 1)
+}
+
+// 日志采样器
+type sampler struct {
+	counters   [Fatal + 1][samplerCountersPerLevel]samplerCounter // 按级别及消息哈希划分的计数器（原子操作，需保持64位对齐）
+	tick       int64                                              // 采样周期（纳秒）
+	first      uint64                                             // 每个周期内全部记录的条数
+	thereafter uint64                                             // 此后每多少条记录1条
+	hook       SamplingHook                                       // 采样结果回调
+}
+
+// 创建日志采样器
+func newSampler(opt SamplingOption) *sampler {
+	opt.validity()
+	return &sampler{
+		tick:       int64(opt.Tick) * int64(time.Millisecond),
+		first:      uint64(opt.First),
+		thereafter: uint64(opt.Thereafter),
+		hook:       opt.Hook,
+	}
+}
+
+// 计算消息的FNV-1a哈希（不产生内存分配）
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+// 判断日志是否需要记录
+//
+//	@param	t	日志记录时间
+//	@param	l	日志级别
+//	@param	msg	日志消息
+//	@return	是否记录
+func (s *sampler) check(t time.Time, l Level, msg string) bool {
+	// 未定义的级别不参与采样
+	if l > Fatal {
+		return true
+	}
+
+	c := &s.counters[l][fnv32a(msg)%samplerCountersPerLevel]
+	n := c.incr(t.UnixNano(), s.tick)
+	logged := n <= s.first || (n-s.first)%s.thereafter == 0
+
+	// 回调采样结果
+	if s.hook != nil {
+		if logged {
+			s.hook(l, msg, SamplingLogged)
+		} else {
+			s.hook(l, msg, SamplingDropped)
+		}
+	}
+	return logged
+}
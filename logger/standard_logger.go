@@ -20,28 +20,22 @@ type StandardBelog struct {
 
 // 序列化格式
 func (s *StandardBelog) format(t time.Time, l Level, msg string, val ...field.Field) {
-	// 从对象池中获取一个日志字节流对象
-	dst := logBytesPool.Get()
-
 	// 是否需要调用栈
+	var fn, mn string
+	var ln int
 	if s.enabledStackPrint {
-		fn, ln, mn := getCallStack(s.stackSkip)
-		dst = s.encoder.EncodeStack(dst, t, l, fn, ln, mn, msg, val...)
-		s.adapterPrintStack(t, l, dst, fn, ln, mn)
-	} else {
-		dst = s.encoder.Encode(dst, t, l, msg, val...)
-		s.adapterPrint(t, l, dst)
+		fn, ln, mn = getCallStack(s.stackSkip)
 	}
 
-	// 避免使用defer，会有些许性能损耗
-	// 回收切片
-	logBytesPool.Put(dst)
+	// 输出日志
+	s.output(t, l, s.enabledStackPrint, fn, ln, mn, msg, val...)
 }
 
 // 高性能日志前置判断和序列化
 func (s *StandardBelog) check(l Level, msg string, val ...field.Field) {
-	// 判断当前级别日志是否需要记录
+	// 判断当前级别日志是否需要记录（低于记录器级别时仅输出到自定义最小记录级别的适配器）
 	if l < s.minLevel {
+		s.belowLevelOutput(l, msg, val...)
 		return
 	}
 
@@ -52,6 +46,26 @@ func (s *StandardBelog) check(l Level, msg string, val ...field.Field) {
 
 	// 获取当前时间
 	now := time.Now()
+
+	// 执行抑制前钩子（修改后的日志记录用于后续处理）
+	if len(s.preHooks) > 0 {
+		r := newRecord(now, l, "", 0, "", msg, val...)
+		defer releaseRecord(r)
+		if !fireHooks(s.preHooks, r) {
+			return
+		}
+		now, l, msg, val = r.Time, r.Level, r.Message, r.Fields
+	}
+
+	// 重复日志折叠
+	if s.dedup != nil && !s.dedupCheck(now, l, msg, val...) {
+		return
+	}
+
+	// 日志采样
+	if s.sampler != nil && !s.sampler.check(now, l, msg) {
+		return
+	}
 	// 执行格式化打印
 	s.format(now, l, msg, val...)
 }
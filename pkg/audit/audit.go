@@ -0,0 +1,210 @@
+/**
+ *@Title 日志哈希链
+ *@Desc 为每条日志追加HMAC-SHA256链值，任何一条日志被修改、删除或调换顺序都会导致后续链值校验失败
+ */
+
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"strconv"
+)
+
+// 哈希链格式相关常量
+//
+//	分段头：#belog-audit v1 seq=<上一条日志序号> prev=<上一条日志链值>\r\n
+//	日志：<日志内容>\t<序号>:<链值>\r\n
+//	链值：HMAC-SHA256(key, 上一条日志链值 + 序号(8字节大端) + 日志内容)
+const (
+	HeaderPrefix = "#belog-audit v1 " // 分段头前缀
+	SumSize      = sha256.Size        // 链值大小
+)
+
+// Chain 日志哈希链
+//
+//	注意：非并发安全
+type Chain struct {
+	mac  hash.Hash     // 链值计算器
+	seq  uint64        // 上一条日志序号
+	prev [SumSize]byte // 上一条日志链值
+	sum  []byte        // 链值计算缓冲区
+}
+
+// 创建链值计算器
+//
+//	密钥为空时退化为SHA-256，只能发现意外的修改
+func newMac(key []byte) hash.Hash {
+	if len(key) == 0 {
+		return sha256.New()
+	}
+	return hmac.New(sha256.New, key)
+}
+
+// NewChain 创建日志哈希链
+//
+//	@param	key	HMAC密钥
+//	@return	日志哈希链（从序号0、全零链值开始）
+func NewChain(key []byte) *Chain {
+	return &Chain{mac: newMac(key)}
+}
+
+// Resume 从指定位置继续哈希链
+//
+//	@param	seq		上一条日志序号
+//	@param	prev	上一条日志链值
+func (c *Chain) Resume(seq uint64, prev []byte) {
+	c.seq = seq
+	c.prev = [SumSize]byte{}
+	copy(c.prev[:], prev)
+}
+
+// State 获取哈希链当前位置
+//
+//	@return	上一条日志序号
+//	@return	上一条日志链值
+func (c *Chain) State() (uint64, []byte) {
+	return c.seq, append([]byte(nil), c.prev[:]...)
+}
+
+// AppendHeader 追加分段头
+//
+//	每个新的日志分段都以分段头开始，用于将哈希链延续到新的分段
+//
+//	@param	dst	填充目标
+//	@return	填充后的内容
+func (c *Chain) AppendHeader(dst []byte) []byte {
+	return appendHeader(dst, c.seq, c.prev[:])
+}
+
+// Append 追加一条带链值的日志
+//
+//	日志末尾的换行符不参与链值计算，没有换行符时将补充\r\n
+//
+//	@param	dst		填充目标
+//	@param	record	日志内容
+//	@return	填充后的内容
+func (c *Chain) Append(dst []byte, record []byte) []byte {
+	body, term := splitTerminator(record)
+	if len(term) == 0 {
+		term = []byte("\r\n")
+	}
+	c.seq++
+	c.sum = computeSum(c.mac, c.sum[:0], c.prev[:], c.seq, body)
+	copy(c.prev[:], c.sum)
+
+	dst = append(dst, body...)
+	dst = append(dst, '\t')
+	dst = strconv.AppendUint(dst, c.seq, 10)
+	dst = append(dst, ':')
+	dst = appendHex(dst, c.sum)
+	return append(dst, term...)
+}
+
+// 计算链值
+func computeSum(mac hash.Hash, dst []byte, prev []byte, seq uint64, body []byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seq)
+	mac.Reset()
+	mac.Write(prev)
+	mac.Write(b[:])
+	mac.Write(body)
+	return mac.Sum(dst)
+}
+
+// 追加分段头
+func appendHeader(dst []byte, seq uint64, prev []byte) []byte {
+	dst = append(dst, HeaderPrefix...)
+	dst = append(dst, "seq="...)
+	dst = strconv.AppendUint(dst, seq, 10)
+	dst = append(dst, " prev="...)
+	dst = appendHex(dst, prev)
+	return append(dst, "\r\n"...)
+}
+
+// 追加十六进制编码
+func appendHex(dst []byte, src []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, hex.EncodedLen(len(src)))...)
+	hex.Encode(dst[n:], src)
+	return dst
+}
+
+// 拆分日志内容与末尾的换行符
+func splitTerminator(line []byte) ([]byte, []byte) {
+	n := len(line)
+	if n > 0 && line[n-1] == '\n' {
+		if n > 1 && line[n-2] == '\r' {
+			return line[:n-2], line[n-2:]
+		}
+		return line[:n-1], line[n-1:]
+	}
+	return line, nil
+}
+
+// ParseHeader 解析分段头
+//
+//	@param	line	一行内容（可包含末尾的换行符）
+//	@return	上一条日志序号
+//	@return	上一条日志链值
+//	@return	是否为有效的分段头
+func ParseHeader(line []byte) (uint64, []byte, bool) {
+	line, _ = splitTerminator(line)
+	if len(line) < len(HeaderPrefix) || string(line[:len(HeaderPrefix)]) != HeaderPrefix {
+		return 0, nil, false
+	}
+	line = line[len(HeaderPrefix):]
+	if len(line) < 4 || string(line[:4]) != "seq=" {
+		return 0, nil, false
+	}
+	line = line[4:]
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return 0, nil, false
+	}
+	seq, err := strconv.ParseUint(string(line[:i]), 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	line = line[i+1:]
+	if len(line) != 5+hex.EncodedLen(SumSize) || string(line[:5]) != "prev=" {
+		return 0, nil, false
+	}
+	prev := make([]byte, SumSize)
+	if _, err = hex.Decode(prev, line[5:]); err != nil {
+		return 0, nil, false
+	}
+	return seq, prev, true
+}
+
+// ParseRecord 解析一行带链值的日志
+//
+//	@param	line	一行内容（可包含末尾的换行符）
+//	@return	参与链值计算的日志内容
+//	@return	日志序号
+//	@return	日志链值
+//	@return	是否以有效的链值结尾
+func ParseRecord(line []byte) ([]byte, uint64, []byte, bool) {
+	line, _ = splitTerminator(line)
+	n := len(line) - hex.EncodedLen(SumSize)
+	if n < 3 || line[n-1] != ':' {
+		return nil, 0, nil, false
+	}
+	sum := make([]byte, SumSize)
+	if _, err := hex.Decode(sum, line[n:]); err != nil {
+		return nil, 0, nil, false
+	}
+	tab := bytes.LastIndexByte(line[:n-1], '\t')
+	if tab < 0 {
+		return nil, 0, nil, false
+	}
+	seq, err := strconv.ParseUint(string(line[tab+1:n-1]), 10, 64)
+	if err != nil {
+		return nil, 0, nil, false
+	}
+	return line[:tab], seq, sum, true
+}
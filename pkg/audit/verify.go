@@ -0,0 +1,216 @@
+/**
+ *@Title 日志哈希链校验
+ *@Desc 按顺序遍历日志分段，校验每条日志的链值并报告第一个断开的位置
+ */
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// BrokenLinkError 哈希链断开异常
+type BrokenLinkError struct {
+	Segment string // 日志分段名称
+	Line    int    // 断开位置所在的行号（从1开始）
+	Seq     uint64 // 期望的日志序号
+	Reason  string // 断开原因
+}
+
+// Error 实现error接口
+func (e *BrokenLinkError) Error() string {
+	return "belog/audit: " + e.Segment + ":" + strconv.Itoa(e.Line) +
+		": " + e.Reason + " (expected seq " + strconv.FormatUint(e.Seq, 10) + ")"
+}
+
+// Verifier 日志哈希链校验器
+//
+//	同一个校验器需要按写入顺序校验所有日志分段，
+//	每个分段的分段头都必须与上一个分段的最后一条日志衔接
+//
+//	注意：哈希链只能证明已校验的日志未被修改、删除或调换顺序，
+//	无法发现从末尾截断的日志或被整体删除的最早的分段，
+//	如需发现这两种情况，请在外部记录State返回的位置并通过Anchor指定校验起点
+type Verifier struct {
+	mac     hash.Hash     // 链值计算器
+	seq     uint64        // 上一条日志序号
+	prev    [SumSize]byte // 上一条日志链值
+	started bool          // 是否已确定校验起点
+	records uint64        // 已校验的日志条数
+	sum     []byte        // 链值计算缓冲区
+}
+
+// NewVerifier 创建日志哈希链校验器
+//
+//	@param	key	HMAC密钥
+//	@return	校验器（以第一个分段的分段头作为校验起点）
+func NewVerifier(key []byte) *Verifier {
+	return &Verifier{mac: newMac(key)}
+}
+
+// Anchor 指定校验起点
+//
+//	第一个分段的分段头必须与校验起点一致
+//
+//	@param	seq		上一条日志序号
+//	@param	prev	上一条日志链值
+func (v *Verifier) Anchor(seq uint64, prev []byte) {
+	v.seq = seq
+	v.prev = [SumSize]byte{}
+	copy(v.prev[:], prev)
+	v.started = true
+}
+
+// State 获取已校验的最后一条日志的位置
+//
+//	@return	日志序号
+//	@return	日志链值
+func (v *Verifier) State() (uint64, []byte) {
+	return v.seq, append([]byte(nil), v.prev[:]...)
+}
+
+// Records 获取已校验的日志条数
+func (v *Verifier) Records() uint64 {
+	return v.records
+}
+
+// Verify 校验一个日志分段
+//
+//	加密的日志分段可通过crypt.NewReader解密后传入
+//
+//	@param	segment	日志分段名称（用于异常信息）
+//	@param	r		日志分段内容
+//	@return	异常信息（哈希链断开时为*BrokenLinkError）
+func (v *Verifier) Verify(segment string, r io.Reader) error {
+	broken := func(line int, reason string) error {
+		return &BrokenLinkError{Segment: segment, Line: line, Seq: v.seq + 1, Reason: reason}
+	}
+
+	br := bufio.NewReader(r)
+	lineNo := 0
+	var pending []byte // 多行日志中尚未读取到链值的部分
+	pendingStart := 0  // 多行日志的起始行号
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(line) > 0 {
+			lineNo++
+			if err == io.EOF {
+				return broken(lineNo, "incomplete record at end of segment")
+			}
+
+			// 第一行必须为分段头
+			if lineNo == 1 {
+				seq, prev, ok := ParseHeader(line)
+				if !ok {
+					return broken(lineNo, "missing segment header")
+				}
+				if v.started && (seq != v.seq || !bytes.Equal(prev, v.prev[:])) {
+					return broken(lineNo, "segment header does not continue the chain")
+				}
+				v.Anchor(seq, prev)
+				continue
+			}
+
+			// 没有链值的行属于多行日志的一部分
+			body, seq, sum, ok := ParseRecord(line)
+			if !ok {
+				if len(pending) == 0 {
+					pendingStart = lineNo
+				}
+				pending = append(pending, line...)
+				continue
+			}
+			start := lineNo
+			if len(pending) > 0 {
+				body = append(pending, body...)
+				pending = nil
+				start = pendingStart
+			}
+
+			// 校验序号与链值
+			if seq != v.seq+1 {
+				return broken(start, "unexpected seq "+strconv.FormatUint(seq, 10))
+			}
+			v.sum = computeSum(v.mac, v.sum[:0], v.prev[:], seq, body)
+			if !hmac.Equal(v.sum, sum) {
+				return broken(start, "chain value mismatch")
+			}
+			v.seq = seq
+			copy(v.prev[:], sum)
+			v.records++
+		}
+		if err == io.EOF {
+			if len(pending) > 0 {
+				return broken(pendingStart, "trailing content without chain value")
+			}
+			return nil
+		}
+	}
+}
+
+// VerifyFiles 按顺序校验多个日志分段
+//
+//	@param	key			HMAC密钥
+//	@param	fileNames	按写入顺序排列的日志分段路径（可使用SortSegments排序）
+//	@return	异常信息（哈希链断开时为*BrokenLinkError）
+func VerifyFiles(key []byte, fileNames ...string) error {
+	v := NewVerifier(key)
+	for _, fileName := range fileNames {
+		file, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		err = v.Verify(fileName, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 日志分段文件名中的日期及分割后缀标识
+var segmentRegexp = regexp.MustCompile(`\.([0-9]{4}-[0-9]{2}-[0-9]{2})\.([0-9]+)(\.[^.]*)?$`)
+
+// SortSegments 将文件日志适配器生成的日志分段按写入顺序排序
+//
+//	按文件名中的日期及分割后缀标识排序，如app.2021-09-21.2.log排在app.2021-09-21.10.log之前
+//
+//	@param	fileNames	日志分段路径
+func SortSegments(fileNames []string) {
+	type key struct {
+		date  string
+		index uint64
+	}
+	keys := make(map[string]key, len(fileNames))
+	for _, fileName := range fileNames {
+		m := segmentRegexp.FindStringSubmatch(filepath.Base(fileName))
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.ParseUint(m[2], 10, 64)
+		keys[fileName] = key{date: m[1], index: index}
+	}
+	sort.SliceStable(fileNames, func(i, j int) bool {
+		a, b := keys[fileNames[i]], keys[fileNames[j]]
+		if a.date != b.date {
+			return a.date < b.date
+		}
+		if a.index != b.index {
+			return a.index < b.index
+		}
+		return fileNames[i] < fileNames[j]
+	})
+}
@@ -0,0 +1,413 @@
+/**
+ *@Title 日志分段加密
+ *@Desc 使用AES-GCM将日志内容分块加密，每个分块独立封装，不完整的文件仍可解密到最后一个完整分块
+ */
+
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// 加密格式相关常量
+//
+//	文件布局：文件头(32) + 分块 + 分块 + ...
+//	文件头布局：标识(8) + 版本(1) + 保留(7) + 文件ID(16)
+//	分块布局：密钥ID(4) + 随机数(12) + 标志(1) + 密文长度(4) + 密文（含16字节认证标签）
+//	附加认证数据：文件ID(16) + 分块序号(8) + 密钥ID(4) + 标志(1) + 密文长度(4)
+//
+//	分块序号从0开始递增，不写入文件，调换、删除分块或拼接其他文件的分块都将导致认证失败；
+//	关闭日志分段时写入带结束标志的分块，末尾没有结束分块的文件视为被截断
+const (
+	Magic           = "BELOGENC" // 加密文件标识
+	Version         = 1          // 加密格式版本
+	HeaderSize      = 32         // 文件头大小
+	FileIDSize      = 16         // 文件ID大小
+	ChunkHeaderSize = 21         // 分块头大小
+	MaxChunkSize    = 64 << 20   // 单个分块密文最大长度
+)
+
+// 分块标志
+const flagFinal = 1 // 结束分块
+
+// ErrTruncated 文件末尾存在不完整的分块或缺少结束分块
+//
+//	写入过程中进程异常退出会产生不完整的分块，此前的分块均可正常解密；
+//	日志分段关闭后可以重新打开继续追加，因此截断到某个结束分块处无法被发现
+var ErrTruncated = errors.New("belog/crypt: truncated chunk at end of file")
+
+// KeyProvider 密钥提供者接口
+//
+//	通过密钥ID区分不同的密钥，轮换密钥时只需让CurrentKey返回新的密钥ID，
+//	旧的密钥仍需通过Key返回，以便解密使用旧密钥加密的分块
+type KeyProvider interface {
+	// CurrentKey 获取当前用于加密的密钥
+	//
+	//	@return	密钥ID
+	//	@return	密钥（16、24或32字节，分别对应AES-128、AES-192、AES-256）
+	//	@return	异常信息
+	CurrentKey() (uint32, []byte, error)
+
+	// Key 根据密钥ID获取密钥
+	//
+	//	@param	id	密钥ID
+	//	@return	密钥
+	//	@return	异常信息
+	Key(id uint32) ([]byte, error)
+}
+
+// KeyRing 基于内存的密钥提供者
+type KeyRing struct {
+	mutex   sync.RWMutex      // 读写锁
+	current uint32            // 当前密钥ID
+	keys    map[uint32][]byte // 密钥映射
+}
+
+// NewKeyRing 创建基于内存的密钥提供者
+//
+//	@param	id	当前密钥ID
+//	@param	key	当前密钥
+//	@return	密钥提供者
+func NewKeyRing(id uint32, key []byte) *KeyRing {
+	return &KeyRing{
+		current: id,
+		keys:    map[uint32][]byte{id: key},
+	}
+}
+
+// Rotate 轮换密钥
+//
+//	旧密钥会被保留，用于解密使用旧密钥加密的分块
+//
+//	@param	id	新的密钥ID
+//	@param	key	新的密钥
+func (k *KeyRing) Rotate(id uint32, key []byte) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.keys[id] = key
+	k.current = id
+}
+
+// CurrentKey 获取当前用于加密的密钥
+func (k *KeyRing) CurrentKey() (uint32, []byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.current, k.keys[k.current], nil
+}
+
+// Key 根据密钥ID获取密钥
+func (k *KeyRing) Key(id uint32) ([]byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	if key, ok := k.keys[id]; ok {
+		return key, nil
+	}
+	return nil, errors.New("belog/crypt: unknown key id " + strconv.FormatUint(uint64(id), 10))
+}
+
+// 追加加密文件头
+func appendHeader(dst []byte, id [FileIDSize]byte) []byte {
+	var header [HeaderSize]byte
+	copy(header[:], Magic)
+	header[8] = Version
+	copy(header[16:], id[:])
+	return append(dst, header[:]...)
+}
+
+// 解析加密文件头
+func parseHeader(header []byte) ([FileIDSize]byte, error) {
+	var id [FileIDSize]byte
+	if !IsEncrypted(header) {
+		return id, errors.New("belog/crypt: not an encrypted log segment")
+	}
+	if header[8] != Version {
+		return id, errors.New("belog/crypt: unsupported version " + strconv.Itoa(int(header[8])))
+	}
+	copy(id[:], header[16:HeaderSize])
+	return id, nil
+}
+
+// 生成附加认证数据
+func appendAD(dst []byte, id [FileIDSize]byte, seq uint64, header []byte) []byte {
+	dst = append(dst, id[:]...)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seq)
+	dst = append(dst, b[:]...)
+	dst = append(dst, header[0:4]...)
+	return append(dst, header[16:ChunkHeaderSize]...)
+}
+
+// ScanSegment 扫描加密日志分段的分块结构（不解密）
+//
+//	用于继续写入已有的日志分段，分段末尾存在不完整的分块时返回ErrTruncated
+//
+//	@param	r	日志分段
+//	@return	文件ID
+//	@return	完整分块的数量
+//	@return	异常信息
+func ScanSegment(r io.ReadSeeker) ([FileIDSize]byte, uint64, error) {
+	var id [FileIDSize]byte
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return id, 0, err
+	}
+	var header [HeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return id, 0, ErrTruncated
+	}
+	id, err := parseHeader(header[:])
+	if err != nil {
+		return id, 0, err
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return id, 0, err
+	}
+
+	var chunks uint64
+	var chunk [ChunkHeaderSize]byte
+	for off := int64(HeaderSize); off < end; chunks++ {
+		if _, err = r.Seek(off, io.SeekStart); err != nil {
+			return id, chunks, err
+		}
+		if _, err = io.ReadFull(r, chunk[:]); err != nil {
+			return id, chunks, ErrTruncated
+		}
+		size := binary.BigEndian.Uint32(chunk[17:21])
+		if size > MaxChunkSize {
+			return id, chunks, errors.New("belog/crypt: invalid chunk size")
+		}
+		off += ChunkHeaderSize + int64(size)
+		if off > end {
+			return id, chunks, ErrTruncated
+		}
+	}
+	return id, chunks, nil
+}
+
+// IsEncrypted 判断内容是否以加密文件头开始
+//
+//	@param	b	文件开头的内容
+//	@return	是否为加密文件
+func IsEncrypted(b []byte) bool {
+	return len(b) >= len(Magic) && string(b[:len(Magic)]) == Magic
+}
+
+// 密钥对应的AEAD缓存
+type aeadCache struct {
+	kp    KeyProvider            // 密钥提供者
+	cache map[uint32]cipher.AEAD // 密钥ID对应的AEAD
+}
+
+// 获取密钥ID对应的AEAD
+func (c *aeadCache) get(id uint32, key []byte) (cipher.AEAD, error) {
+	if aead, ok := c.cache[id]; ok {
+		return aead, nil
+	}
+	if key == nil {
+		var err error
+		if key, err = c.kp.Key(id); err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache == nil {
+		c.cache = make(map[uint32]cipher.AEAD)
+	}
+	c.cache[id] = aead
+	return aead, nil
+}
+
+// Sealer 分块加密器
+//
+//	同一时间只能写入一个日志分段，通过Start开始新的分段或通过Resume继续写入已有的分段
+//
+//	注意：非并发安全
+type Sealer struct {
+	aeads aeadCache        // AEAD缓存
+	id    [FileIDSize]byte // 当前日志分段的文件ID
+	seq   uint64           // 下一个分块的序号
+	ad    []byte           // 附加认证数据缓冲区
+}
+
+// NewSealer 创建分块加密器
+//
+//	@param	kp	密钥提供者
+//	@return	分块加密器
+func NewSealer(kp KeyProvider) *Sealer {
+	return &Sealer{aeads: aeadCache{kp: kp}}
+}
+
+// Start 开始新的日志分段
+//
+//	生成随机的文件ID并重置分块序号
+//
+//	@param	dst	填充目标
+//	@return	追加了加密文件头的内容
+//	@return	异常信息
+func (s *Sealer) Start(dst []byte) ([]byte, error) {
+	if _, err := io.ReadFull(rand.Reader, s.id[:]); err != nil {
+		return dst, err
+	}
+	s.seq = 0
+	return appendHeader(dst, s.id), nil
+}
+
+// Resume 继续写入已有的日志分段
+//
+//	@param	id		日志分段的文件ID
+//	@param	chunks	日志分段中已有的分块数量
+func (s *Sealer) Resume(id [FileIDSize]byte, chunks uint64) {
+	s.id = id
+	s.seq = chunks
+}
+
+// Seal 加密一个分块
+//
+//	每个分块都使用密钥提供者当前的密钥加密
+//
+//	@param	dst			填充目标
+//	@param	plaintext	明文内容
+//	@param	final		是否为结束分块（关闭日志分段时写入）
+//	@return	追加了加密分块的内容
+//	@return	异常信息
+func (s *Sealer) Seal(dst []byte, plaintext []byte, final bool) ([]byte, error) {
+	id, key, err := s.aeads.kp.CurrentKey()
+	if err != nil {
+		return dst, err
+	}
+	aead, err := s.aeads.get(id, key)
+	if err != nil {
+		return dst, err
+	}
+	size := len(plaintext) + aead.Overhead()
+	if size > MaxChunkSize {
+		return dst, errors.New("belog/crypt: chunk too large")
+	}
+
+	// 分块头
+	var header [ChunkHeaderSize]byte
+	binary.BigEndian.PutUint32(header[0:4], id)
+	if _, err = io.ReadFull(rand.Reader, header[4:16]); err != nil {
+		return dst, err
+	}
+	if final {
+		header[16] = flagFinal
+	}
+	binary.BigEndian.PutUint32(header[17:21], uint32(size))
+	dst = append(dst, header[:]...)
+
+	// 以文件ID、分块序号及分块头作为附加认证数据
+	s.ad = appendAD(s.ad[:0], s.id, s.seq, header[:])
+	s.seq++
+	return aead.Seal(dst, header[4:16], plaintext, s.ad), nil
+}
+
+// Reader 加密日志分段读取器
+type Reader struct {
+	r      io.Reader        // 原始内容读取器
+	aeads  aeadCache        // AEAD缓存
+	header bool             // 是否已读取文件头
+	id     [FileIDSize]byte // 文件ID
+	seq    uint64           // 下一个分块的序号
+	final  bool             // 上一个分块是否为结束分块
+	buf    []byte           // 分块读取缓冲区
+	ad     []byte           // 附加认证数据缓冲区
+	plain  []byte           // 已解密且未读取的明文
+	err    error            // 读取异常
+}
+
+// NewReader 创建加密日志分段读取器
+//
+//	文件末尾存在不完整的分块或缺少结束分块时，读取完所有完整分块后将返回ErrTruncated
+//
+//	@param	r	原始内容读取器
+//	@param	kp	密钥提供者
+//	@return	明文读取器
+func NewReader(r io.Reader, kp KeyProvider) *Reader {
+	return &Reader{r: r, aeads: aeadCache{kp: kp}}
+}
+
+// Read 读取解密后的明文
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// 读取并解密下一个分块
+func (r *Reader) next() error {
+	// 读取文件头
+	if !r.header {
+		var header [HeaderSize]byte
+		if _, err := io.ReadFull(r.r, header[:]); err != nil {
+			if err == io.EOF {
+				return io.EOF
+			}
+			return ErrTruncated
+		}
+		id, err := parseHeader(header[:])
+		if err != nil {
+			return err
+		}
+		r.id = id
+		r.header = true
+	}
+
+	// 读取分块头
+	var header [ChunkHeaderSize]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		// 最后一个分块必须是结束分块
+		if err == io.EOF && r.final {
+			return io.EOF
+		}
+		return ErrTruncated
+	}
+	id := binary.BigEndian.Uint32(header[0:4])
+	size := binary.BigEndian.Uint32(header[17:21])
+	if size > MaxChunkSize {
+		return errors.New("belog/crypt: invalid chunk size")
+	}
+
+	// 读取密文
+	if cap(r.buf) < int(size) {
+		r.buf = make([]byte, size)
+	}
+	ciphertext := r.buf[:size]
+	if _, err := io.ReadFull(r.r, ciphertext); err != nil {
+		return ErrTruncated
+	}
+
+	// 解密
+	aead, err := r.aeads.get(id, nil)
+	if err != nil {
+		return err
+	}
+	r.ad = appendAD(r.ad[:0], r.id, r.seq, header[:])
+	r.plain, err = aead.Open(ciphertext[:0], header[4:16], ciphertext, r.ad)
+	if err != nil {
+		return err
+	}
+	r.seq++
+	r.final = header[16]&flagFinal != 0
+	return nil
+}
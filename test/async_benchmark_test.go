@@ -0,0 +1,90 @@
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/writer"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 创建输出到3个无输出写入器的记录器
+func newMultipleAdapterLogger(b *testing.B, async logger.AsyncOption) logger.Logger {
+	var adapters []logger.Adapter
+	for i := 0; i < 3; i++ {
+		adapter, err := writer.New(writer.Options{Name: fmt.Sprintf("discard-%d", i), Writer: ioutil.Discard})
+		if err != nil {
+			b.Fatal(err)
+		}
+		adapters = append(adapters, adapter)
+	}
+	l, err := belog.New(logger.Option{Async: async}, adapters...)
+	if err != nil {
+		b.Fatalf("belog logger create failed, %s", err)
+	}
+	return l
+}
+
+// 执行多适配器基准测试
+func benchmarkMultipleAdapter(b *testing.B, async logger.AsyncOption) {
+	l := newMultipleAdapterLogger(b, async)
+	defer l.(logger.Closer).Close()
+
+	// 重置测试参数
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// 执行测试
+	for i := 0; i < b.N; i++ {
+		l.Info("this is a info log", field.Int("key1", i), field.String("key2", "value"))
+	}
+	l.Flush()
+}
+
+// 执行多适配器并发基准测试
+func benchmarkMultipleAdapterParallel(b *testing.B, async logger.AsyncOption) {
+	l := newMultipleAdapterLogger(b, async)
+	defer l.(logger.Closer).Close()
+
+	// 重置测试参数
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// 执行测试
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			l.Info("this is a info log", field.Int("key1", i), field.String("key2", "value"))
+			i++
+		}
+	})
+	l.Flush()
+}
+
+// BenchmarkMultipleAdapterSync 测试同步输出到3个适配器（每条日志为每个适配器创建一个协程）
+func BenchmarkMultipleAdapterSync(b *testing.B) {
+	benchmarkMultipleAdapter(b, logger.AsyncOption{})
+}
+
+// BenchmarkMultipleAdapterAsyncBlock 测试异步输出到3个适配器（队列已满时阻塞）
+func BenchmarkMultipleAdapterAsyncBlock(b *testing.B) {
+	benchmarkMultipleAdapter(b, logger.AsyncOption{Enabled: true})
+}
+
+// BenchmarkMultipleAdapterAsyncDropNewest 测试异步输出到3个适配器（队列已满时丢弃当前日志）
+func BenchmarkMultipleAdapterAsyncDropNewest(b *testing.B) {
+	benchmarkMultipleAdapter(b, logger.AsyncOption{Enabled: true, Overflow: logger.OverflowDropNewest})
+}
+
+// BenchmarkMultipleAdapterSyncParallel 测试并发同步输出到3个适配器
+func BenchmarkMultipleAdapterSyncParallel(b *testing.B) {
+	benchmarkMultipleAdapterParallel(b, logger.AsyncOption{})
+}
+
+// BenchmarkMultipleAdapterAsyncParallel 测试并发异步输出到3个适配器（队列已满时阻塞）
+func BenchmarkMultipleAdapterAsyncParallel(b *testing.B) {
+	benchmarkMultipleAdapterParallel(b, logger.AsyncOption{Enabled: true})
+}
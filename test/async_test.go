@@ -0,0 +1,143 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 记录日志内容的适配器
+type collectAdapter struct {
+	name  string
+	gate  chan struct{} // 非空时每条日志需等待放行
+	mutex sync.Mutex
+	lines []string
+}
+
+func (c *collectAdapter) Name() string { return c.name }
+
+func (c *collectAdapter) Print(_ time.Time, _ logger.Level, content []byte) {
+	if c.gate != nil {
+		<-c.gate
+	}
+	c.mutex.Lock()
+	c.lines = append(c.lines, string(content))
+	c.mutex.Unlock()
+}
+
+func (c *collectAdapter) PrintStack(t time.Time, l logger.Level, content []byte, _ string, _ int, _ string) {
+	c.Print(t, l, content)
+}
+
+func (c *collectAdapter) Flush() {}
+
+func (c *collectAdapter) get() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+// 创建行格式编码器
+func newNormalEncoder() logger.Encoder {
+	return encoder.NewNormalEncoder(encoder.DefaultNormalOption)
+}
+
+// 判断行格式日志的消息
+func containsMsg(line string, msg string) bool {
+	return strings.HasSuffix(strings.TrimRight(line, "\r\n"), " "+msg)
+}
+
+// TestAsyncOrderAndFlush 测试异步输出的顺序、Flush及Close
+func TestAsyncOrderAndFlush(t *testing.T) {
+	a1, a2 := &collectAdapter{name: "a1"}, &collectAdapter{name: "a2"}
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Encoder: newNormalEncoder(),
+		Async:   logger.AsyncOption{Enabled: true, QueueSize: 16},
+	}, a1, a2, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	l.Flush()
+	for _, a := range []*collectAdapter{a1, a2} {
+		lines := a.get()
+		if len(lines) != 100 {
+			t.Fatalf("%s expected 100 lines, got %d", a.name, len(lines))
+		}
+		for i, line := range lines {
+			if !containsMsg(line, strconv.Itoa(i)) {
+				t.Fatalf("%s line %d out of order: %q", a.name, i, line)
+			}
+		}
+	}
+	observer.AssertCount(t, logger.Info, 100)
+
+	// 关闭后同步输出
+	l.(logger.Closer).Close()
+	l.Info("after close")
+	if lines := a1.get(); len(lines) != 101 {
+		t.Fatalf("expected synchronous output after close, got %d lines", len(lines))
+	}
+}
+
+// TestAsyncOverflow 测试队列已满时的丢弃策略
+func TestAsyncOverflow(t *testing.T) {
+	for _, policy := range []logger.OverflowPolicy{logger.OverflowDropNewest, logger.OverflowDropOldest} {
+		gate := make(chan struct{})
+		a := &collectAdapter{name: "slow", gate: gate}
+		l, err := belog.New(logger.Option{
+			Encoder: newNormalEncoder(),
+			Async:   logger.AsyncOption{Enabled: true, QueueSize: 4, Overflow: policy},
+		}, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 第一条日志阻塞在适配器中，随后4条填满队列，其余被丢弃
+		l.Info("0")
+		time.Sleep(50 * time.Millisecond)
+		for i := 1; i < 10; i++ {
+			l.Info(strconv.Itoa(i))
+		}
+		close(gate)
+		l.(logger.Closer).Close()
+
+		lines := a.get()
+		if len(lines) != 5 || l.(logger.AsyncStats).AsyncDropped() != 5 {
+			t.Fatalf("policy %d: unexpected lines %q, dropped %d", policy, lines, l.(logger.AsyncStats).AsyncDropped())
+		}
+		want := "4"
+		if policy == logger.OverflowDropOldest {
+			want = "9"
+		}
+		if !containsMsg(lines[4], want) {
+			t.Fatalf("policy %d: expected last line %s, got %q", policy, want, lines[4])
+		}
+	}
+}
+
+// TestAsyncStats 测试通过AsyncStats接口获取丢弃条数
+func TestAsyncStats(t *testing.T) {
+	l, err := belog.New(logger.Option{Encoder: newNormalEncoder()}, &collectAdapter{name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := l.(logger.AsyncStats)
+	if !ok {
+		t.Fatal("expected the logger to implement logger.AsyncStats")
+	}
+	if s.AsyncDropped() != 0 {
+		t.Fatalf("expected no dropped logs without async output, got %d", s.AsyncDropped())
+	}
+}
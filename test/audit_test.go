@@ -0,0 +1,222 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/audit"
+)
+
+// 测试用HMAC密钥
+var testAuditKey = []byte("belog-audit-test-key")
+
+// 生成带哈希链的日志分段，每个分段包含指定条数的日志
+func chainSegments(key []byte, counts ...int) [][]byte {
+	c := audit.NewChain(key)
+	segments := make([][]byte, 0, len(counts))
+	seq := 0
+	for _, n := range counts {
+		dst := c.AppendHeader(nil)
+		for i := 0; i < n; i++ {
+			seq++
+			dst = c.Append(dst, []byte("record "+strconv.Itoa(seq)+"\r\n"))
+		}
+		segments = append(segments, dst)
+	}
+	return segments
+}
+
+// 按顺序校验内存中的日志分段
+func verifySegments(key []byte, segments ...[]byte) error {
+	v := audit.NewVerifier(key)
+	for i, s := range segments {
+		if err := v.Verify("segment-"+strconv.Itoa(i), bytes.NewReader(s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 判断是否为哈希链断开异常
+func isBrokenLink(err error) bool {
+	var e *audit.BrokenLinkError
+	return errors.As(err, &e)
+}
+
+// TestAuditChain 测试哈希链生成及校验
+func TestAuditChain(t *testing.T) {
+	segments := chainSegments(testAuditKey, 3, 2)
+	if err := verifySegments(testAuditKey, segments...); err != nil {
+		t.Fatal(err)
+	}
+
+	// 日志内容保持不变
+	lines := strings.Split(string(segments[0]), "\r\n")
+	body, seq, _, ok := audit.ParseRecord([]byte(lines[1]))
+	if !ok || string(body) != "record 1" || seq != 1 {
+		t.Fatalf("unexpected record %q, %d, %v", body, seq, ok)
+	}
+
+	// 错误的密钥
+	if err := verifySegments([]byte("other-key"), segments...); !isBrokenLink(err) {
+		t.Fatalf("expected broken link with the wrong key, got %v", err)
+	}
+
+	// 从上一个分段的状态继续生成
+	v := audit.NewVerifier(testAuditKey)
+	if err := v.Verify("first", bytes.NewReader(segments[0])); err != nil {
+		t.Fatal(err)
+	}
+	c := audit.NewChain(testAuditKey)
+	c.Resume(v.State())
+	next := c.Append(c.AppendHeader(nil), []byte("record 4\r\n"))
+	if err := v.Verify("resumed", bytes.NewReader(next)); err != nil || v.Records() != 4 {
+		t.Fatalf("expected 4 records after resume, got %d, %v", v.Records(), err)
+	}
+}
+
+// TestAuditTamper 测试修改、删除、调换日志及分段不衔接
+func TestAuditTamper(t *testing.T) {
+	segments := chainSegments(testAuditKey, 3, 3)
+	lines := strings.SplitAfter(string(segments[0]), "\r\n")
+	lines = lines[:len(lines)-1] // 去除末尾空字符串
+	join := func(lines ...string) []byte {
+		return []byte(strings.Join(lines, ""))
+	}
+
+	tests := []struct {
+		name     string
+		segments [][]byte
+	}{
+		{"Modified", [][]byte{join(lines[0], strings.Replace(lines[1], "record 1", "record X", 1), lines[2], lines[3]), segments[1]}},
+		{"Deleted", [][]byte{join(lines[0], lines[1], lines[3]), segments[1]}},
+		{"Reordered", [][]byte{join(lines[0], lines[2], lines[1], lines[3]), segments[1]}},
+		{"MissingHeader", [][]byte{join(lines[1:]...), segments[1]}},
+		{"HeaderMismatch", [][]byte{segments[0], chainSegments(testAuditKey, 5)[0]}},
+		{"SegmentOrder", [][]byte{segments[1], segments[0]}},
+		{"SegmentDropped", [][]byte{join(lines[0], lines[1]), segments[1]}},
+		{"Incomplete", [][]byte{segments[0][:len(segments[0])-2], segments[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifySegments(testAuditKey, tt.segments...); !isBrokenLink(err) {
+				t.Fatalf("expected broken link, got %v", err)
+			}
+		})
+	}
+
+	// 指定校验起点后可以发现被整体删除的最早分段
+	v := audit.NewVerifier(testAuditKey)
+	v.Anchor(0, make([]byte, audit.SumSize))
+	if err := v.Verify("second", bytes.NewReader(segments[1])); !isBrokenLink(err) {
+		t.Fatalf("expected broken link with anchor, got %v", err)
+	}
+}
+
+// TestAuditSortSegments 测试日志分段按写入顺序排序
+func TestAuditSortSegments(t *testing.T) {
+	fileNames := []string{
+		"logs/app.2021-09-22.1.log",
+		"logs/app.2021-09-21.10.log",
+		"logs/app.2021-09-21.2.log",
+		"logs/app.2021-09-21.1.log",
+	}
+	audit.SortSegments(fileNames)
+	want := []string{
+		"logs/app.2021-09-21.1.log",
+		"logs/app.2021-09-21.2.log",
+		"logs/app.2021-09-21.10.log",
+		"logs/app.2021-09-22.1.log",
+	}
+	if strings.Join(fileNames, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected order %q", fileNames)
+	}
+}
+
+// TestAuditVerifyFiles 测试跨日志分段校验
+func TestAuditVerifyFiles(t *testing.T) {
+	dir := t.TempDir()
+	segments := chainSegments(testAuditKey, 2, 2, 2)
+	fileNames := make([]string, 0, len(segments))
+	for i, s := range segments {
+		fileName := filepath.Join(dir, "app.2021-09-21."+strconv.Itoa(i+9)+".log")
+		if err := os.WriteFile(fileName, s, 0666); err != nil {
+			t.Fatal(err)
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	if err := audit.VerifyFiles(testAuditKey, fileNames...); err != nil {
+		t.Fatal(err)
+	}
+	// 未按写入顺序排列时校验失败
+	if err := audit.VerifyFiles(testAuditKey, fileNames[1], fileNames[0], fileNames[2]); !isBrokenLink(err) {
+		t.Fatalf("expected broken link, got %v", err)
+	}
+}
+
+// 创建开启哈希链的文件日志适配器，同一文件夹下的路由日志文件以相同名称开头
+func newAuditAdapter(t *testing.T, dir string) *file.Adapter {
+	t.Helper()
+	a, err := file.New(file.Options{
+		LogPath:  filepath.Join(dir, "app.log"),
+		SaveDay:  7,
+		Audit:    true,
+		AuditKey: testAuditKey,
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "app.errors.log"), Match: file.Levels(logger.Error)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.(*file.Adapter)
+}
+
+// 按写入顺序获取指定名称的日志分段
+func auditSegments(t *testing.T, dir string, name string) []string {
+	t.Helper()
+	fileNames, err := filepath.Glob(filepath.Join(dir, name+".[0-9]*.*.log"))
+	if err != nil || len(fileNames) == 0 {
+		t.Fatalf("expected segments of %s, got %v, %v", name, fileNames, err)
+	}
+	audit.SortSegments(fileNames)
+	return fileNames
+}
+
+// TestFileAuditResume 测试重新打开适配器后从各自的日志分段继续哈希链
+func TestFileAuditResume(t *testing.T) {
+	dir := t.TempDir()
+	write := func(msgs ...string) {
+		a := newAuditAdapter(t, dir)
+		l, err := belog.New(logger.Option{}, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range msgs {
+			l.Error(msg)
+		}
+		l.Info("done")
+		l.Flush()
+		if err = a.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("first error")
+	// 路由日志文件的分段名称也匹配主日志文件的通配符，继续写入时不能误用其链值
+	write("second error", "third error")
+
+	if err := audit.VerifyFiles(testAuditKey, auditSegments(t, dir, "app")...); err != nil {
+		t.Fatal(err)
+	}
+	if err := audit.VerifyFiles(testAuditKey, auditSegments(t, dir, "app.errors")...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 记录断言失败信息的测试实例
+type recordTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+// TestObserver 测试日志观察适配器及断言
+func TestObserver(t *testing.T) {
+	l, observer := belogtest.NewLogger(t)
+
+	l.Info("user login", field.String("user", "bob"), field.Int("attempt", 2), field.Duration("took", time.Second))
+	l.Error("save failed", field.Error("err", errors.New("disk full")), field.Bool("retry", true))
+
+	observer.AssertLogged(t, logger.Info, "user login", field.String("user", "bob"))
+	observer.AssertLogged(t, logger.Error, "save failed", field.Error("err", errors.New("disk full")), field.Bool("retry", true))
+	observer.AssertNotLogged(t, logger.Error, "user login")
+	observer.AssertCount(t, logger.Info, 1)
+
+	// 类型化字段值
+	entry := observer.FilterMessage("user login")[0]
+	if v, _ := entry.Value("attempt"); v != int64(2) {
+		t.Fatalf("unexpected attempt value %#v", v)
+	}
+	if v, _ := entry.Value("took"); v != time.Second {
+		t.Fatalf("unexpected took value %#v", v)
+	}
+	if m := observer.FilterLevel(logger.Error)[0].ContextMap(); m["err"] != "disk full" || m["retry"] != true {
+		t.Fatalf("unexpected context map %v", m)
+	}
+
+	// 调用位置
+	if !strings.HasSuffix(entry.FileName, "belogtest_test.go") || !strings.HasSuffix(entry.FuncName, "TestObserver") || entry.LineNo == 0 {
+		t.Fatalf("unexpected caller %s:%d %s", entry.FileName, entry.LineNo, entry.FuncName)
+	}
+
+	// 断言失败
+	r := &recordTB{TB: t}
+	if observer.AssertLogged(r, logger.Info, "user login", field.String("user", "alice")) || len(r.errors) != 1 {
+		t.Fatal("assert should fail on mismatched field value")
+	}
+
+	if n := len(observer.TakeAll()); n != 2 || observer.Len() != 0 {
+		t.Fatalf("unexpected take all result %d", n)
+	}
+}
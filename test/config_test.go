@@ -0,0 +1,180 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/ring"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/config"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 注册测试用观察者适配器
+var testObserver = belogtest.NewObserver()
+
+func init() {
+	_ = config.RegisterAdapter("test-observer", func(c config.Component) (logger.Adapter, error) {
+		if err := c.Decode(&struct{}{}); err != nil {
+			return nil, err
+		}
+		return testObserver, nil
+	})
+}
+
+// 设置环境变量，返回恢复函数
+func setEnv(kv ...string) func() {
+	for i := 0; i < len(kv); i += 2 {
+		_ = os.Setenv(kv[i], kv[i+1])
+	}
+	return func() {
+		for i := 0; i < len(kv); i += 2 {
+			_ = os.Unsetenv(kv[i])
+		}
+	}
+}
+
+// TestConfigJSON 测试根据JSON配置创建记录器
+func TestConfigJSON(t *testing.T) {
+	testObserver.Reset()
+	l, err := config.New([]byte(`{
+		"level": "info",
+		"encoder": {"type": "normal", "options": {"timeFormat": "2006-01-02"}},
+		"dedup": {"enabled": true, "window": 60000},
+		"adapters": [
+			{"type": "test-observer"},
+			{"type": "ring", "options": {"name": "recent", "size": 10}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("hidden")
+	l.Info("shown")
+	l.Info("shown")
+	l.Flush()
+
+	testObserver.AssertNotLogged(t, logger.Debug, "hidden")
+	testObserver.AssertLogged(t, logger.Info, "shown")
+	testObserver.AssertLogged(t, logger.Info, "previous message repeated 1 times")
+}
+
+// TestConfigRingOverride 测试环境变量覆盖适配器参数
+func TestConfigRingOverride(t *testing.T) {
+	defer setEnv("BELOG_ADAPTERS_RING_SIZE", "2")()
+
+	c, err := config.Parse(map[string]interface{}{
+		"adapters": []interface{}{map[string]interface{}{"type": "ring"}},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opt ring.Options
+	if err = c.Adapters[0].Decode(&opt); err != nil {
+		t.Fatal(err)
+	}
+	if opt.Size != 2 {
+		t.Fatalf("expected ring size 2, got %d", opt.Size)
+	}
+}
+
+// TestConfigErrors 测试配置异常指向出错的配置项
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		doc string
+		key string
+	}{
+		{`{"levle": "info"}`, "levle"},
+		{`{"level": "verbose"}`, "level"},
+		{`{"async": {"enabled": true, "overflow": "spill"}}`, "async.overflow"},
+		{`{"async": {"queueSize": "big"}}`, "async.queueSize"},
+		{`{"encoder": {"type": "xml"}}`, "encoder.type"},
+		{`{"encoder": {"type": "json", "options": {"timeKey": 1}}}`, "encoder.options.timeKey"},
+		{`{"adapters": [{"type": "discard"}, {"type": "nope"}]}`, "adapters[1].type"},
+		{`{"adapters": [{"type": "ring", "options": {"sise": 1}}]}`, "adapters[0].options.sise"},
+		{`{"adapters": [{"type": "http", "options": {}}]}`, "adapters[0]"},
+		{`{"adapters": [{"type": "ring"}, {"type": "ring"}]}`, "adapters[1]"},
+		{`{"adapters": [{"type": "ring", "option": {}}]}`, "adapters[0].option"},
+	}
+	for _, tt := range tests {
+		_, err := config.New([]byte(tt.doc))
+		var ce *config.Error
+		if !errors.As(err, &ce) {
+			t.Errorf("%s: expected config error, got %v", tt.doc, err)
+			continue
+		}
+		if ce.Key != tt.key {
+			t.Errorf("%s: expected key %q, got %q (%v)", tt.doc, tt.key, ce.Key, err)
+		}
+	}
+
+	// 未知的环境变量
+	defer setEnv("BELOG_COLOR", "1")()
+	_, err := config.New([]byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "BELOG_COLOR") {
+		t.Fatalf("expected unknown environment variable error, got %v", err)
+	}
+}
+
+// 记录关闭调用的适配器
+type closeAdapter struct {
+	name   string
+	closed bool
+}
+
+func (a *closeAdapter) Name() string { return a.name }
+
+func (a *closeAdapter) Print(time.Time, logger.Level, []byte) {}
+
+func (a *closeAdapter) PrintStack(time.Time, logger.Level, []byte, string, int, string) {}
+
+func (a *closeAdapter) Flush() {}
+
+func (a *closeAdapter) Close() error {
+	a.closed = true
+	return nil
+}
+
+// TestConfigCloseOnError 测试配置有误时关闭已创建的适配器
+func TestConfigCloseOnError(t *testing.T) {
+	var created []*closeAdapter
+	err := config.RegisterAdapter("test-closer", func(c config.Component) (logger.Adapter, error) {
+		a := &closeAdapter{name: "closer-" + strconv.Itoa(len(created))}
+		created = append(created, a)
+		return a, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, doc := range []string{
+		`{"adapters": [{"type": "test-closer"}, {"type": "nope"}]}`,
+		`{"adapters": [{"type": "test-closer"}, {"type": "ring", "options": {"sise": 1}}]}`,
+		`{"level": "info", "adapters": [{"type": "test-closer"}, {"type": "test-closer"}, {"type": "http", "options": {}}]}`,
+	} {
+		created = nil
+		if _, err = config.New([]byte(doc)); err == nil {
+			t.Fatalf("%s: expected error", doc)
+		}
+		if len(created) == 0 {
+			t.Fatalf("%s: expected adapters to be created", doc)
+		}
+		for _, a := range created {
+			if !a.closed {
+				t.Fatalf("%s: adapter %s was not closed", doc, a.name)
+			}
+		}
+	}
+}
+
+// TestConfigRegister 测试重复注册
+func TestConfigRegister(t *testing.T) {
+	err := config.RegisterAdapter("File", func(config.Component) (logger.Adapter, error) { return nil, nil })
+	if err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/console"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 并发安全的缓冲区
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+// Write 写入内容
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+// String 获取已写入的内容
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+// 检测并发写入的输出目标
+type exclusiveWriter struct {
+	active  int32 // 正在写入的数量
+	overlap int32 // 是否发生过并发写入
+	lines   int64 // 已写入的行数
+}
+
+// Write 写入内容
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if atomic.AddInt32(&w.active, 1) > 1 {
+		atomic.StoreInt32(&w.overlap, 1)
+	}
+	runtime.Gosched()
+	atomic.AddInt64(&w.lines, int64(bytes.Count(p, []byte("\n"))))
+	atomic.AddInt32(&w.active, -1)
+	return len(p), nil
+}
+
+// 创建控制台日志记录器
+func newStreamLogger(t *testing.T, opt console.Option) (logger.Logger, *console.Adapter) {
+	t.Helper()
+	opt.Color = console.ColorNever
+	a := console.New(opt)
+	l, err := belog.New(logger.Option{}, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, a.(*console.Adapter)
+}
+
+// 统计行数
+func countLines(s string) int {
+	return strings.Count(s, "\n")
+}
+
+// TestConsoleStreamRoute 测试按日志级别路由到标准输出及标准错误
+func TestConsoleStreamRoute(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  console.StreamRoute
+		level  logger.Level
+		stdout int
+		stderr int
+	}{
+		{"SplitDefault", console.RouteSplit, 0, 2, 1},
+		{"SplitWarn", console.RouteSplit, logger.Warn, 1, 2},
+		{"Stdout", console.RouteStdout, 0, 3, 0},
+		{"Stderr", console.RouteStderr, 0, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			l, _ := newStreamLogger(t, console.Option{
+				DisabledBuffer: true,
+				Route:          tt.route,
+				StderrLevel:    tt.level,
+				Stdout:         &stdout,
+				Stderr:         &stderr,
+			})
+			l.Info("info")
+			l.Warn("warn")
+			l.Error("error")
+			if countLines(stdout.String()) != tt.stdout || countLines(stderr.String()) != tt.stderr {
+				t.Fatalf("expected %d/%d lines, got %q / %q", tt.stdout, tt.stderr, stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+// TestConsoleStreamFlush 测试刷新时同时刷新两个输出流的缓冲区
+func TestConsoleStreamFlush(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	l, _ := newStreamLogger(t, console.Option{Stdout: &stdout, Stderr: &stderr})
+	l.Info("info")
+	l.Error("error")
+	if stdout.Len() > 0 || stderr.Len() > 0 {
+		t.Fatalf("expected buffered output before flush, got %q / %q", stdout.String(), stderr.String())
+	}
+	l.Flush()
+	if countLines(stdout.String()) != 1 || countLines(stderr.String()) != 1 {
+		t.Fatalf("expected both streams flushed, got %q / %q", stdout.String(), stderr.String())
+	}
+}
+
+// TestConsoleStreamShared 测试标准输出与标准错误为同一目标时不会并发写入
+func TestConsoleStreamShared(t *testing.T) {
+	for _, disabledBuffer := range []bool{true, false} {
+		w := &exclusiveWriter{}
+		l, _ := newStreamLogger(t, console.Option{DisabledBuffer: disabledBuffer, Stdout: w, Stderr: w})
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					if (i+j)%2 == 0 {
+						l.Info("info")
+					} else {
+						l.Error("error")
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		l.Flush()
+		if atomic.LoadInt32(&w.overlap) != 0 {
+			t.Fatalf("expected no concurrent writes to the shared writer (buffer disabled: %v)", disabledBuffer)
+		}
+		if n := atomic.LoadInt64(&w.lines); n != 1600 {
+			t.Fatalf("expected 1600 lines, got %d (buffer disabled: %v)", n, disabledBuffer)
+		}
+	}
+}
+
+// TestConsoleAutoFlush 测试定时刷新缓冲区及关闭后停止定时刷新协程
+func TestConsoleAutoFlush(t *testing.T) {
+	before := runtime.NumGoroutine()
+	var stdout syncBuffer
+	l, a := newStreamLogger(t, console.Option{Stdout: &stdout, FlushInterval: 10})
+	l.Info("info")
+
+	deadline := time.Now().Add(time.Second)
+	for countLines(stdout.String()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the buffer to be flushed automatically")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the auto-flush goroutine to stop, %d goroutines before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,204 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/console"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 测试用配色主题（每一部分的样式均不相同）
+var testTheme = console.Theme{
+	Time:    console.StyleGray,
+	Info:    console.StyleGreen,
+	Error:   console.StyleRed,
+	Stack:   console.StyleFaint,
+	Message: console.StyleBold,
+	Key:     console.StyleCyan,
+	Value:   console.StyleMagenta,
+}
+
+// 控制台样式转义序列
+var ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// 创建输出到缓冲区的控制台日志记录器
+func newConsoleLogger(t *testing.T, stack bool, opt console.Option) (logger.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	opt.Stdout = &buf
+	opt.Stderr = &buf
+	opt.DisabledBuffer = true
+	l, err := belog.New(logger.Option{EnabledStackPrint: stack}, console.New(opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, &buf
+}
+
+// 包裹样式
+func styled(style console.Style, s string) string {
+	return string(style) + s + "\x1b[0m"
+}
+
+// TestConsoleColorNormal 测试按元数据为普通格式设置样式
+func TestConsoleColorNormal(t *testing.T) {
+	l, buf := newConsoleLogger(t, false, console.Option{Color: console.ColorAlways, Theme: &testTheme, Encoder: newNormalEncoder()})
+	// 描述中包含级别标识及字段形式的内容
+	msg := "contains [E] and, key:value"
+	l.Info(msg, field.String("user", "bob"), field.Int("code", 500))
+
+	out := buf.String()
+	want := " " + styled(testTheme.Info, "[I]") + "  " + styled(testTheme.Message, msg) +
+		", " + styled(testTheme.Key, "user") + ":" + styled(testTheme.Value, "bob") +
+		", " + styled(testTheme.Key, "code") + ":" + styled(testTheme.Value, "500") + "\r\n"
+	if !strings.HasPrefix(out, string(testTheme.Time)) || !strings.HasSuffix(out, want) {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if n := strings.Count(out, string(testTheme.Key)); n != 2 {
+		t.Fatalf("expected 2 styled keys, got %d in %q", n, out)
+	}
+	if strings.Contains(out, string(testTheme.Error)) {
+		t.Fatalf("expected no error style, got %q", out)
+	}
+}
+
+// TestConsoleColorStack 测试调用栈样式
+func TestConsoleColorStack(t *testing.T) {
+	l, buf := newConsoleLogger(t, true, console.Option{Color: console.ColorAlways, Theme: &testTheme, Encoder: newNormalEncoder()})
+	l.Error("failed")
+
+	out := buf.String()
+	if !strings.Contains(out, styled(testTheme.Error, "[E]")+" "+string(testTheme.Stack)+"[console_test.go:") {
+		t.Fatalf("expected styled level and stack, got %q", out)
+	}
+	if !strings.HasSuffix(out, "]"+"\x1b[0m  "+styled(testTheme.Message, "failed")+"\r\n") {
+		t.Fatalf("expected styled message after stack, got %q", out)
+	}
+}
+
+// TestConsoleColorJSON 测试未配置编码器时保留日志记录器的JSON编码器并按元数据设置样式
+func TestConsoleColorJSON(t *testing.T) {
+	l, buf := newConsoleLogger(t, false, console.Option{
+		Color: console.ColorAlways,
+		Theme: &testTheme,
+	})
+	l.Info(`quoted "message": "x"`, field.String("user", "bob"))
+
+	out := buf.String()
+	for _, s := range []string{
+		styled(testTheme.Key, `"level"`) + ": " + styled(testTheme.Info, `"I"`),
+		styled(testTheme.Key, `"message"`) + ": " + styled(testTheme.Message, `"quoted \"message\": \"x\""`),
+		styled(testTheme.Key, `"user"`) + ": " + styled(testTheme.Value, `"bob"`),
+	} {
+		if !strings.Contains(out, s) {
+			t.Fatalf("expected %q in %q", s, out)
+		}
+	}
+	// 去除样式后为合法的JSON
+	if plain := ansiRegexp.ReplaceAllString(out, ""); !json.Valid([]byte(plain)) {
+		t.Fatalf("expected valid JSON without styles, got %q", plain)
+	}
+}
+
+// TestConsoleColorMode 测试颜色输出模式及环境变量
+func TestConsoleColorMode(t *testing.T) {
+	defer os.Unsetenv("NO_COLOR")
+	defer os.Unsetenv("FORCE_COLOR")
+	tests := []struct {
+		name  string
+		mode  console.ColorMode
+		env   map[string]string
+		color bool
+	}{
+		{"AutoNotTerminal", console.ColorAuto, nil, false},
+		{"AutoForce", console.ColorAuto, map[string]string{"FORCE_COLOR": "1"}, true},
+		{"AutoForceFalse", console.ColorAuto, map[string]string{"FORCE_COLOR": "false"}, false},
+		{"AutoNoColor", console.ColorAuto, map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false},
+		{"Always", console.ColorAlways, map[string]string{"NO_COLOR": "1"}, true},
+		{"Never", console.ColorNever, map[string]string{"FORCE_COLOR": "1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("NO_COLOR")
+			os.Unsetenv("FORCE_COLOR")
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			l, buf := newConsoleLogger(t, false, console.Option{Color: tt.mode})
+			l.Info("hello")
+			if got := strings.Contains(buf.String(), "\x1b["); got != tt.color {
+				t.Fatalf("expected color %v, got %q", tt.color, buf.String())
+			}
+		})
+	}
+}
+
+// TestConsoleLoggerEncoder 测试不输出颜色时使用日志记录器的编码器
+func TestConsoleLoggerEncoder(t *testing.T) {
+	a := console.New(console.Option{Color: console.ColorNever, Stdout: &bytes.Buffer{}})
+	if ea, ok := a.(logger.EncoderAdapter); !ok || ea.Encoder() != nil {
+		t.Fatal("expected the logger encoder to be used without color")
+	}
+
+	// 不输出颜色时的输出与日志记录器的JSON编码器一致
+	l, buf := newConsoleLogger(t, false, console.Option{Color: console.ColorNever})
+	l.Info("hello", field.String("user", "bob"))
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil || record["message"] != "hello" {
+		t.Fatalf("expected JSON output, got %q, %v", buf.String(), err)
+	}
+}
+
+// 不支持配色主题的编码器
+type plainEncoder struct{}
+
+func (plainEncoder) Encode(dst []byte, _ time.Time, _ logger.Level, msg string, _ ...field.Field) []byte {
+	return append(append(dst, msg...), '\n')
+}
+
+func (plainEncoder) EncodeStack(dst []byte, _ time.Time, _ logger.Level, _ string, _ int, _ string, msg string, _ ...field.Field) []byte {
+	return append(append(dst, msg...), '\n')
+}
+
+// TestConsoleUnthemedEncoder 测试日志记录器的编码器不支持配色主题时原样输出
+func TestConsoleUnthemedEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := belog.New(logger.Option{Encoder: plainEncoder{}}, console.New(console.Option{
+		Color:          console.ColorAlways,
+		DisabledBuffer: true,
+		Stdout:         &buf,
+		Stderr:         &buf,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("hello")
+	if buf.String() != "hello\n" {
+		t.Fatalf("expected the logger encoder output, got %q", buf.String())
+	}
+}
+
+// TestConsolePrettyEncoder 测试美化编码器按输出流是否输出颜色生成副本
+func TestConsolePrettyEncoder(t *testing.T) {
+	enc := encoder.NewPrettyEncoder(encoder.DefaultPrettyOption)
+	for _, mode := range []console.ColorMode{console.ColorAlways, console.ColorNever} {
+		l, buf := newConsoleLogger(t, false, console.Option{Color: mode, Encoder: enc})
+		l.Error("failed", field.String("user", "bob"))
+		out := buf.String()
+		if !strings.Contains(out, " ERR ") || !strings.Contains(out, "user") {
+			t.Fatalf("expected pretty output, got %q", out)
+		}
+		if got := strings.Contains(out, "\x1b["); got != (mode == console.ColorAlways) {
+			t.Fatalf("unexpected color %v for mode %d, got %q", got, mode, out)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/logger"
+	"github.com/bearki/belog/v3/pkg/crypt"
+)
+
+// 测试用密钥
+var (
+	testKey1 = bytes.Repeat([]byte{1}, 32)
+	testKey2 = bytes.Repeat([]byte{2}, 16)
+)
+
+// 加密测试分段，返回文件内容及每个分块的起始位置
+func sealSegment(t *testing.T, kp crypt.KeyProvider, chunks ...string) ([]byte, []int) {
+	t.Helper()
+	s := crypt.NewSealer(kp)
+	dst, err := s.Start(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := make([]int, 0, len(chunks))
+	for i, c := range chunks {
+		offsets = append(offsets, len(dst))
+		if dst, err = s.Seal(dst, []byte(c), i == len(chunks)-1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dst, offsets
+}
+
+// 解密加密分段
+func openSegment(data []byte, kp crypt.KeyProvider) (string, error) {
+	b, err := ioutil.ReadAll(crypt.NewReader(bytes.NewReader(data), kp))
+	return string(b), err
+}
+
+// TestCryptRoundTrip 测试加密解密及密钥轮换
+func TestCryptRoundTrip(t *testing.T) {
+	kr := crypt.NewKeyRing(1, testKey1)
+	s := crypt.NewSealer(kr)
+	data, err := s.Start(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = s.Seal(data, []byte("first\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	kr.Rotate(2, testKey2)
+	if data, err = s.Seal(data, []byte("second\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = s.Seal(data, nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := openSegment(data, kr)
+	if err != nil || plain != "first\nsecond\n" {
+		t.Fatalf("unexpected plaintext %q, %v", plain, err)
+	}
+
+	// 缺少旧密钥
+	if _, err = openSegment(data, crypt.NewKeyRing(2, testKey2)); err == nil {
+		t.Fatal("expected missing key error")
+	}
+}
+
+// TestCryptResume 测试继续写入已有的分段
+func TestCryptResume(t *testing.T) {
+	kr := crypt.NewKeyRing(1, testKey1)
+	data, _ := sealSegment(t, kr, "a\n", "b\n")
+
+	id, chunks, err := crypt.ScanSegment(bytes.NewReader(data))
+	if err != nil || chunks != 2 {
+		t.Fatalf("expected 2 chunks, got %d, %v", chunks, err)
+	}
+	s := crypt.NewSealer(kr)
+	s.Resume(id, chunks)
+	if data, err = s.Seal(data, []byte("c\n"), true); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := openSegment(data, kr)
+	if err != nil || plain != "a\nb\nc\n" {
+		t.Fatalf("unexpected plaintext %q, %v", plain, err)
+	}
+
+	// 末尾存在不完整的分块
+	if _, _, err = crypt.ScanSegment(bytes.NewReader(data[:len(data)-1])); !errors.Is(err, crypt.ErrTruncated) {
+		t.Fatalf("expected truncated error, got %v", err)
+	}
+}
+
+// TestCryptTamper 测试篡改、调换、删除、拼接及截断分块
+func TestCryptTamper(t *testing.T) {
+	kr := crypt.NewKeyRing(1, testKey1)
+	data, offsets := sealSegment(t, kr, "chunk-0\n", "chunk-1\n", "chunk-2\n")
+	other, otherOffsets := sealSegment(t, kr, "other-0\n", "other-1\n", "other-2\n")
+	chunk := func(data []byte, offsets []int, i int) []byte {
+		if i+1 < len(offsets) {
+			return data[offsets[i]:offsets[i+1]]
+		}
+		return data[offsets[i]:]
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	head := data[:offsets[0]]
+
+	// 修改密文
+	modified := append([]byte(nil), data...)
+	modified[len(modified)-1] ^= 1
+	// 修改结束标志
+	unflagged := append([]byte(nil), data...)
+	unflagged[offsets[2]+16] = 0
+
+	tests := []struct {
+		name      string
+		data      []byte
+		truncated bool
+	}{
+		{"Modified", modified, false},
+		{"Unflagged", unflagged, false},
+		{"Reordered", join(head, chunk(data, offsets, 1), chunk(data, offsets, 0), chunk(data, offsets, 2)), false},
+		{"Dropped", join(head, chunk(data, offsets, 0), chunk(data, offsets, 2)), false},
+		{"Spliced", join(head, chunk(data, offsets, 0), chunk(other, otherOffsets, 1), chunk(data, offsets, 2)), false},
+		{"OtherHeader", join(other[:otherOffsets[0]], data[offsets[0]:]), false},
+		{"FinalRemoved", data[:offsets[2]], true},
+		{"PartialChunk", data[:len(data)-3], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := openSegment(tt.data, kr)
+			if err == nil {
+				t.Fatal("expected decrypt error")
+			}
+			if errors.Is(err, crypt.ErrTruncated) != tt.truncated {
+				t.Fatalf("expected truncated %v, got %v", tt.truncated, err)
+			}
+		})
+	}
+
+	// 错误的密钥
+	if _, err := openSegment(data, crypt.NewKeyRing(1, testKey2[:16])); err == nil || errors.Is(err, crypt.ErrTruncated) {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
+
+// 创建开启加密的文件日志适配器
+func newEncryptedAdapter(t *testing.T, dir string, kp crypt.KeyProvider) *file.Adapter {
+	t.Helper()
+	a, err := file.New(file.Options{
+		LogPath:          filepath.Join(dir, "app.log"),
+		SaveDay:          7,
+		Encryption:       kp,
+		EncryptChunkSize: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.(*file.Adapter)
+}
+
+// 解密日志文件夹中的日志分段
+func decryptSegment(t *testing.T, dir string, kp crypt.KeyProvider) (string, error) {
+	t.Helper()
+	segments, err := filepath.Glob(filepath.Join(dir, "app.*.*.log"))
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("expected 1 segment, got %v, %v", segments, err)
+	}
+	f, err := os.Open(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(crypt.NewReader(f, kp))
+	return string(b), err
+}
+
+// TestFileEncrypt 测试文件日志适配器加密写入及重新打开后继续写入
+func TestFileEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	kr := crypt.NewKeyRing(1, testKey1)
+	var want strings.Builder
+	write := func(a *file.Adapter, n int) {
+		for i := 0; i < n; i++ {
+			line := strings.Repeat("x", 300) + "\n"
+			want.WriteString(line)
+			a.Print(time.Now(), logger.Info, []byte(line))
+		}
+	}
+
+	a := newEncryptedAdapter(t, dir, kr)
+	write(a, 10)
+	a.Flush()
+	// 未关闭的分段缺少结束分块
+	if plain, err := decryptSegment(t, dir, kr); !errors.Is(err, crypt.ErrTruncated) || plain != want.String() {
+		t.Fatalf("expected the flushed logs with truncated error, got %d bytes, %v", len(plain), err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后追加到同一分段
+	kr.Rotate(2, testKey2)
+	a = newEncryptedAdapter(t, dir, kr)
+	write(a, 10)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := decryptSegment(t, dir, kr)
+	if err != nil || plain != want.String() {
+		t.Fatalf("unexpected plaintext (%d bytes, want %d), %v", len(plain), want.Len(), err)
+	}
+
+	// 错误的密钥
+	if _, err = decryptSegment(t, dir, crypt.NewKeyRing(1, testKey2)); err == nil {
+		t.Fatal("expected decrypt error with the wrong key")
+	}
+}
+
+// TestDecryptCommand 测试belog-decrypt工具
+func TestDecryptCommand(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "belog-decrypt")
+	if out, err := exec.Command(goBin, "build", "-o", bin, "../cmd/belog-decrypt").CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %s, %s", err, out)
+	}
+
+	kr := crypt.NewKeyRing(1, testKey1)
+	data, offsets := sealSegment(t, kr, "hello\n", "world\n")
+	complete := filepath.Join(dir, "complete.log")
+	truncated := filepath.Join(dir, "truncated.log")
+	if err = os.WriteFile(complete, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(truncated, data[:offsets[1]], 0666); err != nil {
+		t.Fatal(err)
+	}
+	key := "1:" + strings.Repeat("01", 32)
+
+	run := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(bin, args...)
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		err := cmd.Run()
+		code := 0
+		if ee, ok := err.(*exec.ExitError); ok {
+			code = ee.ExitCode()
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		return stdout.String(), stderr.String(), code
+	}
+
+	// 正常解密
+	if out, errOut, code := run("-key", key, complete); code != 0 || out != "hello\nworld\n" || len(errOut) > 0 {
+		t.Fatalf("unexpected result %q, %q, %d", out, errOut, code)
+	}
+	// 被截断的分段输出警告
+	if out, errOut, code := run("-key", key, truncated); code != 0 || out != "hello\n" || !strings.Contains(errOut, "warning") {
+		t.Fatalf("unexpected result %q, %q, %d", out, errOut, code)
+	}
+	// 错误的密钥
+	if _, errOut, code := run("-key", "1:"+strings.Repeat("02", 32), complete); code != 1 || !strings.Contains(errOut, "error") {
+		t.Fatalf("unexpected result %q, %d", errOut, code)
+	}
+	// 缺少密钥
+	if _, _, code := run(complete); code != 2 {
+		t.Fatalf("expected usage exit code 2, got %d", code)
+	}
+}
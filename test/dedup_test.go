@@ -0,0 +1,142 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/discard"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// TestDedupByContent 测试按内容折叠重复日志
+func TestDedupByContent(t *testing.T) {
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Dedup: logger.DedupOption{Enabled: true, Window: 3600000},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 533; i++ {
+		l.Warn("disk full", field.String("disk", "sda"))
+	}
+	// 字段值不同不参与折叠
+	l.Warn("disk full", field.String("disk", "sdb"))
+	// 包含复杂值的日志不参与折叠
+	l.Warn("batch", field.Ints("ids", []int{1}))
+	l.Warn("batch", field.Ints("ids", []int{1}))
+	observer.AssertCount(t, logger.Warn, 4)
+
+	// 刷新时输出重复次数汇总
+	l.Flush()
+	observer.AssertLogged(t, logger.Warn, "previous message repeated 532 times",
+		field.String("previous", "disk full"), field.Uint64("repeated", 532))
+	observer.AssertCount(t, logger.Warn, 5)
+
+	// 汇总已输出，无需重复输出
+	l.(logger.Closer).Close()
+	observer.AssertCount(t, logger.Warn, 5)
+}
+
+// TestDedupWindow 测试窗口结束后先输出汇总再记录日志
+func TestDedupWindow(t *testing.T) {
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Dedup: logger.DedupOption{Enabled: true, Window: 50},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("tick")
+	l.Info("tick")
+	l.Info("tick")
+	time.Sleep(100 * time.Millisecond)
+	l.Info("tick")
+
+	entries := observer.All()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Message != "tick" ||
+		entries[1].Message != "previous message repeated 2 times" ||
+		entries[2].Message != "tick" {
+		t.Fatalf("unexpected entries %q, %q, %q", entries[0].Message, entries[1].Message, entries[2].Message)
+	}
+}
+
+// TestDedupWindowExpiry 测试窗口结束后没有新日志时也输出汇总
+func TestDedupWindowExpiry(t *testing.T) {
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Dedup: logger.DedupOption{Enabled: true, Window: 50},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.(logger.Closer).Close()
+
+	l.Info("once")
+	for i := 0; i < 3; i++ {
+		l.Error("tick")
+	}
+	deadline := time.Now().Add(time.Second)
+	for observer.Len() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected summary after the window, got %d entries", observer.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	observer.AssertLogged(t, logger.Error, "previous message repeated 2 times",
+		field.String("previous", "tick"), field.Uint64("repeated", 2))
+
+	// 没有被折叠的日志时不输出汇总，已输出的汇总不再重复输出
+	time.Sleep(100 * time.Millisecond)
+	l.Flush()
+	observer.AssertCount(t, logger.Info, 1)
+	observer.AssertCount(t, logger.Error, 2)
+}
+
+// TestDedupByCaller 测试按调用位置折叠重复日志
+func TestDedupByCaller(t *testing.T) {
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Dedup: logger.DedupOption{Enabled: true, Window: 3600000, Key: logger.DedupByCaller},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		l.Error("request failed", field.Int("attempt", i))
+	}
+	// 不同调用位置单独计数
+	l.Error("request failed", field.Int("attempt", 10))
+	observer.AssertCount(t, logger.Error, 2)
+
+	l.Flush()
+	observer.AssertLogged(t, logger.Error, "previous message repeated 9 times")
+}
+
+// BenchmarkDedupSuppressed 测试被折叠的重复日志开销
+func BenchmarkDedupSuppressed(b *testing.B) {
+	l, err := belog.New(logger.Option{
+		Dedup: logger.DedupOption{Enabled: true, Window: 3600000},
+	}, discard.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// 重置测试参数
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// 执行测试
+	for i := 0; i < b.N; i++ {
+		l.Warn("this is a duplicate log", field.String("key", "value"))
+	}
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bearki/belog/v3/encoder"
+)
+
+// TestAppendJSONString 测试JSON字符串转义
+func TestAppendJSONString(t *testing.T) {
+	for s, want := range map[string]string{
+		`plain`:             `plain`,
+		"quote\" slash\\":   "quote\" slash\\",
+		"line\r\n\ttab\x00": "line\r\n\ttab\x00",
+		"bad \xff utf8 中文":  "bad � utf8 中文",
+	} {
+		out := encoder.AppendJSONString([]byte("prefix:"), s)
+		var got string
+		if err := json.Unmarshal(out[len("prefix:"):], &got); err != nil || got != want {
+			t.Fatalf("%q: unexpected output %q, %v", s, out, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 创建测试用文件日志适配器
+func newTestFileAdapter(t *testing.T, dir string, opt file.Options) logger.Adapter {
+	t.Helper()
+	opt.LogPath = filepath.Join(dir, "app.log")
+	if opt.MaxSize == 0 {
+		opt.MaxSize = 100
+	}
+	if opt.MaxLines == 0 {
+		opt.MaxLines = 100000
+	}
+	opt.SaveDay = 7
+	a, err := file.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = a.(*file.Adapter).Close() })
+	return a
+}
+
+// 等待日志分段中出现指定条数的日志
+func waitSegmentLines(t *testing.T, dir string, n int, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if len(readSegmentLines(t, dir)) >= n {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestFileBatchWrite 测试批量写入保持日志顺序且按行数分割文件
+func TestFileBatchWrite(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestFileAdapter(t, dir, file.Options{
+		MaxLines:     100,
+		Async:        true,
+		AsyncChanCap: 100,
+		BatchWrite:   true,
+		BatchMaxSize: 4,
+	})
+	const total = 1000
+	for i := 1; i <= total; i++ {
+		a.Print(time.Now(), logger.Info, []byte("seq "+strconv.Itoa(i)+" batch\n"))
+	}
+	a.Flush()
+
+	lines := readSegmentLines(t, dir)
+	records, _ := checkOverflowLines(t, lines, plainDropNotice)
+	if records != total {
+		t.Fatalf("expected %d records, got %d", total, records)
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "app.*.*.log"))
+	if len(segments) < total/100/2 {
+		t.Fatalf("expected the batches to be split by lines, got %d segments", len(segments))
+	}
+}
+
+// TestFileBatchVisible 测试批量写入不经过缓冲区，写入后无需刷新即可读取
+func TestFileBatchVisible(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestFileAdapter(t, dir, file.Options{
+		Async:      true,
+		BatchWrite: true,
+		SyncPolicy: file.SyncNever,
+	})
+	a.Print(time.Now(), logger.Info, []byte("seq 1 batch\n"))
+	if !waitSegmentLines(t, dir, 1, time.Second) {
+		t.Fatal("expected the batch to be written without flush")
+	}
+}
+
+// TestFileSyncPolicy 测试各同步策略下日志到达文件的时机
+func TestFileSyncPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     file.Options
+		visible bool // 单条日志无需刷新即可读取
+	}{
+		// 同步写入模式下每次写入后同步（替代原O_SYNC的行为）
+		{"AutoSync", file.Options{}, true},
+		// 异步写入模式下仅在刷新时同步
+		{"AutoAsync", file.Options{Async: true}, false},
+		{"Never", file.Options{Async: true, SyncPolicy: file.SyncNever}, false},
+		{"PerBatch", file.Options{Async: true, SyncPolicy: file.SyncPerBatch}, true},
+		{"Interval", file.Options{Async: true, SyncPolicy: file.SyncInterval, SyncInterval: 10}, true},
+		// 未达到阈值时不同步
+		{"Bytes", file.Options{Async: true, SyncPolicy: file.SyncBytes, SyncBytes: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			a := newTestFileAdapter(t, dir, tt.opt)
+			a.Print(time.Now(), logger.Info, []byte("seq 1 sync\n"))
+			if got := waitSegmentLines(t, dir, 1, 200*time.Millisecond); got != tt.visible {
+				t.Fatalf("expected visible %v before flush, got %v", tt.visible, got)
+			}
+			a.Flush()
+			if lines := readSegmentLines(t, dir); len(lines) != 1 {
+				t.Fatalf("expected 1 line after flush, got %d", len(lines))
+			}
+		})
+	}
+
+	// 写入量达到阈值后同步
+	dir := t.TempDir()
+	a := newTestFileAdapter(t, dir, file.Options{Async: true, SyncPolicy: file.SyncBytes, SyncBytes: 1})
+	padding := strings.Repeat("x", 100)
+	for i := 1; i <= 10; i++ {
+		a.Print(time.Now(), logger.Info, []byte("seq "+strconv.Itoa(i)+" "+padding+"\n"))
+	}
+	if !waitSegmentLines(t, dir, 10, time.Second) {
+		t.Fatal("expected the logs to be synced after reaching the threshold")
+	}
+}
@@ -1,7 +1,12 @@
 package test
 
 import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bearki/belog/v3"
 	"github.com/bearki/belog/v3/adapter/file"
@@ -55,3 +60,123 @@ func BenchmarkBelogLoggerFileWrite(b *testing.B) {
 		)
 	}
 }
+
+// 生成指定大小的日志分段文件
+func writeSegmentFile(b *testing.B, fileName string, size int) {
+	line := append(bytes.Repeat([]byte{'x'}, 198), '\r', '\n')
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		b.Fatalf("segment file create failed, %s\r\n", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for n := 0; n < size; n += len(line) {
+		_, _ = w.Write(line)
+	}
+	if err = w.Flush(); err != nil {
+		b.Fatalf("segment file write failed, %s\r\n", err)
+	}
+}
+
+// 重新打开日志文件适配器（选择可用分段时将获取已有分段的行数）
+func reopenFileAdapter(b *testing.B, dir string) *file.Adapter {
+	fileAdapter, err := file.New(file.Options{
+		LogPath:  filepath.Join(dir, "app.log"),
+		MaxSize:  200,
+		MaxLines: 100000000,
+		SaveDay:  7,
+	})
+	if err != nil {
+		b.Fatalf("file adapter create failed, %s\r\n", err)
+	}
+	return fileAdapter.(*file.Adapter)
+}
+
+// 关闭重新打开的日志文件适配器（不计入耗时）
+//
+//	关闭时会刷新分段索引，需要恢复为关闭前的状态，保证每次迭代的场景一致
+func closeReopenedAdapter(b *testing.B, dir string, fileAdapter *file.Adapter) {
+	b.StopTimer()
+	defer b.StartTimer()
+
+	// 保存关闭前的分段索引
+	indexes, _ := filepath.Glob(filepath.Join(dir, ".*.idx"))
+	saved := make(map[string][]byte, len(indexes))
+	for _, v := range indexes {
+		data, err := os.ReadFile(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+		saved[v] = data
+	}
+
+	if err := fileAdapter.Close(); err != nil {
+		b.Fatalf("file adapter close failed, %s\r\n", err)
+	}
+
+	// 恢复分段索引
+	indexes, _ = filepath.Glob(filepath.Join(dir, ".*.idx"))
+	for _, v := range indexes {
+		_ = os.Remove(v)
+	}
+	for name, data := range saved {
+		if err := os.WriteFile(name, data, 0666); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBelogFileSegmentReopen 测试重新打开已有日志分段时获取行数的开销
+func BenchmarkBelogFileSegmentReopen(b *testing.B) {
+	// 生成64MB的日志分段
+	dir := b.TempDir()
+	segment := filepath.Join(dir, "app."+time.Now().Format("2006-01-02")+".1.log")
+	writeSegmentFile(b, segment, 64*1024*1024)
+
+	// 初始化文件日志适配器，刷新时将生成分段索引
+	fileAdapter := reopenFileAdapter(b, dir)
+	fileAdapter.Flush()
+	if err := fileAdapter.Close(); err != nil {
+		b.Fatalf("file adapter close failed, %s\r\n", err)
+	}
+
+	// 旧方式：使用bufio.Scanner扫描整个文件
+	b.Run("Scanner", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			f, err := os.Open(segment)
+			if err != nil {
+				b.Fatal(err)
+			}
+			reader := bufio.NewScanner(f)
+			for reader.Scan() {
+			}
+			f.Close()
+		}
+	})
+
+	// 索引与文件大小一致
+	b.Run("Indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			closeReopenedAdapter(b, dir, reopenFileAdapter(b, dir))
+		}
+	})
+
+	// 索引之后追加了1MB内容
+	writeSegmentFile(b, segment, 1024*1024)
+	b.Run("IndexedTail", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			closeReopenedAdapter(b, dir, reopenFileAdapter(b, dir))
+		}
+	})
+
+	// 索引丢失时通过尾部采样估算
+	indexes, _ := filepath.Glob(filepath.Join(dir, ".*.idx"))
+	for _, v := range indexes {
+		_ = os.Remove(v)
+	}
+	b.Run("Estimate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			closeReopenedAdapter(b, dir, reopenFileAdapter(b, dir))
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 获取测试用日志分段路径
+func segmentPath(dir string, index int) string {
+	return filepath.Join(dir, "app."+time.Now().Format("2006-01-02")+"."+strconv.Itoa(index)+".log")
+}
+
+// 获取日志分段对应的索引文件路径
+func segmentIndexPath(segment string) string {
+	dir, name := filepath.Split(segment)
+	return filepath.Join(dir, "."+name+".idx")
+}
+
+// 写入日志分段索引
+func writeIndex(t *testing.T, segment string, magic string, size uint64, lines uint64) {
+	b := make([]byte, 24)
+	copy(b[:8], magic)
+	binary.LittleEndian.PutUint64(b[8:16], size)
+	binary.LittleEndian.PutUint64(b[16:24], lines)
+	if err := os.WriteFile(segmentIndexPath(segment), b, 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 生成指定行数的日志内容
+func repeatLines(line string, n int) []byte {
+	return bytes.Repeat([]byte(line+"\n"), n)
+}
+
+// 使用已有的日志分段创建适配器并写入一条日志，返回日志是否追加到了已有分段
+func appendToSegment(t *testing.T, dir string, maxLines uint64) bool {
+	t.Helper()
+	a, err := file.New(file.Options{
+		LogPath:  filepath.Join(dir, "app.log"),
+		MaxSize:  200,
+		MaxLines: maxLines,
+		SaveDay:  7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.(*file.Adapter).Close()
+	a.Print(time.Now(), logger.Info, []byte("new line\n"))
+	a.Flush()
+
+	_, err = os.Stat(segmentPath(dir, 2))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return os.IsNotExist(err)
+}
+
+// TestFileIndexRebuild 测试无索引时统计行数并在刷新后重建索引
+func TestFileIndexRebuild(t *testing.T) {
+	// 行数已达上限，需要切换到下一个分段
+	dir := t.TempDir()
+	if err := os.WriteFile(segmentPath(dir, 1), repeatLines("line", 100), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if appendToSegment(t, dir, 100) {
+		t.Fatal("expected the full segment to be skipped")
+	}
+
+	// 行数未达上限，追加到已有分段并重建索引
+	dir = t.TempDir()
+	segment := segmentPath(dir, 1)
+	if err := os.WriteFile(segment, repeatLines("line", 100), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if !appendToSegment(t, dir, 102) {
+		t.Fatal("expected the log to be appended to the existing segment")
+	}
+	b, err := os.ReadFile(segmentIndexPath(segment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 24 || string(b[:8]) != "BELOGIDX" {
+		t.Fatalf("unexpected index content %q", b)
+	}
+	if size := binary.LittleEndian.Uint64(b[8:16]); size != uint64(info.Size()) {
+		t.Fatalf("expected indexed size %d, got %d", info.Size(), size)
+	}
+	if lines := binary.LittleEndian.Uint64(b[16:24]); lines != 101 {
+		t.Fatalf("expected indexed lines 101, got %d", lines)
+	}
+}
+
+// TestFileIndexStale 测试过期或损坏的索引
+func TestFileIndexStale(t *testing.T) {
+	content := repeatLines("line", 100)
+	half := uint64(len(content) / 2)
+	tests := []struct {
+		name   string
+		magic  string
+		size   uint64
+		lines  uint64
+		append bool
+	}{
+		// 索引有效时直接使用索引中的行数
+		{"Valid", "BELOGIDX", uint64(len(content)), 3, true},
+		// 索引之后追加了内容，统计增量部分
+		{"Tail", "BELOGIDX", half, 50, false},
+		// 索引记录的大小超过文件大小（文件被截断或替换），重新统计
+		{"Truncated", "BELOGIDX", uint64(len(content)) * 2, 0, false},
+		// 索引标识错误，重新统计
+		{"Corrupt", "XXXXXXXX", uint64(len(content)), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			segment := segmentPath(dir, 1)
+			if err := os.WriteFile(segment, content, 0666); err != nil {
+				t.Fatal(err)
+			}
+			writeIndex(t, segment, tt.magic, tt.size, tt.lines)
+			if got := appendToSegment(t, dir, 100); got != tt.append {
+				t.Fatalf("expected append %v, got %v", tt.append, got)
+			}
+		})
+	}
+
+	// 索引文件大小错误
+	dir := t.TempDir()
+	segment := segmentPath(dir, 1)
+	if err := os.WriteFile(segment, content, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(segmentIndexPath(segment), []byte("BELOGIDX"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if appendToSegment(t, dir, 100) {
+		t.Fatal("expected the short index to be ignored")
+	}
+}
+
+// TestFileIndexLongLine 测试超过64KB的单行日志
+func TestFileIndexLongLine(t *testing.T) {
+	content := append(repeatLines(string(bytes.Repeat([]byte{'x'}, 100*1024)), 1), repeatLines("line", 99)...)
+	for _, tt := range []struct {
+		maxLines uint64
+		append   bool
+	}{{100, false}, {102, true}} {
+		dir := t.TempDir()
+		if err := os.WriteFile(segmentPath(dir, 1), content, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if got := appendToSegment(t, dir, tt.maxLines); got != tt.append {
+			t.Fatalf("max lines %d: expected append %v, got %v", tt.maxLines, tt.append, got)
+		}
+	}
+}
+
+// TestFileIndexEstimate 测试无索引的大文件通过尾部采样估算行数
+func TestFileIndexEstimate(t *testing.T) {
+	// 5MB，共26214行
+	content := repeatLines(string(bytes.Repeat([]byte{'x'}, 199)), 26214)
+	for _, tt := range []struct {
+		maxLines uint64
+		append   bool
+	}{{20000, false}, {30000, true}} {
+		dir := t.TempDir()
+		if err := os.WriteFile(segmentPath(dir, 1), content, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if got := appendToSegment(t, dir, tt.maxLines); got != tt.append {
+			t.Fatalf("max lines %d: expected append %v, got %v", tt.maxLines, tt.append, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 溢出测试写入的日志条数
+const overflowRecords = 5000
+
+// 读取文件夹中全部日志分段的内容（按分段顺序）
+func readSegmentLines(t *testing.T, dir string) []string {
+	t.Helper()
+	segments, err := filepath.Glob(filepath.Join(dir, "app.*.*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return len(segments[i]) < len(segments[j]) || len(segments[i]) == len(segments[j]) && segments[i] < segments[j]
+	})
+	var lines []string
+	for _, v := range segments {
+		b, err := os.ReadFile(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// 使用指定的溢出策略写入日志，返回写入的日志及适配器统计信息
+//
+//	每条日志携带递增序号并通过频繁同步拖慢写入协程，使管道更容易溢出
+func writeOverflow(t *testing.T, opt file.Options) ([]string, file.Stats) {
+	t.Helper()
+	dir := t.TempDir()
+	opt.LogPath = filepath.Join(dir, "app.log")
+	opt.MaxSize = 1000
+	opt.MaxLines = 100000000
+	opt.SaveDay = 7
+	opt.Async = true
+	opt.SyncPolicy = file.SyncBytes
+	opt.SyncBytes = 1
+	a, err := file.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.(*file.Adapter).Close()
+
+	padding := strings.Repeat("x", 1024)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	seq := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// 序号的分配与写入保持同一顺序
+				mutex.Lock()
+				if seq == overflowRecords {
+					mutex.Unlock()
+					return
+				}
+				seq++
+				a.Print(time.Now(), logger.Info, []byte("seq "+strconv.Itoa(seq)+" "+padding+"\n"))
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	a.Flush()
+	return readSegmentLines(t, dir), a.(*file.Adapter).Stats()
+}
+
+// 拆分日志记录与丢弃提示，校验日志序号递增，返回日志条数及提示中的丢弃条数之和
+func checkOverflowLines(t *testing.T, lines []string, notice func(string) (uint64, bool)) (int, uint64) {
+	t.Helper()
+	var records int
+	var dropped uint64
+	last := 0
+	for _, line := range lines {
+		if n, ok := notice(line); ok {
+			dropped += n
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "seq" {
+			t.Fatalf("unexpected line %q", line)
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n <= last {
+			t.Fatalf("record %d written after %d", n, last)
+		}
+		last = n
+		records++
+	}
+	return records, dropped
+}
+
+// 解析纯文本格式的丢弃提示
+func plainDropNotice(line string) (uint64, bool) {
+	i := strings.Index(line, " records dropped")
+	if i < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(line[:i])
+	n, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+	return n, err == nil && strings.Contains(line, "[W]")
+}
+
+// TestFileOverflowBlock 测试阻塞策略不丢弃日志
+func TestFileOverflowBlock(t *testing.T) {
+	lines, stats := writeOverflow(t, file.Options{Overflow: file.OverflowBlock})
+	records, dropped := checkOverflowLines(t, lines, plainDropNotice)
+	if records != overflowRecords || dropped != 0 || stats.Dropped != 0 || stats.Spilled != 0 {
+		t.Fatalf("expected %d records without drops, got %d records, %d noticed, stats %+v", overflowRecords, records, dropped, stats)
+	}
+}
+
+// TestFileOverflowBlockClose 测试阻塞策略下关闭时不会永久阻塞写入方
+func TestFileOverflowBlockClose(t *testing.T) {
+	a, err := file.New(file.Options{
+		LogPath:      filepath.Join(t.TempDir(), "app.log"),
+		SaveDay:      7,
+		Async:        true,
+		AsyncChanCap: 1,
+		SyncPolicy:   file.SyncBytes,
+		SyncBytes:    1,
+		Overflow:     file.OverflowBlock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 持续写入直到关闭之后
+	record := []byte(strings.Repeat("x", 64*1024) + "\n")
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				a.Print(time.Now(), logger.Info, record)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	waitDone := func(name string, f func()) {
+		done := make(chan struct{})
+		go func() {
+			f()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s blocked after close", name)
+		}
+	}
+	waitDone("close", func() {
+		if err := a.(*file.Adapter).Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	waitDone("print", wg.Wait)
+}
+
+// TestFileOverflowDrop 测试丢弃策略的统计及丢弃提示
+func TestFileOverflowDrop(t *testing.T) {
+	for _, policy := range []file.OverflowPolicy{file.OverflowDropNewest, file.OverflowDropOldest} {
+		lines, stats := writeOverflow(t, file.Options{Overflow: policy})
+		records, dropped := checkOverflowLines(t, lines, plainDropNotice)
+		if stats.Dropped == 0 {
+			t.Skipf("policy %d: the write channel never overflowed", policy)
+		}
+		if uint64(records)+stats.Dropped != overflowRecords {
+			t.Fatalf("policy %d: %d records written and %d dropped, expected %d in total", policy, records, stats.Dropped, overflowRecords)
+		}
+		if dropped != stats.Dropped {
+			t.Fatalf("policy %d: drop notices report %d records, stats report %d", policy, dropped, stats.Dropped)
+		}
+		if stats.Spilled != 0 {
+			t.Fatalf("policy %d: unexpected spilled records %d", policy, stats.Spilled)
+		}
+	}
+}
+
+// TestFileOverflowDropOldest 测试丢弃最旧日志时保留最新的日志
+func TestFileOverflowDropOldest(t *testing.T) {
+	lines, stats := writeOverflow(t, file.Options{Overflow: file.OverflowDropOldest})
+	if stats.Dropped == 0 {
+		t.Skip("the write channel never overflowed")
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if _, ok := plainDropNotice(lines[i]); ok {
+			continue
+		}
+		if !strings.HasPrefix(lines[i], "seq "+strconv.Itoa(overflowRecords)+" ") {
+			t.Fatalf("expected the newest record to be kept, got %q", lines[i])
+		}
+		break
+	}
+}
+
+// TestFileOverflowSpill 测试溢出到二级缓冲区
+func TestFileOverflowSpill(t *testing.T) {
+	// 二级缓冲区足够大时不丢弃日志，且保持写入顺序
+	lines, stats := writeOverflow(t, file.Options{Overflow: file.OverflowSpill, SpillCap: overflowRecords})
+	records, dropped := checkOverflowLines(t, lines, plainDropNotice)
+	if records != overflowRecords || dropped != 0 || stats.Dropped != 0 {
+		t.Fatalf("expected %d records without drops, got %d records, %d noticed, stats %+v", overflowRecords, records, dropped, stats)
+	}
+	if stats.Spilled == 0 {
+		t.Skip("the write channel never overflowed")
+	}
+
+	// 二级缓冲区已满时丢弃其中最旧的日志
+	lines, stats = writeOverflow(t, file.Options{Overflow: file.OverflowSpill, SpillCap: 1})
+	records, dropped = checkOverflowLines(t, lines, plainDropNotice)
+	if uint64(records)+stats.Dropped != overflowRecords || dropped != stats.Dropped {
+		t.Fatalf("%d records written, %d noticed, stats %+v", records, dropped, stats)
+	}
+}
+
+// TestFileOverflowDropNoticeEncoder 测试使用编码器生成丢弃提示
+func TestFileOverflowDropNoticeEncoder(t *testing.T) {
+	lines, stats := writeOverflow(t, file.Options{
+		Overflow:          file.OverflowDropNewest,
+		DropNoticeEncoder: encoder.NewJsonEncoder(encoder.DefaultJsonOption),
+	})
+	if stats.Dropped == 0 {
+		t.Skip("the write channel never overflowed")
+	}
+	_, dropped := checkOverflowLines(t, lines, func(line string) (uint64, bool) {
+		if !strings.HasPrefix(line, "{") {
+			return 0, false
+		}
+		var notice struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+			Fields  struct {
+				Dropped uint64 `json:"dropped"`
+			} `json:"fields"`
+		}
+		if err := json.Unmarshal([]byte(line), &notice); err != nil {
+			t.Fatalf("%s: %q", err, line)
+		}
+		if notice.Level != "W" || notice.Message != "records dropped" {
+			t.Fatalf("unexpected drop notice %q", line)
+		}
+		return notice.Fields.Dropped, true
+	})
+	if dropped != stats.Dropped {
+		t.Fatalf("drop notices report %d records, stats report %d", dropped, stats.Dropped)
+	}
+}
@@ -0,0 +1,132 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 崩溃测试子进程的环境变量（日志文件夹）
+const crashHelperEnv = "TEST_FILE_CRASH_DIR"
+
+// 崩溃测试写入的日志条数（每条约200字节，超过环形缓冲区容量，覆盖回绕）
+const crashRecords = 2000
+
+// 创建开启崩溃保护的适配器
+func newCrashSafeAdapter(t *testing.T, dir string) *file.Adapter {
+	t.Helper()
+	a, err := file.New(file.Options{
+		LogPath:       filepath.Join(dir, "app.log"),
+		MaxLines:      100000,
+		SaveDay:       7,
+		Async:         true,
+		AsyncChanCap:  100,
+		CrashSafe:     true,
+		CrashSafeSize: 64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.(*file.Adapter)
+}
+
+// 写入带序号的日志
+func writeCrashRecords(a *file.Adapter) {
+	padding := strings.Repeat("x", 180)
+	for i := 1; i <= crashRecords; i++ {
+		a.Print(time.Now(), logger.Info, []byte("seq "+strconv.Itoa(i)+" "+padding+"\n"))
+	}
+}
+
+// 统计每个序号出现的次数
+func countCrashRecords(t *testing.T, dir string) map[int]int {
+	t.Helper()
+	seen := make(map[int]int)
+	for _, line := range readSegmentLines(t, dir) {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "seq" {
+			t.Fatalf("unexpected line %q", line)
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[n]++
+	}
+	return seen
+}
+
+// TestFileCrashHelper 崩溃测试子进程，写入日志后立即被强制结束
+func TestFileCrashHelper(t *testing.T) {
+	dir := os.Getenv(crashHelperEnv)
+	if len(dir) == 0 {
+		t.Skip("only runs as the crash test subprocess")
+	}
+	a := newCrashSafeAdapter(t, dir)
+	writeCrashRecords(a)
+	_ = syscall.Kill(os.Getpid(), syscall.SIGKILL)
+}
+
+// TestFileCrashRecovery 测试进程被强制结束后恢复未写入文件的日志
+func TestFileCrashRecovery(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFileCrashHelper$")
+	cmd.Env = append(os.Environ(), crashHelperEnv+"="+dir)
+	err := cmd.Run()
+	if ee, ok := err.(*exec.ExitError); !ok || ee.Sys().(syscall.WaitStatus).Signal() != syscall.SIGKILL {
+		t.Fatalf("expected the subprocess to be killed, got %v", err)
+	}
+
+	// 重新创建适配器时恢复环形缓冲区中的日志
+	a := newCrashSafeAdapter(t, dir)
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	seen := countCrashRecords(t, dir)
+	for i := 1; i <= crashRecords; i++ {
+		if seen[i] == 0 {
+			t.Fatalf("record %d lost after crash", i)
+		}
+	}
+}
+
+// TestFileCrashRingWrap 测试环形缓冲区回绕后正常关闭不会重复恢复日志
+func TestFileCrashRingWrap(t *testing.T) {
+	dir := t.TempDir()
+	a := newCrashSafeAdapter(t, dir)
+	writeCrashRecords(a)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭后解除内存映射
+	if maps, err := os.ReadFile("/proc/self/maps"); err == nil && strings.Contains(string(maps), ".app.log.ring") {
+		t.Fatal("expected the crash-safe ring to be unmapped after close")
+	}
+
+	// 重新打开后没有需要恢复的日志
+	a = newCrashSafeAdapter(t, dir)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	seen := countCrashRecords(t, dir)
+	if len(seen) != crashRecords {
+		t.Fatalf("expected %d records, got %d", crashRecords, len(seen))
+	}
+	for i, n := range seen {
+		if n != 1 {
+			t.Fatalf("record %d written %d times", i, n)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/file"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 读取日志文件夹中指定名称的全部日志分段
+func readRouteLines(t *testing.T, dir string, name string) []string {
+	t.Helper()
+	segments, err := filepath.Glob(filepath.Join(dir, name+".*.*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, v := range segments {
+		b, err := os.ReadFile(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// 创建带路由规则的记录器
+func newRouteLogger(t *testing.T, dir string, option logger.Option, opt file.Options, adapters ...logger.Adapter) logger.Logger {
+	t.Helper()
+	opt.LogPath = filepath.Join(dir, "app.log")
+	opt.SaveDay = 7
+	a, err := file.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = a.(*file.Adapter).Close() })
+	l, err := belog.New(option, append(adapters, a)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// 判断日志中是否包含指定内容
+func containsLine(lines []string, s string) bool {
+	for _, line := range lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestFileRouteLevels 测试按日志级别路由
+func TestFileRouteLevels(t *testing.T) {
+	dir := t.TempDir()
+	l := newRouteLogger(t, dir, logger.Option{}, file.Options{
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "error.log"), Match: file.Levels(logger.Error, logger.Fatal)},
+			{LogPath: filepath.Join(dir, "debug.log"), Match: file.LevelRange(logger.Trace, logger.Debug)},
+		},
+	})
+	l.Debug("debug message")
+	l.Info("info message")
+	l.Error("error message")
+	l.Flush()
+
+	main := readRouteLines(t, dir, "app")
+	errs := readRouteLines(t, dir, "error")
+	debugs := readRouteLines(t, dir, "debug")
+	if len(main) != 3 || !containsLine(main, "debug message") || !containsLine(main, "error message") {
+		t.Fatalf("expected all logs in the main file, got %q", main)
+	}
+	if len(errs) != 1 || !containsLine(errs, "error message") {
+		t.Fatalf("expected only the error log in the error file, got %q", errs)
+	}
+	if len(debugs) != 1 || !containsLine(debugs, "debug message") {
+		t.Fatalf("expected only the debug log in the debug file, got %q", debugs)
+	}
+}
+
+// TestFileRouteFieldEquals 测试按字段值路由
+func TestFileRouteFieldEquals(t *testing.T) {
+	dir := t.TempDir()
+	l := newRouteLogger(t, dir, logger.Option{Encoder: newNormalEncoder()}, file.Options{
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "acme.log"), Match: file.FieldEquals("tenant", "acme")},
+			{LogPath: filepath.Join(dir, "fail.log"), Match: file.FieldEquals("code", "500")},
+		},
+	})
+	// 消息中包含与字段相同的内容时不匹配
+	l.Info("hello, tenant:acme,")
+	l.Info(`"tenant": "acme"`, field.String("user", "tenant:acme"))
+	l.Info("other tenant", field.String("tenant", "acme-corp"))
+	// 字段匹配
+	l.Info("acme request", field.String("tenant", "acme"), field.Int("code", 200))
+	l.Warn("failed request", field.String("tenant", "other"), field.Int("code", 500))
+	l.Flush()
+
+	// 主日志文件与路由日志文件均使用记录器的编码器
+	if main := readRouteLines(t, dir, "app"); len(main) != 5 || !containsLine(main, "failed request, tenant:other, code:500") {
+		t.Fatalf("expected 5 logs in the main file, got %q", main)
+	}
+	acme := readRouteLines(t, dir, "acme")
+	if len(acme) != 1 || !containsLine(acme, "acme request") {
+		t.Fatalf("expected only the acme request in the acme file, got %q", acme)
+	}
+	fail := readRouteLines(t, dir, "fail")
+	if len(fail) != 1 || !containsLine(fail, "failed request, tenant:other, code:500") {
+		t.Fatalf("expected only the failed request in the fail file, got %q", fail)
+	}
+}
+
+// TestFileRouteDirectPrint 测试适配器不自带编码器，直接调用Print时仅写入主日志文件
+func TestFileRouteDirectPrint(t *testing.T) {
+	dir := t.TempDir()
+	a, err := file.New(file.Options{
+		LogPath: filepath.Join(dir, "app.log"),
+		SaveDay: 7,
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "error.log"), Match: file.Levels(logger.Error)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.(*file.Adapter).Close()
+	if _, ok := a.(logger.EncoderAdapter); ok {
+		t.Fatal("expected the file adapter to use the logger encoder")
+	}
+	a.Print(time.Now(), logger.Error, []byte("direct\n"))
+	a.Flush()
+	if main := readRouteLines(t, dir, "app"); len(main) != 1 {
+		t.Fatalf("expected the direct log in the main file, got %q", main)
+	}
+	if errs := readRouteLines(t, dir, "error"); len(errs) != 0 {
+		t.Fatalf("expected no direct log in the route file, got %q", errs)
+	}
+}
+
+// TestFileRouteAsyncDrop 测试异步队列丢弃的日志不会写入路由日志文件
+func TestFileRouteAsyncDrop(t *testing.T) {
+	dir := t.TempDir()
+	gate := make(chan struct{})
+	slow := &collectAdapter{name: "slow", gate: gate}
+	l := newRouteLogger(t, dir, logger.Option{
+		Encoder: newNormalEncoder(),
+		Async:   logger.AsyncOption{Enabled: true, QueueSize: 4, Overflow: logger.OverflowDropNewest},
+	}, file.Options{
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "error.log"), Match: file.Levels(logger.Error)},
+		},
+	}, slow)
+
+	// 第一条日志阻塞在慢适配器中，随后的日志填满队列后被丢弃
+	l.Error("0")
+	time.Sleep(50 * time.Millisecond)
+	for i := 1; i < 20; i++ {
+		l.Error(strconv.Itoa(i))
+	}
+	close(gate)
+	l.(logger.Closer).Close()
+
+	if l.(logger.AsyncStats).AsyncDropped() == 0 {
+		t.Fatal("expected dropped logs")
+	}
+	main := readRouteLines(t, dir, "app")
+	errs := readRouteLines(t, dir, "error")
+	if strings.Join(main, "\n") != strings.Join(errs, "\n") {
+		t.Fatalf("expected the route file to match the main file, main %q, route %q", main, errs)
+	}
+}
+
+// TestFileRouteNewError 测试创建路由写入器失败时关闭已创建的写入器
+func TestFileRouteNewError(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	before := runtime.NumGoroutine()
+	_, err := file.New(file.Options{
+		LogPath: filepath.Join(dir, "app.log"),
+		SaveDay: 7,
+		Routes: []file.Route{
+			{LogPath: filepath.Join(dir, "error.log"), Match: file.Levels(logger.Error)},
+			{LogPath: filepath.Join(blocker, "route.log"), Match: file.Levels(logger.Error)},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected route writer create error")
+	}
+
+	// 等待过期日志文件删除协程结束
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the created writers to be closed, %d goroutines before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
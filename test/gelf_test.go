@@ -0,0 +1,182 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/gelf"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 创建使用GELF适配器的记录器
+func newGELFLogger(t *testing.T, opt gelf.Options) logger.Logger {
+	adapter, err := gelf.New(opt)
+	if err != nil {
+		t.Fatalf("gelf adapter create failed, %s", err)
+	}
+	l, err := belog.New(logger.Option{EnabledStackPrint: true}, adapter)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	return l
+}
+
+// 解析GELF消息
+func decodeGELF(t *testing.T, data []byte) map[string]interface{} {
+	var msg map[string]interface{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("invalid gelf message %q, %s", data, err)
+	}
+	return msg
+}
+
+// TestGELFUDPChunkedGzip 测试UDP分块及gzip压缩
+func TestGELFUDPChunkedGzip(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newGELFLogger(t, gelf.Options{
+		Address:     conn.LocalAddr().String(),
+		Host:        "host1",
+		Compression: gelf.CompressionGzip,
+		ChunkSize:   128,
+	})
+
+	// 随机性较强的内容，保证压缩后仍需分块
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString(time.Duration(i * 7919).String())
+	}
+	l.Error("first line\nsecond line", field.String("payload", sb.String()), field.Int("id", 7), field.Float64("ratio", 0.5))
+
+	// 接收并重组分块
+	var chunks [][]byte
+	buf := make([]byte, 65536)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read datagram failed, %s", err)
+		}
+		if n > 128 || buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatalf("invalid chunk, len %d", n)
+		}
+		count := int(buf[11])
+		if chunks == nil {
+			chunks = make([][]byte, count)
+		}
+		chunks[buf[10]] = append([]byte(nil), buf[chunkHeader:n]...)
+		if len(chunks) < 2 {
+			t.Fatalf("expected multiple chunks, got %d", count)
+		}
+		done := true
+		for _, c := range chunks {
+			done = done && c != nil
+		}
+		if done {
+			break
+		}
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(bytes.Join(chunks, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(zr)
+	msg := decodeGELF(t, data)
+
+	if msg["version"] != "1.1" || msg["host"] != "host1" || msg["level"] != float64(3) {
+		t.Fatalf("unexpected message %v", msg)
+	}
+	if msg["short_message"] != "first line" || msg["full_message"] != "first line\nsecond line" {
+		t.Fatalf("unexpected message text %v", msg)
+	}
+	if msg["_payload"] != sb.String() || msg["__id"] != float64(7) || msg["_ratio"] != 0.5 {
+		t.Fatalf("unexpected additional fields %v", msg)
+	}
+	if _, ok := msg["timestamp"].(float64); !ok {
+		t.Fatalf("invalid timestamp %v", msg["timestamp"])
+	}
+	if file, _ := msg["_file"].(string); !strings.HasSuffix(file, "gelf_test.go") || msg["_line"] == nil {
+		t.Fatalf("unexpected stack fields %v", msg)
+	}
+}
+
+// GELF分块头长度
+const chunkHeader = 12
+
+// TestGELFUDPZlib 测试UDP zlib压缩
+func TestGELFUDPZlib(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newGELFLogger(t, gelf.Options{
+		Address:       conn.LocalAddr().String(),
+		Compression:   gelf.CompressionZlib,
+		DisabledStack: true,
+	})
+	l.Warn("disk", field.Bool("full", true), field.String("user name", "bob"))
+
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(zr)
+	msg := decodeGELF(t, data)
+	if msg["level"] != float64(4) || msg["_full"] != "true" || msg["_user_name"] != "bob" || msg["_file"] != nil {
+		t.Fatalf("unexpected message %v", msg)
+	}
+}
+
+// TestGELFTCP 测试TCP空字节分帧
+func TestGELFTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	l := newGELFLogger(t, gelf.Options{Network: "tcp", Address: ln.Addr().String()})
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l.Info("one")
+	l.Debug("two\x00")
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"one", "two\x00"} {
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		frame, err := r.ReadBytes(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := decodeGELF(t, frame[:len(frame)-1])
+		if msg["short_message"] != want {
+			t.Fatalf("unexpected message %v", msg)
+		}
+	}
+}
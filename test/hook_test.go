@@ -0,0 +1,130 @@
+package test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// TestHook 测试钩子修改字段、丢弃日志及执行顺序
+func TestHook(t *testing.T) {
+	var errors int64
+	var order []string
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Hooks: []logger.Hook{
+			// 统计错误日志
+			logger.HookFunc(func(r *logger.Record) bool {
+				order = append(order, "count")
+				if r.Level >= logger.Error {
+					atomic.AddInt64(&errors, 1)
+				}
+				return true
+			}),
+			// 丢弃健康检查日志
+			logger.HookFunc(func(r *logger.Record) bool {
+				order = append(order, "health")
+				return !strings.HasPrefix(r.Message, "GET /health")
+			}),
+		},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 后添加的钩子最后执行
+	err = l.(logger.HookAdder).AddHook(logger.HookFunc(func(r *logger.Record) bool {
+		order = append(order, "host")
+		r.RemoveField("password")
+		r.AddField(field.String("host", "web-1"))
+		return true
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.(logger.HookAdder).AddHook(nil); err == nil {
+		t.Fatal("expected error when adding nil hook")
+	}
+
+	fields := []field.Field{field.String("user", "bob"), field.String("password", "secret")}
+	l.Error("login failed", fields...)
+	l.Info("GET /health 200")
+
+	if strings.Join(order, ",") != "count,health,host,count,health" {
+		t.Fatalf("unexpected hook order %v", order)
+	}
+	if errors != 1 {
+		t.Fatalf("expected 1 error, got %d", errors)
+	}
+	observer.AssertCount(t, logger.Info, 0)
+	observer.AssertLogged(t, logger.Error, "login failed",
+		field.String("user", "bob"), field.String("host", "web-1"))
+	if _, ok := observer.All()[0].Field("password"); ok {
+		t.Fatal("password field should be removed")
+	}
+	// 钩子不应修改调用方的字段切片
+	if fields[1].Key != "password" {
+		t.Fatalf("caller fields modified: %v", fields)
+	}
+}
+
+// TestPreHook 测试抑制前钩子在日志采样及重复日志折叠之前执行
+func TestPreHook(t *testing.T) {
+	var seen, fired int64
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Sampling: logger.SamplingOption{Enabled: true, Tick: 3600000, First: 2, Thereafter: 1000},
+		Dedup:    logger.DedupOption{Enabled: true, Window: 3600000},
+		PreHooks: []logger.Hook{
+			// 统计全部日志
+			logger.HookFunc(func(r *logger.Record) bool {
+				atomic.AddInt64(&seen, 1)
+				return true
+			}),
+			// 提前丢弃的日志不占用采样配额
+			logger.HookFunc(func(r *logger.Record) bool {
+				return !strings.HasPrefix(r.Message, "GET /health")
+			}),
+		},
+		Hooks: []logger.Hook{
+			logger.HookFunc(func(r *logger.Record) bool {
+				atomic.AddInt64(&fired, 1)
+				return true
+			}),
+		},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		l.Info("GET /health 200")
+	}
+	// 重复日志被折叠
+	for i := 0; i < 3; i++ {
+		l.Info("retry", field.Int("n", 1))
+	}
+	// 超过采样配额的日志被丢弃
+	for i := 0; i < 3; i++ {
+		l.Info("retry", field.Int("n", i+2))
+	}
+
+	if seen != 11 {
+		t.Fatalf("expected pre hooks to see 11 logs, got %d", seen)
+	}
+	if fired != 2 {
+		t.Fatalf("expected hooks to see 2 logs, got %d", fired)
+	}
+	observer.AssertCount(t, logger.Info, 2)
+	observer.AssertLogged(t, logger.Info, "retry", field.Int("n", 1))
+	observer.AssertLogged(t, logger.Info, "retry", field.Int("n", 2))
+
+	// 钩子不能为空
+	if _, err = belog.New(logger.Option{PreHooks: []logger.Hook{nil}}, observer); err == nil {
+		t.Fatal("expected error with nil pre hook")
+	}
+}
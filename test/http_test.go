@@ -0,0 +1,266 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/http"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 接收到的HTTP请求
+type httpRequest struct {
+	contentType string
+	body        []byte
+}
+
+// HTTP接收服务
+type httpSink struct {
+	mutex    sync.Mutex
+	requests []httpRequest
+}
+
+// 创建HTTP接收服务
+//
+//	status依次返回的状态码，用完后始终返回200
+func newHTTPSink(t *testing.T, status ...int) (*httpSink, *httptest.Server) {
+	sink := &httpSink{}
+	var calls int32
+	srv := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Errorf("invalid gzip body, %s", err)
+				return
+			}
+			body, _ = ioutil.ReadAll(zr)
+		}
+		if n := int(atomic.AddInt32(&calls, 1)); n <= len(status) {
+			if status[n-1] == stdhttp.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status[n-1])
+			return
+		}
+		sink.mutex.Lock()
+		sink.requests = append(sink.requests, httpRequest{r.Header.Get("Content-Type"), body})
+		sink.mutex.Unlock()
+	}))
+	return sink, srv
+}
+
+// 获取接收到的请求
+func (s *httpSink) get() []httpRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]httpRequest(nil), s.requests...)
+}
+
+// 创建使用HTTP适配器的记录器
+func newHTTPLogger(t *testing.T, opt http.Options) (logger.Logger, *http.Adapter) {
+	adapter, err := http.New(opt)
+	if err != nil {
+		t.Fatalf("http adapter create failed, %s", err)
+	}
+	l, err := belog.New(logger.Option{}, adapter)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	return l, adapter.(*http.Adapter)
+}
+
+// TestHTTPJSONBatch 测试通用JSON数组格式、gzip压缩及按条数分批
+func TestHTTPJSONBatch(t *testing.T) {
+	sink, srv := newHTTPSink(t)
+	defer srv.Close()
+
+	l, adapter := newHTTPLogger(t, http.Options{URL: srv.URL, BatchCount: 3, BatchInterval: 3600000})
+	defer adapter.Close()
+
+	for i := 0; i < 5; i++ {
+		l.Info("hello \"world\"\n", field.Int("i", i), field.String("s", "a\tb"))
+	}
+	l.Flush()
+
+	reqs := sink.get()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	var total []map[string]interface{}
+	for _, req := range reqs {
+		if req.contentType != "application/json" {
+			t.Fatalf("unexpected content type %q", req.contentType)
+		}
+		var records []map[string]interface{}
+		if err := json.Unmarshal(req.body, &records); err != nil {
+			t.Fatalf("invalid json body %q, %s", req.body, err)
+		}
+		total = append(total, records...)
+	}
+	if len(total) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(total))
+	}
+	for i, r := range total {
+		if r["message"] != "hello \"world\"\n" || r["level"] != "info" || r["s"] != "a\tb" || r["i"] != float64(i) {
+			t.Fatalf("unexpected record %d: %v", i, r)
+		}
+	}
+	if s := adapter.Stats(); s.Sent != 5 || s.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+// TestHTTPElasticsearchBulk 测试Elasticsearch批量接口格式
+func TestHTTPElasticsearchBulk(t *testing.T) {
+	sink, srv := newHTTPSink(t)
+	defer srv.Close()
+
+	l, adapter := newHTTPLogger(t, http.Options{
+		URL:          srv.URL,
+		Formatter:    http.ElasticsearchFormatter{Index: "logs-app"},
+		DisabledGzip: true,
+	})
+	defer adapter.Close()
+
+	l.Warn("slow query", field.Int("ms", 1200))
+	l.Error("failed")
+	l.Flush()
+
+	reqs := sink.get()
+	if len(reqs) != 1 || reqs[0].contentType != "application/x-ndjson" {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(reqs[0].body))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 4 || !bytes.HasSuffix(reqs[0].body, []byte("\n")) {
+		t.Fatalf("unexpected body %q", reqs[0].body)
+	}
+	for i, level := range []string{"warning", "error"} {
+		if lines[i*2] != `{"create":{"_index":"logs-app"}}` {
+			t.Fatalf("unexpected action line %q", lines[i*2])
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i*2+1]), &doc); err != nil {
+			t.Fatalf("invalid document %q, %s", lines[i*2+1], err)
+		}
+		if doc["level"] != level || doc["@timestamp"] == nil {
+			t.Fatalf("unexpected document %v", doc)
+		}
+	}
+}
+
+// TestHTTPLoki 测试Loki推送接口格式及按标签分组
+func TestHTTPLoki(t *testing.T) {
+	sink, srv := newHTTPSink(t)
+	defer srv.Close()
+
+	l, adapter := newHTTPLogger(t, http.Options{
+		URL: srv.URL,
+		Formatter: http.LokiFormatter{
+			Labels:      map[string]string{"job": "api", "env": "test"},
+			LabelFields: []string{"level", "tenant.id"},
+		},
+	})
+	defer adapter.Close()
+
+	l.Info("a", field.String("tenant.id", "t1"), field.Int("n", 1))
+	l.Error("b", field.String("tenant.id", "t1"))
+	l.Info("c", field.String("tenant.id", "t1"), field.Int("n", 3))
+	l.Info("d")
+	l.Flush()
+
+	reqs := sink.get()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(reqs))
+	}
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(reqs[0].body, &push); err != nil {
+		t.Fatalf("invalid loki body %q, %s", reqs[0].body, err)
+	}
+	if len(push.Streams) != 3 {
+		t.Fatalf("expected 3 streams, got %s", reqs[0].body)
+	}
+	first := push.Streams[0]
+	if first.Stream["job"] != "api" || first.Stream["env"] != "test" || first.Stream["level"] != "info" || first.Stream["tenant_id"] != "t1" {
+		t.Fatalf("unexpected stream labels %v", first.Stream)
+	}
+	if len(first.Values) != 2 {
+		t.Fatalf("expected 2 values in first stream, got %v", first.Values)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(first.Values[1][1]), &line); err != nil {
+		t.Fatalf("invalid log line %q, %s", first.Values[1][1], err)
+	}
+	if line["message"] != "c" || line["n"] != float64(3) || line["tenant.id"] != nil {
+		t.Fatalf("unexpected log line %v", line)
+	}
+	if push.Streams[1].Stream["level"] != "error" {
+		t.Fatalf("unexpected second stream %v", push.Streams[1].Stream)
+	}
+	if _, ok := push.Streams[2].Stream["tenant_id"]; ok {
+		t.Fatalf("absent field must not produce a label: %v", push.Streams[2].Stream)
+	}
+}
+
+// TestHTTPRetry 测试429及5xx重试
+func TestHTTPRetry(t *testing.T) {
+	sink, srv := newHTTPSink(t, stdhttp.StatusServiceUnavailable, stdhttp.StatusTooManyRequests)
+	defer srv.Close()
+
+	l, adapter := newHTTPLogger(t, http.Options{URL: srv.URL, RetryMinInterval: 10})
+	defer adapter.Close()
+
+	l.Info("retry me")
+	l.Flush()
+
+	reqs := sink.get()
+	if len(reqs) != 1 || !strings.Contains(string(reqs[0].body), "retry me") {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	if s := adapter.Stats(); s.Sent != 1 || s.Retries != 2 || s.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+// TestHTTPClientError 测试4xx响应不重试
+func TestHTTPClientError(t *testing.T) {
+	sink, srv := newHTTPSink(t, stdhttp.StatusBadRequest)
+	defer srv.Close()
+
+	l, adapter := newHTTPLogger(t, http.Options{URL: srv.URL})
+
+	l.Info("rejected")
+	l.Flush()
+	l.Info("accepted")
+	if err := adapter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := sink.get()
+	if len(reqs) != 1 || !strings.Contains(string(reqs[0].body), "accepted") {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	if s := adapter.Stats(); s.Sent != 1 || s.Retries != 0 || s.Dropped != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,171 @@
+//go:build linux
+// +build linux
+
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/journald"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 解析journald原生协议日志
+func parseJournal(t *testing.T, data []byte) map[string]string {
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid journal entry %q", data)
+		}
+		name := string(data[:i])
+		if data[i] == '=' {
+			j := bytes.IndexByte(data, '\n')
+			fields[name] = string(data[i+1 : j])
+			data = data[j+1:]
+			continue
+		}
+		size := int(binary.LittleEndian.Uint64(data[i+1 : i+9]))
+		fields[name] = string(data[i+9 : i+9+size])
+		if data[i+9+size] != '\n' {
+			t.Fatalf("invalid binary field %q", name)
+		}
+		data = data[i+10+size:]
+	}
+	return fields
+}
+
+// 接收一条日志（支持文件描述符传递）
+func readJournal(t *testing.T, conn *net.UnixConn) map[string]string {
+	buf := make([]byte, 65536)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("read journal entry failed, %s", err)
+	}
+	if oobn == 0 {
+		return parseJournal(t, buf[:n])
+	}
+
+	// 大日志通过文件描述符传递
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("invalid control message, %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("invalid unix rights, %v", err)
+	}
+	file := os.NewFile(uintptr(fds[0]), "journal")
+	defer file.Close()
+	if _, err = file.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournal(t, data)
+	fields["__FD"] = "1"
+	return fields
+}
+
+// 创建输出到测试套接字的journald日志记录器
+func newJournalLogger(t *testing.T) (logger.Logger, *net.UnixConn) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	adapter, err := journald.New(journald.Options{SocketPath: path, Identifier: "belog-test"})
+	if err != nil {
+		t.Fatalf("journald adapter create failed, %s", err)
+	}
+	t.Cleanup(func() { adapter.(*journald.Adapter).Close() })
+	l, err := belog.New(logger.Option{EnabledStackPrint: true}, adapter)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	return l, conn
+}
+
+// TestJournald 测试journald原生协议
+func TestJournald(t *testing.T) {
+	l, conn := newJournalLogger(t)
+
+	// 普通日志
+	l.Warn("line1\nline2", field.String("user.name", "bob"), field.Int("_count", 3), field.String("9lives", "cat"))
+	fields := readJournal(t, conn)
+	want := map[string]string{
+		"MESSAGE":           "line1\nline2",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "belog-test",
+		"USER_NAME":         "bob",
+		"COUNT":             "3",
+		"F_9LIVES":          "cat",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Fatalf("field %s expected %q, got %q (%v)", k, v, fields[k], fields)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journald_test.go") || fields["CODE_LINE"] == "" || !strings.Contains(fields["CODE_FUNC"], "TestJournald") {
+		t.Fatalf("unexpected code fields %v", fields)
+	}
+
+	// 超过数据报长度上限的日志通过文件描述符传递
+	large := strings.Repeat("x", 4*1024*1024)
+	l.Error("large", field.String("payload", large))
+	fields = readJournal(t, conn)
+	if fields["__FD"] != "1" || fields["PAYLOAD"] != large || fields["PRIORITY"] != "3" {
+		t.Fatalf("large entry not passed by file descriptor, got %d fields", len(fields))
+	}
+}
+
+// TestJournaldReservedFields 测试与适配器写入的字段同名的日志字段添加前缀
+func TestJournaldReservedFields(t *testing.T) {
+	l, conn := newJournalLogger(t)
+	l.Info("real",
+		field.String("message", "fake"),
+		field.Int("priority", 0),
+		field.String("syslog_identifier", "other"),
+		field.String("code_file", "fake.go"),
+		field.Int("_code_line", 1),
+		field.String("code.func", "fake"),
+	)
+	fields := readJournal(t, conn)
+	want := map[string]string{
+		"MESSAGE":             "real",
+		"PRIORITY":            "6",
+		"SYSLOG_IDENTIFIER":   "belog-test",
+		"F_MESSAGE":           "fake",
+		"F_PRIORITY":          "0",
+		"F_SYSLOG_IDENTIFIER": "other",
+		"F_CODE_FILE":         "fake.go",
+		"F_CODE_LINE":         "1",
+		"F_CODE_FUNC":         "fake",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Fatalf("field %s expected %q, got %q (%v)", k, v, fields[k], fields)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journald_test.go") || !strings.Contains(fields["CODE_FUNC"], "TestJournaldReservedFields") {
+		t.Fatalf("unexpected code fields %v", fields)
+	}
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/netlog"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 测试用日志收集端，按行接收日志
+type netCollector struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	conns    []net.Conn
+	lines    []string
+	accepted int
+}
+
+// 在指定地址启动日志收集端
+func startCollector(t *testing.T, address string) *netCollector {
+	t.Helper()
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &netCollector{listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.mutex.Lock()
+			c.conns = append(c.conns, conn)
+			c.accepted++
+			c.mutex.Unlock()
+			go func() {
+				s := bufio.NewScanner(conn)
+				for s.Scan() {
+					c.mutex.Lock()
+					c.lines = append(c.lines, s.Text())
+					c.mutex.Unlock()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(c.stop)
+	return c
+}
+
+// 停止收集端并断开所有连接
+func (c *netCollector) stop() {
+	c.listener.Close()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.conns = nil
+}
+
+// 获取已接收的日志
+func (c *netCollector) received() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+// 等待接收到满足条件的日志
+func (c *netCollector) wait(t *testing.T, cond func(lines []string) bool) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lines := c.received()
+		if cond(lines) {
+			return lines
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for logs, received %d: %q", len(lines), lines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 创建网络日志适配器
+func newNetAdapter(t *testing.T, opt netlog.Options) *netlog.Adapter {
+	t.Helper()
+	opt.Timeout = 500
+	opt.ReconnectMinInterval = 10
+	opt.ReconnectMaxInterval = 50
+	a, err := netlog.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a.(*netlog.Adapter)
+}
+
+// 写入编号为[from, to)的日志，每条日志填充到指定长度
+func printRecords(a *netlog.Adapter, from, to, size int) {
+	for i := from; i < to; i++ {
+		line := "record " + strconv.Itoa(i) + " "
+		line += strings.Repeat("x", size-len(line)-1) + "\n"
+		a.Print(time.Now(), logger.Info, []byte(line))
+	}
+}
+
+// 校验日志为编号从from开始连续递增的日志
+func checkRecords(t *testing.T, lines []string, from int) {
+	t.Helper()
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "record "+strconv.Itoa(from+i)+" ") {
+			t.Fatalf("unexpected record at %d: %q", i, line)
+		}
+	}
+}
+
+// TestNetOrder 测试日志按写入顺序发送
+func TestNetOrder(t *testing.T) {
+	c := startCollector(t, "127.0.0.1:0")
+	a := newNetAdapter(t, netlog.Options{Address: c.listener.Addr().String()})
+	printRecords(a, 0, 2000, 64)
+	a.Flush()
+	lines := c.wait(t, func(lines []string) bool { return len(lines) >= 2000 })
+	checkRecords(t, lines, 0)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.Stats(); s.Sent != 2000 || s.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+// 获取当前未被监听的地址
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// TestNetSpoolReplay 测试收集端不可用时写入磁盘队列，重新打开后按顺序重放
+func TestNetSpoolReplay(t *testing.T) {
+	address := freeAddress(t)
+	opt := netlog.Options{
+		Address:      address,
+		BufferSize:   1,
+		SpoolDir:     t.TempDir(),
+		FlushTimeout: 100,
+	}
+
+	// 内存缓冲区只能容纳前8条日志，其余日志写入磁盘队列
+	a := newNetAdapter(t, opt)
+	printRecords(a, 0, 30, 128)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 关闭时内存缓冲区中的日志插入到磁盘队列头部
+	if s := a.Stats(); s.Sent != 0 || s.Dropped != 0 || s.Spilled != 30 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	c := startCollector(t, address)
+	a = newNetAdapter(t, opt)
+	printRecords(a, 30, 40, 128)
+	a.Flush()
+	lines := c.wait(t, func(lines []string) bool { return len(lines) >= 40 })
+	checkRecords(t, lines, 0)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s := a.Stats(); s.Sent != 40 || s.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+// TestNetReconnect 测试收集端重启后重新连接并继续发送
+func TestNetReconnect(t *testing.T) {
+	c := startCollector(t, "127.0.0.1:0")
+	address := c.listener.Addr().String()
+	a := newNetAdapter(t, netlog.Options{Address: address})
+	defer a.Close()
+	printRecords(a, 0, 10, 64)
+	c.wait(t, func(lines []string) bool { return len(lines) >= 10 })
+
+	// 重启收集端，断开期间写入连接成功的日志可能丢失
+	c.stop()
+	c = startCollector(t, address)
+	for i := 10; ; i++ {
+		printRecords(a, i, i+1, 64)
+		if len(c.received()) > 0 {
+			break
+		}
+		if i > 1000 {
+			t.Fatal("expected logs after reconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	lines := c.received()
+	from, err := strconv.Atoi(strings.Fields(lines[0])[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, lines, from)
+}
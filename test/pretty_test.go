@@ -0,0 +1,118 @@
+package test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3/adapter/console"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 测试用日志记录时间
+var prettyTime = time.Date(2021, 9, 21, 19, 16, 0, 123000000, time.UTC)
+
+// 创建不输出颜色的美化编码器
+func newPlainPrettyEncoder(opt encoder.PrettyEncoderOption) logger.Encoder {
+	opt.DisabledColor = true
+	return encoder.NewPrettyEncoder(opt)
+}
+
+// TestPrettyEncode 测试美化编码器的列对齐及单行字段
+func TestPrettyEncode(t *testing.T) {
+	e := newPlainPrettyEncoder(encoder.DefaultPrettyOption)
+
+	got := string(e.Encode(nil, prettyTime, logger.Info, "hello 世界", field.String("a", "b"), field.Int("n", 5)))
+	want := "19:16:00.123   INF   hello 世界" + strings.Repeat(" ", 32) + "a=b n=5\r\n"
+	if got != want {
+		t.Fatalf("unexpected output\n got %q\nwant %q", got, want)
+	}
+
+	// 无字段时不补齐日志描述
+	if got = string(e.Encode(nil, prettyTime, logger.Warn, "bye")); got != "19:16:00.123   WRN   bye\r\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	// 调用位置保留最后一级文件夹并补齐列宽
+	got = string(e.EncodeStack(nil, prettyTime, logger.Error, "/src/app/server/main.go", 42, "main.run", "failed"))
+	want = "19:16:00.123   ERR   server/main.go:42" + strings.Repeat(" ", 7) + "  failed\r\n"
+	if got != want {
+		t.Fatalf("unexpected output\n got %q\nwant %q", got, want)
+	}
+}
+
+// TestPrettyMultiline 测试多行字段及JSON字段缩进输出
+func TestPrettyMultiline(t *testing.T) {
+	e := newPlainPrettyEncoder(encoder.DefaultPrettyOption)
+	got := string(e.Encode(nil, prettyTime, logger.Error, "request failed",
+		field.String("js", `{"x":1}`),
+		field.Error("err", errors.New("line1\r\nline2\n")),
+		field.String("user", "bob"),
+	))
+	want := "19:16:00.123   ERR   request failed" + strings.Repeat(" ", 26) + "user=bob\r\n" +
+		"    js: {\r\n" +
+		"          \"x\": 1\r\n" +
+		"        }\r\n" +
+		"    err: line1\r\n" +
+		"        line2\r\n"
+	if got != want {
+		t.Fatalf("unexpected output\n got %q\nwant %q", got, want)
+	}
+
+	// 无法缩进的JSON内容保持单行
+	if got = string(e.Encode(nil, prettyTime, logger.Info, "m", field.String("e", "{}"))); !strings.HasSuffix(got, "e={}\r\n") {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+// TestPrettyOptions 测试完整日志级别及相对时间
+func TestPrettyOptions(t *testing.T) {
+	opt := encoder.DefaultPrettyOption
+	opt.LevelFormat = true
+	if got := string(newPlainPrettyEncoder(opt).Encode(nil, prettyTime, logger.Info, "m")); got != "19:16:00.123   INFO      m\r\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	opt = encoder.DefaultPrettyOption
+	opt.RelativeTime = true
+	e := newPlainPrettyEncoder(opt)
+	got := string(e.Encode(nil, time.Now().Add(1500*time.Millisecond), logger.Info, "m"))
+	if !regexp.MustCompile(`^ {3}\+1\.5[0-9]{2}s   INF   m\r\n$`).MatchString(got) {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+// TestPrettyColor 测试美化编码器颜色输出及配色副本
+func TestPrettyColor(t *testing.T) {
+	e := encoder.NewPrettyEncoder(encoder.DefaultPrettyOption)
+	got := string(e.Encode(nil, prettyTime, logger.Info, "m", field.String("a", "b")))
+	if !strings.Contains(got, "\x1b[42;30m INF \x1b[0m") || !strings.Contains(got, "\x1b[36m\x1b[2ma\x1b[0m") {
+		t.Fatalf("expected colored output, got %q", got)
+	}
+
+	// 不输出颜色的副本
+	plain := string(encoder.WithTheme(e, nil).Encode(nil, prettyTime, logger.Info, "m", field.String("a", "b")))
+	if strings.Contains(plain, "\x1b[") {
+		t.Fatalf("expected plain output, got %q", plain)
+	}
+	if colored := string(encoder.WithTheme(e, &console.DefaultTheme).Encode(nil, prettyTime, logger.Info, "m", field.String("a", "b"))); colored != got {
+		t.Fatalf("expected colored copy %q, got %q", got, colored)
+	}
+}
+
+// TestPrettyAllocs 测试美化编码器编码单行的普通字段时不分配内存
+func TestPrettyAllocs(t *testing.T) {
+	e := encoder.NewPrettyEncoder(encoder.DefaultPrettyOption)
+	dst := make([]byte, 0, 1024)
+	val := []field.Field{field.String("a", "b"), field.Int("n", 5), field.Bool("ok", true)}
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = e.EncodeStack(dst[:0], prettyTime, logger.Info, "/src/app/main.go", 42, "main.run", "hello", val...)
+	})
+	if allocs > 0 {
+		t.Fatalf("expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 统计日志条数的适配器
+type countAdapter struct {
+	name  string
+	count int64
+}
+
+func (c *countAdapter) Name() string { return c.name }
+
+func (c *countAdapter) Print(time.Time, logger.Level, []byte) { atomic.AddInt64(&c.count, 1) }
+
+func (c *countAdapter) PrintStack(time.Time, logger.Level, []byte, string, int, string) {
+	atomic.AddInt64(&c.count, 1)
+}
+
+func (c *countAdapter) Flush() {}
+
+// 并发记录日志期间不断设置适配器
+func raceSetAdapter(t *testing.T, option logger.Option) {
+	stable := &countAdapter{name: "stable"}
+	l, err := belog.New(option, stable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.(logger.Closer).Close()
+
+	const goroutines, logs = 8, 500
+	stop := make(chan struct{})
+	var setter sync.WaitGroup
+	setter.Add(1)
+	go func() {
+		defer setter.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// 新增、替换普通适配器及自带编码器的适配器
+			_ = l.SetAdapter(&countAdapter{name: "dynamic-" + strconv.Itoa(i%4)})
+			_ = l.SetAdapter(belogtest.NewObserver())
+			runtime.Gosched()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < logs; i++ {
+				l.Info("race")
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	setter.Wait()
+	l.Flush()
+
+	if n := atomic.LoadInt64(&stable.count); n != goroutines*logs {
+		t.Fatalf("stable adapter expected %d logs, got %d", goroutines*logs, n)
+	}
+}
+
+// TestRaceSetAdapterSync 测试同步输出时并发设置适配器
+func TestRaceSetAdapterSync(t *testing.T) {
+	raceSetAdapter(t, logger.Option{})
+}
+
+// TestRaceSetAdapterStack 测试输出调用栈时并发设置适配器
+func TestRaceSetAdapterStack(t *testing.T) {
+	raceSetAdapter(t, logger.Option{EnabledStackPrint: true})
+}
+
+// TestRaceSetAdapterAsync 测试异步输出时并发设置适配器
+func TestRaceSetAdapterAsync(t *testing.T) {
+	raceSetAdapter(t, logger.Option{Async: logger.AsyncOption{Enabled: true, QueueSize: 64}})
+}
+
+// TestSetAdapterAfterEmptyLogging 测试无适配器时记录日志后仍可设置适配器
+func TestSetAdapterAfterEmptyLogging(t *testing.T) {
+	l, err := belog.New(logger.Option{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("nobody listens")
+
+	done := make(chan error, 1)
+	go func() { done <- l.SetAdapter(&countAdapter{name: "late"}) }()
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("SetAdapter blocked after logging without adapters")
+	}
+}
+
+// TestSetAdapterReplace 测试同名适配器替换
+func TestSetAdapterReplace(t *testing.T) {
+	first, second := &countAdapter{name: "same"}, &countAdapter{name: "same"}
+	l, err := belog.New(logger.Option{}, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("one")
+	if err = l.SetAdapter(second); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("two")
+	if first.count != 1 || second.count != 1 {
+		t.Fatalf("unexpected counts %d, %d", first.count, second.count)
+	}
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/ring"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 创建使用内存环形缓冲区适配器的记录器
+func newRingLogger(t *testing.T, size uint, stack bool) (logger.Logger, *ring.Adapter) {
+	adapter := ring.New(ring.Options{Size: size})
+	l, err := belog.New(logger.Option{EnabledStackPrint: stack}, adapter)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	return l, adapter.(*ring.Adapter)
+}
+
+// TestRingQuery 测试覆盖及查询条件
+func TestRingQuery(t *testing.T) {
+	l, r := newRingLogger(t, 4, false)
+	for i := 0; i < 6; i++ {
+		if i%2 == 0 {
+			l.Info("info", field.Int("i", i))
+		} else {
+			l.Error("error", field.Int("i", i))
+		}
+	}
+
+	all := r.Records(ring.Query{})
+	if r.Len() != 4 || len(all) != 4 || all[0].Seq != 2 || all[3].Seq != 5 {
+		t.Fatalf("unexpected records %+v", all)
+	}
+	if !strings.Contains(string(all[0].Content), `"i": 2`) || strings.HasSuffix(string(all[0].Content), "\n") {
+		t.Fatalf("unexpected content %q", all[0].Content)
+	}
+	if got := r.Records(ring.Query{MinLevel: logger.Error}); len(got) != 2 || got[0].Seq != 3 {
+		t.Fatalf("level query failed %+v", got)
+	}
+	if got := r.Records(ring.Query{Contains: `"i": 4`}); len(got) != 1 || got[0].Seq != 4 {
+		t.Fatalf("substring query failed %+v", got)
+	}
+	if got := r.Records(ring.Query{Limit: 1}); len(got) != 1 || got[0].Seq != 5 {
+		t.Fatalf("limit query failed %+v", got)
+	}
+	if got := r.Records(ring.Query{Since: time.Now().Add(time.Minute)}); len(got) != 0 {
+		t.Fatalf("time query failed %+v", got)
+	}
+}
+
+// TestRingHandler 测试NDJSON导出
+func TestRingHandler(t *testing.T) {
+	l, r := newRingLogger(t, 100, true)
+	l.Debug("boot")
+	l.Warn("disk \"almost\" full")
+	l.Error("disk full")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?level=warn&q=disk", nil))
+	if rec.Code != 200 || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/x-ndjson") {
+		t.Fatalf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %q, %s", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0]["level"] != "warning" || lines[1]["level"] != "error" {
+		t.Fatalf("unexpected lines %v", lines)
+	}
+	if !strings.Contains(lines[0]["content"].(string), `disk \"almost\" full`) || !strings.HasSuffix(lines[0]["file"].(string), "ring_test.go") {
+		t.Fatalf("unexpected line %v", lines[0])
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/logs?since=yesterday", nil))
+	if rec.Code != 400 {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestRingConcurrent 测试并发写入及查询
+func TestRingConcurrent(t *testing.T) {
+	l, r := newRingLogger(t, 64, false)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				l.Info("g" + strconv.Itoa(g))
+				if i%50 == 0 {
+					_ = r.Records(ring.Query{Contains: "g1"})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	records := r.Records(ring.Query{})
+	if len(records) != 64 || records[63].Seq != 3999 {
+		t.Fatalf("unexpected records len %d", len(records))
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Seq != records[i-1].Seq+1 {
+			t.Fatalf("records out of order at %d", i)
+		}
+	}
+}
+
+// TestRingBelowLoggerLevel 测试环形缓冲区保留低于记录器级别的日志
+func TestRingBelowLoggerLevel(t *testing.T) {
+	adapter := ring.New(ring.Options{Size: 100, MinLevel: logger.Debug})
+	other := &collectAdapter{name: "other"}
+	l, err := belog.New(logger.Option{EnabledStackPrint: true}, adapter, other)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	l.SetLevel(logger.Warn)
+	l.Trace("trace")
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+
+	records := adapter.(*ring.Adapter).Records(ring.Query{})
+	if len(records) != 3 || records[0].Level != logger.Debug || records[2].Level != logger.Warn {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if !strings.HasSuffix(records[0].FileName, "ring_test.go") || !strings.Contains(records[0].FuncName, "TestRingBelowLoggerLevel") {
+		t.Fatalf("unexpected stack of the below level record %+v", records[0])
+	}
+	if lines := other.get(); len(lines) != 1 || !strings.Contains(lines[0], "warn") {
+		t.Fatalf("expected only the warn log in the other adapter, got %q", lines)
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/discard"
+	"github.com/bearki/belog/v3/belogtest"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// TestSampling 测试先记录前N条，此后每M条记录1条
+func TestSampling(t *testing.T) {
+	var logged, dropped int64
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Sampling: logger.SamplingOption{
+			Enabled:    true,
+			Tick:       3600000,
+			First:      3,
+			Thereafter: 10,
+			Hook: func(_ logger.Level, _ string, d logger.SamplingDecision) {
+				if d == logger.SamplingLogged {
+					atomic.AddInt64(&logged, 1)
+				} else {
+					atomic.AddInt64(&dropped, 1)
+				}
+			},
+		},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 53; i++ {
+		l.Warn("disk full")
+	}
+	// 不同级别及消息单独计数
+	l.Error("disk full")
+	l.Warn("other")
+
+	// 前3条及第13、23、33、43、53条
+	observer.AssertCount(t, logger.Warn, 8+1)
+	observer.AssertCount(t, logger.Error, 1)
+	if logged != 10 || dropped != 45 {
+		t.Fatalf("unexpected hook counts logged=%d dropped=%d", logged, dropped)
+	}
+}
+
+// TestSamplingTick 测试周期结束后重新计数
+func TestSamplingTick(t *testing.T) {
+	observer := belogtest.NewObserver()
+	l, err := belog.New(logger.Option{
+		Sampling: logger.SamplingOption{Enabled: true, Tick: 50, First: 1, Thereafter: 1000},
+	}, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("tick")
+	l.Info("tick")
+	time.Sleep(100 * time.Millisecond)
+	l.Info("tick")
+	observer.AssertCount(t, logger.Info, 2)
+}
+
+// BenchmarkSamplingDropped 测试被采样丢弃的日志开销
+func BenchmarkSamplingDropped(b *testing.B) {
+	l, err := belog.New(logger.Option{
+		Sampling: logger.SamplingOption{Enabled: true, First: 1, Thereafter: 1 << 30},
+	}, discard.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// 重置测试参数
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// 执行测试
+	for i := 0; i < b.N; i++ {
+		l.Warn("this is a sampled log")
+	}
+}
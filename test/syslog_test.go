@@ -0,0 +1,180 @@
+package test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/syslog"
+	"github.com/bearki/belog/v3/encoder"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 创建使用syslog适配器的记录器
+func newSyslogLogger(t *testing.T, opt syslog.Options, stack bool) (logger.Logger, logger.Adapter) {
+	adapter, err := syslog.New(opt)
+	if err != nil {
+		t.Fatalf("syslog adapter create failed, %s", err)
+	}
+	l, err := belog.New(logger.Option{
+		EnabledStackPrint: stack,
+		Encoder:           encoder.NewJsonEncoder(encoder.DefaultJsonOption),
+	}, adapter)
+	if err != nil {
+		t.Fatalf("belog logger create failed, %s", err)
+	}
+	return l, adapter
+}
+
+// 读取一个UDP数据报
+func readDatagram(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read datagram failed, %s", err)
+	}
+	return string(buf[:n])
+}
+
+// TestSyslogRFC5424UDP 测试RFC 5424格式及结构化数据
+func TestSyslogRFC5424UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l, _ := newSyslogLogger(t, syslog.Options{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: syslog.Local3,
+		AppName:  "belog-test",
+		Hostname: "host1",
+	}, false)
+
+	l.Error("disk full", field.String("path", `/var/"log"]`), field.Int("free", 0))
+	got := readDatagram(t, conn)
+
+	// local3(19)*8 + err(3) = 155
+	re := regexp.MustCompile(`^<155>1 \S+ host1 belog-test [0-9]+ - \[belog@32473 path="/var/\\"log\\"\\]" free="0"\] disk full$`)
+	if !re.MatchString(got) {
+		t.Fatalf("unexpected message: %q", got)
+	}
+	ts := strings.Fields(got)[1]
+	if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+		t.Fatalf("invalid timestamp %q, %s", ts, err)
+	}
+
+	// 无字段时结构化数据为"-"
+	l.Info("started")
+	if got = readDatagram(t, conn); !strings.HasSuffix(got, " - - started") || !strings.HasPrefix(got, "<158>1 ") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+// TestSyslogRFC3164TCP 测试RFC 3164格式及TCP八位组计数分帧和重连
+func TestSyslogRFC3164TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// 接收并解析八位组计数分帧的日志
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					length, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+					if err != nil {
+						messages <- "bad frame: " + length
+						return
+					}
+					buf := make([]byte, n)
+					if _, err = io.ReadFull(r, buf); err != nil {
+						return
+					}
+					messages <- string(buf)
+				}
+			}(conn)
+		}
+	}()
+	receive := func() string {
+		select {
+		case m := <-messages:
+			return m
+		case <-time.After(3 * time.Second):
+			t.Fatal("receive message timeout")
+			return ""
+		}
+	}
+
+	l, adapter := newSyslogLogger(t, syslog.Options{
+		Network:           "tcp",
+		Address:           ln.Addr().String(),
+		Format:            syslog.RFC3164,
+		AppName:           "app",
+		Hostname:          "host2",
+		ReconnectInterval: 1,
+	}, true)
+
+	l.Warn("slow request\nsecond line", field.Int("ms", 1500))
+	got := receive()
+	re := regexp.MustCompile(`^<12>[A-Z][a-z]{2} [ 0-9]{2} [0-9:]{8} host2 app\[[0-9]+\]: \[\S+syslog_test\.go:[0-9]+\] slow request\nsecond line, ms:1500$`)
+	if !re.MatchString(got) {
+		t.Fatalf("unexpected message: %q", got)
+	}
+
+	// 断开连接后重连
+	_ = adapter.(*syslog.Adapter).Close()
+	l.Fatal("after reconnect")
+	if got = receive(); !strings.HasSuffix(got, "] after reconnect") || !strings.HasPrefix(got, "<10>") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
+
+// TestSyslogUnixgram 测试本机套接字
+func TestSyslogUnixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "belog-syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram is not supported, %s", err)
+	}
+	defer conn.Close()
+
+	l, _ := newSyslogLogger(t, syslog.Options{
+		Network: "unixgram",
+		Address: path,
+		AppName: "local",
+	}, false)
+	l.Debug("hello")
+	if got := readDatagram(t, conn); !strings.HasPrefix(got, "<15>1 ") || !strings.HasSuffix(got, " local "+strconv.Itoa(os.Getpid())+" - - hello") {
+		t.Fatalf("unexpected message: %q", got)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bearki/belog/v3"
+	"github.com/bearki/belog/v3/adapter/writer"
+	"github.com/bearki/belog/v3/field"
+	"github.com/bearki/belog/v3/logger"
+)
+
+// 记录刷新及关闭调用的缓冲区
+type recordingBuffer struct {
+	bytes.Buffer
+	flushed int
+	closed  bool
+}
+
+// Flush 刷新
+func (b *recordingBuffer) Flush() error {
+	b.flushed++
+	return nil
+}
+
+// Close 关闭
+func (b *recordingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+// 创建输出到指定目标的记录器
+func newWriterLogger(t *testing.T, opt writer.Options) (logger.Logger, *writer.Adapter) {
+	t.Helper()
+	a, err := writer.New(opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := belog.New(logger.Option{}, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, a.(*writer.Adapter)
+}
+
+// TestWriterCapture 测试将日志捕获到bytes.Buffer
+func TestWriterCapture(t *testing.T) {
+	var buf bytes.Buffer
+	l, a := newWriterLogger(t, writer.Options{Writer: &buf})
+	if a.Name() != writer.DefaultName {
+		t.Fatalf("unexpected name %q", a.Name())
+	}
+	l.Info("hello", field.String("user", "bob"))
+	l.Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\r\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"message": "hello"`) || !strings.Contains(lines[0], `"user": "bob"`) ||
+		!strings.Contains(lines[1], `"level": "E"`) {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+// TestWriterBuffer 测试缓冲区在刷新时写入并转发刷新及关闭
+func TestWriterBuffer(t *testing.T) {
+	buf := &recordingBuffer{}
+	l, a := newWriterLogger(t, writer.Options{Writer: buf, BufferSize: 1})
+	l.Info("hello")
+	if buf.Len() > 0 {
+		t.Fatalf("expected buffered output before flush, got %q", buf.String())
+	}
+	l.Flush()
+	if !strings.Contains(buf.String(), "hello") || buf.flushed != 1 {
+		t.Fatalf("expected flushed output, got %q, %d flushes", buf.String(), buf.flushed)
+	}
+
+	l.Info("bye")
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "bye") || !buf.closed {
+		t.Fatalf("expected output flushed and writer closed, got %q, closed %v", buf.String(), buf.closed)
+	}
+}
+
+// TestWriterNil 测试未指定输出目标
+func TestWriterNil(t *testing.T) {
+	if _, err := writer.New(writer.Options{}); err == nil {
+		t.Fatal("expected error without writer")
+	}
+}